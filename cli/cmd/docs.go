@@ -0,0 +1,292 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/mermaid"
+	"github.com/koderizer/arc/viz/puml"
+	"github.com/spf13/cobra"
+)
+
+var docsCheck bool
+var docsFormat string
+var docsLibrary string
+
+//viewMarker match a generated view block of a markdown document
+var viewMarker = regexp.MustCompile(`(?s)<!--\s*arc:view\s+(.*?)\s*-->.*?<!--\s*/arc:view\s*-->`)
+
+//fenceOpening match the opening line of a fenced code block, where markers are quoted rather than views
+var fenceOpening = regexp.MustCompile("(?m)^ {0,3}(`{3,}|~{3,})")
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Keep architecture views embedded in markdown documents up to date",
+}
+
+// docsUpdateCmd represents the docs update command
+var docsUpdateCmd = &cobra.Command{
+	Use:   "update <markdown files>",
+	Short: "Regenerate the architecture views embedded in markdown documents",
+	Long: `
+Find the view markers in the given markdown files and replace their content with a freshly generated view.
+
+A view marker name the perspective and targets to render, the same way as inspect arguments,
+and optionally the format of the view (image | mermaid | puml), eg:
+
+	<!-- arc:view container arc format=mermaid -->
+	<!-- /arc:view -->
+
+Image views are rendered by the arcviz server and linked, mermaid and puml views are generated locally as code blocks.
+Puml views include the C4-PlantUML library from the --puml-library directory next to the document, written along.
+Markers quoted in fenced code blocks are left alone.
+With --check, no file is written and the command fail if any document or library file is stale, which is handy for CI.
+
+Eg:
+	arcli docs update README.md docs/*.md
+	arcli docs update --check README.md`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		docs := &docsUpdater{arc: arc, format: docsFormat}
+		defer docs.close()
+
+		stale, err := docs.updateFiles(args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if docsCheck && stale > 0 {
+			fmt.Printf("%d file(s) out of sync with %s, run arcli docs update\n", stale, arcFilename)
+			os.Exit(1)
+		}
+	},
+}
+
+//docsUpdater generate the views embedded in markdown documents
+type docsUpdater struct {
+	arc    *model.ArcType
+	format string
+	client *vizClient
+	//libraries collect the levels of the C4-PlantUML library included by the puml views of a document
+	libraries map[string]bool
+}
+
+//updateFiles regenerate the views of the documents, with the library files their puml views include,
+//and return the number of stale files, only listing them with --check
+func (d *docsUpdater) updateFiles(docs []string) (int, error) {
+	stale := 0
+	for _, doc := range docs {
+		content, err := ioutil.ReadFile(doc)
+		if err != nil {
+			return stale, err
+		}
+		updated, err := d.update(content)
+		if err != nil {
+			return stale, fmt.Errorf("%s: %v", doc, err)
+		}
+		files := map[string][]byte{doc: updated}
+		for level := range d.libraries {
+			library, err := puml.Library(level)
+			if err != nil {
+				return stale, err
+			}
+			files[filepath.Join(filepath.Dir(doc), filepath.FromSlash(docsLibrary), level+".puml")] = []byte(library)
+		}
+		names := make([]string, 0, len(files))
+		for file := range files {
+			names = append(names, file)
+		}
+		sort.Strings(names)
+		for _, file := range names {
+			if current, err := ioutil.ReadFile(file); err == nil && bytes.Equal(current, files[file]) {
+				continue
+			}
+			stale++
+			if docsCheck {
+				fmt.Printf("%s is stale\n", file)
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				return stale, err
+			}
+			if err := ioutil.WriteFile(file, files[file], 0644); err != nil {
+				return stale, err
+			}
+			fmt.Printf("%s updated\n", file)
+		}
+	}
+	return stale, nil
+}
+
+//update return the document content with all its views regenerated
+func (d *docsUpdater) update(content []byte) ([]byte, error) {
+	var failure error
+	d.libraries = make(map[string]bool)
+	updated := replaceViews(content, func(block []byte) []byte {
+		if failure != nil {
+			return block
+		}
+		spec := viewMarker.FindSubmatch(block)[1]
+		view, err := d.view(strings.Fields(string(spec)))
+		if err != nil {
+			failure = fmt.Errorf("view %q: %v", spec, err)
+			return block
+		}
+		return []byte(fmt.Sprintf("<!-- arc:view %s -->\n%s\n<!-- /arc:view -->", spec, view))
+	})
+	return updated, failure
+}
+
+//view generate the content of a view given the marker arguments
+func (d *docsUpdater) view(args []string) (string, error) {
+	format := d.format
+	targets := make([]string, 0)
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "format=") {
+			format = strings.TrimPrefix(arg, "format=")
+			continue
+		}
+		targets = append(targets, arg)
+	}
+	if len(targets) == 0 {
+		return "", fmt.Errorf("missing perspective")
+	}
+	persName := targets[0]
+	pers, err := parsePerspective(persName)
+	if err != nil {
+		return "", err
+	}
+	targets = targets[1:]
+
+	switch format {
+	case "image":
+		if d.client == nil {
+			if d.client, err = dialViz(vizAddress); err != nil {
+				return "", err
+			}
+		}
+		vizform, err := parseVisualFormat(outFormat)
+		if err != nil {
+			return "", err
+		}
+		uri, err := d.client.render(d.arc, pers, vizform, targets...)
+		if err != nil {
+			return "", err
+		}
+		title := strings.Join(append([]string{persName, "view"}, targets...), " ")
+		return fmt.Sprintf("![%s](%s)", title, uri), nil
+	case "mermaid":
+		g, err := analyse(d.arc, pers, targets...)
+		if err != nil {
+			return "", err
+		}
+		src, err := mermaid.Generate(g)
+		if err != nil {
+			return "", err
+		}
+		return "```mermaid\n" + src + "```", nil
+	case "puml":
+		g, err := analyse(d.arc, pers, targets...)
+		if err != nil {
+			return "", err
+		}
+		src, err := puml.Generate(g, targets...)
+		if err != nil {
+			return "", err
+		}
+		src, levels := puml.IncludeLibrary(src, docsLibrary)
+		for _, level := range levels {
+			d.libraries[level] = true
+		}
+		return "```plantuml\n" + strings.TrimSpace(src) + "\n```", nil
+	default:
+		return "", fmt.Errorf("format %s not supported, please indicate one of: image, mermaid, puml", format)
+	}
+}
+
+//replaceViews replace the view blocks of a markdown document, leaving alone the markers quoted in fenced code blocks
+func replaceViews(content []byte, replace func(block []byte) []byte) []byte {
+	out := make([]byte, 0, len(content))
+	for {
+		view := viewMarker.FindIndex(content)
+		if view == nil {
+			return append(out, content...)
+		}
+		if fence := fenceOpening.FindSubmatchIndex(content); fence != nil && fence[0] < view[0] {
+			end := fenceEnd(content, fence)
+			out = append(out, content[:end]...)
+			content = content[end:]
+			continue
+		}
+		out = append(append(out, content[:view[0]]...), replace(content[view[0]:view[1]])...)
+		content = content[view[1]:]
+	}
+}
+
+//fenceEnd return the offset following the line closing the fenced code block opened by the given match,
+//or the end of the content when the block is not closed
+func fenceEnd(content []byte, fence []int) int {
+	marker := content[fence[2]:fence[3]]
+	pos := fence[1]
+	for {
+		next := bytes.IndexByte(content[pos:], '\n')
+		if next < 0 {
+			return len(content)
+		}
+		pos += next + 1
+		line := content[pos:]
+		if end := bytes.IndexByte(line, '\n'); end >= 0 {
+			line = line[:end]
+		}
+		closing := bytes.TrimLeft(line, " ")
+		if len(line)-len(closing) <= 3 && bytes.HasPrefix(closing, marker) && len(bytes.Trim(closing, string(marker[:1])+" \t\r")) == 0 {
+			return pos + len(line)
+		}
+	}
+}
+
+func (d *docsUpdater) close() {
+	if d.client != nil {
+		d.client.close()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsUpdateCmd)
+
+	docsCmd.PersistentFlags().StringVar(&vizAddress, "viz", "localhost:10000", "URI of an acrviz app")
+	docsCmd.PersistentFlags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to render views from")
+	docsCmd.PersistentFlags().StringVar(&outFormat, "outform", defaultOutForm, "Image format (png | svg)")
+	docsUpdateCmd.Flags().StringVar(&docsFormat, "format", "image", "Default format of the views (image | mermaid | puml)")
+	docsUpdateCmd.Flags().StringVar(&docsLibrary, "puml-library", "c4", "Directory of the C4-PlantUML library included by puml views, relative to each document")
+	docsUpdateCmd.Flags().BoolVar(&docsCheck, "check", false, "Only check the documents are up to date, fail if stale")
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koderizer/arc/viz/puml"
+)
+
+//quotedView is a marker quoted in a fenced code block, an image view which could not render without an arcviz server
+const quotedView = "````markdown\n<!-- arc:view container web -->\n```\n<!-- /arc:view -->\n````\n"
+
+const docsReadme = `# Shop
+<!-- arc:view component web.api format=mermaid -->
+outdated
+<!-- /arc:view -->
+
+Embed a view with:
+` + quotedView + `
+<!-- arc:view container web format=puml -->
+<!-- /arc:view -->
+`
+
+func TestReplaceViews(t *testing.T) {
+	tests := []struct {
+		content, expect string
+	}{
+		{"<!-- arc:view a -->\nold\n<!-- /arc:view -->\n", "view\n"},
+		{"```\n<!-- arc:view a -->\n<!-- /arc:view -->\n```\n<!-- arc:view b --><!-- /arc:view -->",
+			"```\n<!-- arc:view a -->\n<!-- /arc:view -->\n```\nview"},
+		//a fence is only closed by a line of at least as many of its characters
+		{"~~~~\n~~~\n<!-- arc:view a --><!-- /arc:view -->\n~~~~~ \n<!-- arc:view b --><!-- /arc:view -->",
+			"~~~~\n~~~\n<!-- arc:view a --><!-- /arc:view -->\n~~~~~ \nview"},
+		{"```\n<!-- arc:view a --><!-- /arc:view -->", "```\n<!-- arc:view a --><!-- /arc:view -->"},
+		//fences generated in views do not hide the following views
+		{"<!-- arc:view a -->\n```mermaid\n```\n<!-- /arc:view -->\n<!-- arc:view b --><!-- /arc:view -->", "view\nview"},
+	}
+	for i, test := range tests {
+		replaced := replaceViews([]byte(test.content), func(block []byte) []byte { return []byte("view") })
+		if string(replaced) != test.expect {
+			t.Errorf("Test %d: expect\n%s\nget\n%s", i, test.expect, replaced)
+		}
+	}
+}
+
+func TestUpdateDocs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"arc.yaml": refactorArc, "docs/README.md": docsReadme})
+	arc, err := loadArc(filepath.Join(dir, "arc.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	readme, library := filepath.Join(dir, "docs", "README.md"), filepath.Join(dir, "docs", "c4", "C4_Container.puml")
+	docsLibrary = "c4"
+	defer func() { docsCheck, docsLibrary = false, "" }()
+	docs := &docsUpdater{arc: arc, format: "image"}
+	defer docs.close()
+
+	//--check only list the stale files, failing the command
+	docsCheck = true
+	var stale int
+	out := captureOutput(t, func() { stale, err = docs.updateFiles([]string{readme}) })
+	if err != nil || stale != 2 || out != readme+" is stale\n"+library+" is stale\n" {
+		t.Errorf("expect a check to list the document and its library, get %d file(s): %s %v", stale, out, err)
+	}
+	if content, _ := ioutil.ReadFile(readme); string(content) != docsReadme {
+		t.Error("expect a check to leave the document untouched")
+	}
+	if _, err := os.Stat(library); !os.IsNotExist(err) {
+		t.Error("expect a check not to write the library")
+	}
+
+	docsCheck = false
+	captureOutput(t, func() { stale, err = docs.updateFiles([]string{readme}) })
+	if err != nil || stale != 2 {
+		t.Errorf("expect the document and its library to be written, get %d file(s): %v", stale, err)
+	}
+	content, _ := ioutil.ReadFile(readme)
+	for _, expect := range []string{
+		"<!-- arc:view component web.api format=mermaid -->\n```mermaid\n",
+		"Embed a view with:\n" + quotedView,
+		"<!-- arc:view container web format=puml -->\n```plantuml\n@startuml\n!include c4/C4_Container.puml\n",
+	} {
+		if !strings.Contains(string(content), expect) {
+			t.Errorf("expect %q in\n%s", expect, content)
+		}
+	}
+	if strings.Contains(string(content), "outdated") || strings.Contains(string(content), "!define LAYOUT_TOP_DOWN") {
+		t.Errorf("expect the views to be regenerated without inlining the C4 library, get\n%s", content)
+	}
+	expect, err := puml.Library("C4_Container")
+	if included, _ := ioutil.ReadFile(library); err != nil || string(included) != expect {
+		t.Errorf("expect the C4 library to be written next to the document: %v", err)
+	}
+
+	docsCheck = true
+	out = captureOutput(t, func() { stale, err = docs.updateFiles([]string{readme}) })
+	if err != nil || stale != 0 || out != "" {
+		t.Errorf("expect up to date documents to pass the check, get %d stale file(s): %s %v", stale, out, err)
+	}
+	arc.InternalSystems[0].Containers[0].Components[0].Desc = "the basket"
+	out = captureOutput(t, func() { stale, err = docs.updateFiles([]string{readme}) })
+	if err != nil || stale != 1 || out != readme+" is stale\n" {
+		t.Errorf("expect a document out of sync with the arc to fail the check, get %d stale file(s): %s %v", stale, out, err)
+	}
+}
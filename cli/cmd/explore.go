@@ -0,0 +1,104 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/koderizer/arc/cli/explore"
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+	"github.com/spf13/cobra"
+)
+
+// exploreCmd represents the explore command
+var exploreCmd = &cobra.Command{
+	Use:   "explore",
+	Short: "Browse an architecture interactively in the terminal",
+	Long: `
+Browse the arc file given with -f, or the workspace given with -w, in a full screen terminal interface:
+ - up and down select an element, right or enter list its containers or components, left goes back to its parent
+ - the selected element is shown with its incoming and outgoing relations, the relations of containers and components
+   being rolled up to their parents the same way as in the diagrams
+ - tab moves to the relations, and enter jumps to the element at the other end of the selected relation
+ - t filters the elements by tag, an empty tag showing all elements again
+ - r renders the selection with the arcviz server given with --viz: a system in its container view, a container
+   in its component view, and other elements in the landscape
+ - q quits`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isTerminal(os.Stdin) {
+			fmt.Println("arcli explore needs an interactive terminal")
+			os.Exit(1)
+		}
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		g, err := analyse(arc, model.PresentationPerspective_LANDSCAPE)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		explorer := explore.New(g)
+		explorer.Render = func(path string) (string, error) {
+			pers, targets := selectionView(g, path)
+			client, err := dialViz(vizAddress)
+			if err != nil {
+				return "", err
+			}
+			defer client.close()
+			uri, err := client.render(arc, pers, model.ArcVisualFormat_SVG, targets...)
+			if err != nil {
+				return "", err
+			}
+			open(uri)
+			return uri, nil
+		}
+		if err := explore.Run(explorer, os.Stdin, os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exploreCmd)
+	exploreCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to explore")
+	exploreCmd.Flags().StringVar(&vizAddress, "viz", "localhost:10000", "URI of an acrviz app rendering the selection")
+}
+
+//selectionView return the perspective and targets rendering an element: the container view of a system,
+//the component view of a container, or of the container of a component, and the landscape otherwise
+func selectionView(g *analyzer.Graph, path string) (model.PresentationPerspective, []string) {
+	v, ok := g.Element(path)
+	if !ok {
+		return model.PresentationPerspective_LANDSCAPE, nil
+	}
+	switch v.Kind {
+	case analyzer.VerticeTypeInternalSystem:
+		return model.PresentationPerspective_CONTAINER, []string{path}
+	case analyzer.VerticeTypeContainer:
+		if len(v.Entity.(model.Container).Components) > 0 {
+			return model.PresentationPerspective_COMPONENT, []string{path}
+		}
+		return model.PresentationPerspective_CONTAINER, []string{path[:len(path)-len(v.Entity.(model.Container).Name)-1]}
+	case analyzer.VerticeTypeComponent:
+		return model.PresentationPerspective_COMPONENT, []string{path[:len(path)-len(v.Entity.(model.Component).Name)-1]}
+	}
+	return model.PresentationPerspective_LANDSCAPE, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var fmtCheck bool
+
+// fmtCmd represents the fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [arc files]",
+	Short: "Rewrite arc yaml files in the canonical layout",
+	Long: `
+Rewrite the given arc files, the one given with -f, or all arc files of the workspace given with -w, in the canonical layout, keeping their comments:
+ - keys in the order of the arc format, unknown keys last
+ - relations grouped by subject system, in the order of the system names, each relation on one line as { s, p, o }
+ - indentation of 2 spaces, and a blank line around top level lists and mappings
+
+With --check, files are not rewritten, the files not in the canonical layout are listed and the command fails, eg in CI.
+
+Eg:
+	arcli fmt
+	arcli fmt --check -w .`,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := args
+		if len(files) == 0 && workspaceRoot != "" {
+			discovered, err := discoverArcFiles(workspaceRoot)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			files = discovered
+		}
+		if len(files) == 0 {
+			files = []string{arcFilename}
+		}
+		unformatted, err := formatFiles(files)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if fmtCheck && unformatted > 0 {
+			fmt.Printf("%d file(s) not formatted, run arcli fmt\n", unformatted)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+	fmtCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to format")
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "List the files not formatted and fail instead of rewriting them")
+}
+
+//formatFiles rewrite the arc files not in the canonical layout, or only list them with --check, and return their number
+func formatFiles(files []string) (int, error) {
+	unformatted := 0
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return unformatted, err
+		}
+		formatted, err := formatArc(content)
+		if err != nil {
+			return unformatted, fmt.Errorf("fail to format %s: %v", file, err)
+		}
+		if bytes.Equal(content, formatted) {
+			continue
+		}
+		unformatted++
+		if fmtCheck {
+			fmt.Println(file)
+			continue
+		}
+		if err := ioutil.WriteFile(file, formatted, 0644); err != nil {
+			return unformatted, err
+		}
+		fmt.Printf("Formatted %s\n", file)
+	}
+	return unformatted, nil
+}
+
+//formatArc rewrite the content of an arc yaml file, full arc or fragment, in the canonical layout
+func formatArc(content []byte) ([]byte, error) {
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(content, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return content, nil
+	}
+	root := doc.Content[0]
+	keys := make(map[string]interface{})
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keys[root.Content[i].Value] = true
+	}
+	if isFragment(keys) {
+		formatNode(root, reflect.TypeOf(model.Fragment{}))
+	} else {
+		formatNode(root, reflect.TypeOf(model.ArcType{}))
+	}
+	formatRelations(mappingValue(root, "relations"))
+
+	out := &bytes.Buffer{}
+	enc := yamlv3.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return separateKeys(out.Bytes()), nil
+}
+
+//formatNode order the keys of a mapping node as the fields of the type it is decoded into, recursively, and lay it out in block style
+func formatNode(node *yamlv3.Node, t reflect.Type) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case node.Kind == yamlv3.MappingNode && t.Kind() == reflect.Struct:
+		node.Style = 0
+		fields := make(map[string]int)
+		for i := 0; i < t.NumField(); i++ {
+			if name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]; name != "" && name != "-" {
+				fields[name] = i
+			}
+		}
+		pairs := make([][2]*yamlv3.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yamlv3.Node{node.Content[i], node.Content[i+1]})
+		}
+		rank := func(key string) int {
+			if i, ok := fields[key]; ok {
+				return i
+			}
+			return t.NumField()
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return rank(pairs[i][0].Value) < rank(pairs[j][0].Value) })
+		node.Content = node.Content[:0]
+		for _, pair := range pairs {
+			node.Content = append(node.Content, pair[0], pair[1])
+			if i, ok := fields[pair[0].Value]; ok {
+				formatNode(pair[1], t.Field(i).Type)
+			}
+		}
+	case node.Kind == yamlv3.SequenceNode && t.Kind() == reflect.Slice:
+		if elem := t.Elem(); elem.Kind() == reflect.Struct || elem.Kind() == reflect.Ptr {
+			node.Style = 0
+		}
+		for _, item := range node.Content {
+			formatNode(item, t.Elem())
+		}
+	case node.Kind == yamlv3.ScalarNode && node.Style&(yamlv3.LiteralStyle|yamlv3.FoldedStyle) != 0:
+		//trailing spaces would turn a block scalar into a quoted one
+		lines := strings.Split(node.Value, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		node.Value = strings.Join(lines, "\n")
+	}
+}
+
+//formatRelations group relations by subject system, in the order of the system names, and lay each one on a single line
+func formatRelations(relations *yamlv3.Node) {
+	if relations == nil || relations.Kind != yamlv3.SequenceNode {
+		return
+	}
+	system := func(rel *yamlv3.Node) string {
+		if s := mappingValue(rel, "s"); s != nil {
+			return strings.Split(s.Value, ".")[0]
+		}
+		return ""
+	}
+	sort.SliceStable(relations.Content, func(i, j int) bool {
+		return system(relations.Content[i]) < system(relations.Content[j])
+	})
+	for _, rel := range relations.Content {
+		if rel.Kind != yamlv3.MappingNode {
+			continue
+		}
+		rel.Style = yamlv3.FlowStyle
+		//comments within a flow mapping are moved to the relation
+		for _, n := range rel.Content {
+			rel.HeadComment = joinComments(rel.HeadComment, n.HeadComment)
+			rel.LineComment = joinComments(rel.LineComment, n.LineComment)
+			rel.FootComment = joinComments(rel.FootComment, n.FootComment)
+			n.HeadComment, n.LineComment, n.FootComment = "", "", ""
+		}
+	}
+}
+
+func joinComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n" + b
+}
+
+//separateKeys insert a blank line, above its comments, before each top level key holding or following a list or a mapping,
+//and before each top level comment following a list or a mapping
+func separateKeys(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+	out := make([]string, 0, len(lines))
+	for i, line := range lines {
+		topLevel := line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "-")
+		if i > 0 && topLevel {
+			start := len(out)
+			for start > 0 && strings.HasPrefix(out[start-1], "#") {
+				start--
+			}
+			nested := strings.HasSuffix(line, ":") || (start > 0 && (strings.HasPrefix(out[start-1], " ") || strings.HasPrefix(out[start-1], "-")))
+			if nested && start > 0 && out[start-1] != "" {
+				out = append(out[:start], append([]string{""}, out[start:]...)...)
+			}
+		}
+		//a top level comment ending a list or a mapping starts a new section
+		if strings.HasPrefix(line, "#") && len(out) > 0 && (strings.HasPrefix(out[len(out)-1], " ") || strings.HasPrefix(out[len(out)-1], "-")) {
+			out = append(out, "")
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatArc(t *testing.T) {
+	input, err := ioutil.ReadFile("testdata/fmt/input.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := ioutil.ReadFile("testdata/fmt/output.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := formatArc(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != string(golden) {
+		t.Errorf("expect\n%s\nget\n%s", golden, formatted)
+	}
+	for _, comment := range []string{"# architecture of the shop", "# checkout flow", "# main use case", "# back office", "# the storefront"} {
+		if !strings.Contains(string(formatted), comment) {
+			t.Errorf("expect the comment %q to be kept", comment)
+		}
+	}
+	again, err := formatArc(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(formatted) {
+		t.Errorf("expect formatting to be idempotent, get\n%s", again)
+	}
+}
+
+func TestFormatFiles(t *testing.T) {
+	input, err := ioutil.ReadFile("testdata/fmt/input.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := ioutil.ReadFile("testdata/fmt/output.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"arc.yaml": string(input), "api/arc.yaml": string(golden)})
+	files := []string{filepath.Join(dir, "arc.yaml"), filepath.Join(dir, "api", "arc.yaml")}
+	defer func() { fmtCheck = false }()
+
+	//--check only list the files not formatted, failing the command
+	fmtCheck = true
+	var unformatted int
+	out := captureOutput(t, func() { unformatted, err = formatFiles(files) })
+	if err != nil || unformatted != 1 || strings.TrimSpace(out) != files[0] {
+		t.Errorf("expect a check to list %s only, get %d file(s): %s %v", files[0], unformatted, out, err)
+	}
+	if content, _ := ioutil.ReadFile(files[0]); string(content) != string(input) {
+		t.Error("expect a check to leave the files untouched")
+	}
+
+	fmtCheck = false
+	captureOutput(t, func() { unformatted, err = formatFiles(files) })
+	if err != nil || unformatted != 1 {
+		t.Errorf("expect 1 file formatted, get %d: %v", unformatted, err)
+	}
+	if content, _ := ioutil.ReadFile(files[0]); string(content) != string(golden) {
+		t.Errorf("expect the file to be rewritten in the canonical layout, get\n%s", content)
+	}
+	fmtCheck = true
+	captureOutput(t, func() { unformatted, err = formatFiles(files) })
+	if err != nil || unformatted != 0 {
+		t.Errorf("expect formatted files to pass the check, get %d file(s) not formatted: %v", unformatted, err)
+	}
+}
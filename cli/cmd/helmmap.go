@@ -87,143 +87,8 @@ The default behavior is recursively look at all sub directories to profile
 		arcData.InternalSystems = make([]model.InternalSystem, 0)
 		arcData.ExternalSystems = make([]model.ExternalSystem, 0)
 
-		actionConfig := &action.Configuration{
-			Releases:     storage.Init(driver.NewMemory()),
-			KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
-			Capabilities: chartutil.DefaultCapabilities,
-			Log:          func(format string, v ...interface{}) {},
-		}
-		client := action.NewInstall(actionConfig)
-		client.DryRun = true
-		client.Replace = true // Skip the name check
-		client.ClientOnly = true
-		client.APIVersions = chartutil.VersionSet(extraAPIs)
-		skip := []string{}
-		for _, f := range files {
-			name := filepath.Base(f)
-			client.ReleaseName = name
-			settings := cli.New()
-			cp, err := client.ChartPathOptions.LocateChart(f, settings)
-			if err != nil {
-				fmt.Println(err)
-				skip = append(skip, f)
-				continue
-			}
-			p := getter.All(settings)
-			valueOpts := &values.Options{}
-			vals, err := valueOpts.MergeValues(p)
-			if err != nil {
-				fmt.Println(err)
-				skip = append(skip, f)
-				continue
-			}
-
-			// Check chart dependencies to make sure all are present in /charts
-			chartRequested, err := loader.Load(cp)
-			if err != nil {
-				fmt.Println(err)
-				skip = append(skip, f)
-				continue
-			}
-
-			if req := chartRequested.Metadata.Dependencies; req != nil {
-				// If CheckDependencies returns an error, we have unfulfilled dependencies.
-				// As of Helm 2.4.0, this is treated as a stopping condition:
-				// https://github.com/helm/helm/issues/2209
-				if err = action.CheckDependencies(chartRequested, req); err != nil {
-					if client.DependencyUpdate {
-						man := &downloader.Manager{
-							Out:              os.Stdout,
-							ChartPath:        cp,
-							Keyring:          client.ChartPathOptions.Keyring,
-							SkipUpdate:       false,
-							Getters:          p,
-							RepositoryConfig: settings.RepositoryConfig,
-							RepositoryCache:  settings.RepositoryCache,
-							Debug:            settings.Debug,
-						}
-						if err = man.Update(); err != nil {
-							fmt.Println(err)
-							skip = append(skip, f)
-							continue
-						}
-						// Reload the chart with the updated Chart.lock file.
-						if chartRequested, err = loader.Load(cp); err != nil {
-							fmt.Println(err)
-							skip = append(skip, f)
-							continue
-						}
-					} else {
-						fmt.Println(err)
-						skip = append(skip, f)
-						continue
-					}
-				}
-			}
-
-			client.Namespace = settings.Namespace()
-			rel, err := client.Run(chartRequested, vals)
-			if err != nil {
-				fmt.Println(err)
-				skip = append(skip, f)
-				continue
-			}
-
-			manifests := strings.Split(rel.Manifest, "---")
-			for i, man := range manifests {
-				if i == 0 {
-					continue
-				}
-				// fmt.Println("section ", i, man)
-				manifest := &ManifestData{}
-				decode := serializer.NewCodecFactory(sch).UniversalDeserializer().Decode
-				kubeObj, _, err := decode([]byte(man), nil, nil)
-				if err != nil {
-					fmt.Println(err)
-					skip = append(skip, f)
-					continue
-				}
-				if err := yaml.Unmarshal([]byte(man), manifest); err != nil {
-					fmt.Println(err)
-					skip = append(skip, f)
-					continue
-				}
-				switch manifest.APIVersion + "." + manifest.Kind {
-				case "extensions/v1beta1.Deployment":
-					deployment := kubeObj.(*v1beta1.Deployment)
-					runtime := "default"
-					if ns := deployment.GetNamespace(); ns != "" {
-						runtime = ns
-					}
-					system := model.InternalSystem{
-						Name:       deployment.GetName(),
-						Desc:       "Deployment " + cp,
-						Containers: make([]model.Container, 0),
-					}
-
-					for _, c := range deployment.Spec.Template.Spec.Containers {
-						system.Containers = append(system.Containers, model.Container{
-							Name:       c.Name,
-							Desc:       "Image " + c.Image,
-							Technology: "k8s-container",
-							Runtime:    runtime,
-						})
-					}
-					arcData.InternalSystems = append(arcData.InternalSystems, system)
-				case "v1.Service":
-					// service := kubeObj.(*v1.Service)
-					// system := model.InternalSystem{
-					// 	Name:       service.GetName(),
-					// 	Desc:       "Service " + cp,
-					// 	Containers: make([]model.Container, 0),
-					// }
-					// arcData.InternalSystems = append(arcData.InternalSystems, system)
-					fmt.Println("Thinking how to treat Service abstraction")
-				default:
-					fmt.Println("Not yet supported: ", manifest.APIVersion, manifest.Kind)
-				}
-			}
-		}
+		systems, skip := mapHelmCharts(files)
+		arcData.InternalSystems = append(arcData.InternalSystems, systems...)
 		arcTpl, err := template.New("arcTemplate").Parse(arcTemplate)
 		if err != nil {
 			fmt.Println(err)
@@ -250,6 +115,149 @@ The default behavior is recursively look at all sub directories to profile
 	},
 }
 
+//mapHelmCharts render the helm charts and map their deployments to internal systems, returning the charts skipped
+func mapHelmCharts(files []string) ([]model.InternalSystem, []string) {
+	actionConfig := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(format string, v ...interface{}) {},
+	}
+	client := action.NewInstall(actionConfig)
+	client.DryRun = true
+	client.Replace = true // Skip the name check
+	client.ClientOnly = true
+	client.APIVersions = chartutil.VersionSet(extraAPIs)
+	systems := make([]model.InternalSystem, 0)
+	skip := []string{}
+	for _, f := range files {
+		name := filepath.Base(f)
+		client.ReleaseName = name
+		settings := cli.New()
+		cp, err := client.ChartPathOptions.LocateChart(f, settings)
+		if err != nil {
+			fmt.Println(err)
+			skip = append(skip, f)
+			continue
+		}
+		p := getter.All(settings)
+		valueOpts := &values.Options{}
+		vals, err := valueOpts.MergeValues(p)
+		if err != nil {
+			fmt.Println(err)
+			skip = append(skip, f)
+			continue
+		}
+
+		// Check chart dependencies to make sure all are present in /charts
+		chartRequested, err := loader.Load(cp)
+		if err != nil {
+			fmt.Println(err)
+			skip = append(skip, f)
+			continue
+		}
+
+		if req := chartRequested.Metadata.Dependencies; req != nil {
+			// If CheckDependencies returns an error, we have unfulfilled dependencies.
+			// As of Helm 2.4.0, this is treated as a stopping condition:
+			// https://github.com/helm/helm/issues/2209
+			if err = action.CheckDependencies(chartRequested, req); err != nil {
+				if client.DependencyUpdate {
+					man := &downloader.Manager{
+						Out:              os.Stdout,
+						ChartPath:        cp,
+						Keyring:          client.ChartPathOptions.Keyring,
+						SkipUpdate:       false,
+						Getters:          p,
+						RepositoryConfig: settings.RepositoryConfig,
+						RepositoryCache:  settings.RepositoryCache,
+						Debug:            settings.Debug,
+					}
+					if err = man.Update(); err != nil {
+						fmt.Println(err)
+						skip = append(skip, f)
+						continue
+					}
+					// Reload the chart with the updated Chart.lock file.
+					if chartRequested, err = loader.Load(cp); err != nil {
+						fmt.Println(err)
+						skip = append(skip, f)
+						continue
+					}
+				} else {
+					fmt.Println(err)
+					skip = append(skip, f)
+					continue
+				}
+			}
+		}
+
+		client.Namespace = settings.Namespace()
+		rel, err := client.Run(chartRequested, vals)
+		if err != nil {
+			fmt.Println(err)
+			skip = append(skip, f)
+			continue
+		}
+
+		manifests := strings.Split(rel.Manifest, "---")
+		for i, man := range manifests {
+			if i == 0 {
+				continue
+			}
+			// fmt.Println("section ", i, man)
+			manifest := &ManifestData{}
+			decode := serializer.NewCodecFactory(sch).UniversalDeserializer().Decode
+			kubeObj, _, err := decode([]byte(man), nil, nil)
+			if err != nil {
+				fmt.Println(err)
+				skip = append(skip, f)
+				continue
+			}
+			if err := yaml.Unmarshal([]byte(man), manifest); err != nil {
+				fmt.Println(err)
+				skip = append(skip, f)
+				continue
+			}
+			switch manifest.APIVersion + "." + manifest.Kind {
+			case "extensions/v1beta1.Deployment":
+				deployment := kubeObj.(*v1beta1.Deployment)
+				runtime := "default"
+				if ns := deployment.GetNamespace(); ns != "" {
+					runtime = ns
+				}
+				system := model.InternalSystem{
+					Name:       deployment.GetName(),
+					Desc:       "Deployment " + cp,
+					Containers: make([]model.Container, 0),
+				}
+
+				for _, c := range deployment.Spec.Template.Spec.Containers {
+					system.Containers = append(system.Containers, model.Container{
+						Name:       c.Name,
+						Desc:       "Image " + c.Image,
+						Technology: "k8s-container",
+						Runtime:    runtime,
+					})
+				}
+				systems = append(systems, system)
+			case "v1.Service":
+				// service := kubeObj.(*v1.Service)
+				// system := model.InternalSystem{
+				// 	Name:       service.GetName(),
+				// 	Desc:       "Service " + cp,
+				// 	Containers: make([]model.Container, 0),
+				// }
+				// systems = append(systems, system)
+				fmt.Println("Thinking how to treat Service abstraction")
+			default:
+				fmt.Println("Not yet supported: ", manifest.APIVersion, manifest.Kind)
+			}
+		}
+	}
+	return systems, skip
+}
+
 func getHelms(dir string) []string {
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
@@ -0,0 +1,167 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+)
+
+//gitIncludePrefix mark an include of an arc file inside a git repository, as git::<repository>//<path>[?ref=<ref>]
+const gitIncludePrefix = "git::"
+
+//unsafePathChars are replaced to name the checkout directory of a git repository
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+//load read the arc file and merge in the arc files it includes, an including file taking precedence over its includes
+func (l *arcLoader) load(filename string) (*model.ArcType, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	l.loaded[path] = true
+	l.stack = append(l.stack, path)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	arc, err := l.read(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, include := range arc.Include {
+		included, err := l.resolve(include, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("fail to include %s in %s: %v", include, l.rel(path), err)
+		}
+		for _, p := range l.stack {
+			if p == included {
+				return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(l.stack, " -> "), included)
+			}
+		}
+		if l.loaded[included] {
+			continue
+		}
+		sub, err := l.load(included)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.merge(arc, sub); err != nil {
+			return nil, fmt.Errorf("fail to include %s in %s: %v", include, l.rel(path), err)
+		}
+	}
+	arc.Include = nil
+	return arc, nil
+}
+
+//resolve return the path of an included arc file, relative paths being relative to the including file directory
+func (l *arcLoader) resolve(include, dir string) (string, error) {
+	if !strings.HasPrefix(include, gitIncludePrefix) {
+		if filepath.IsAbs(include) {
+			return include, nil
+		}
+		return filepath.Abs(filepath.Join(dir, include))
+	}
+	repo, path, ref := parseGitInclude(strings.TrimPrefix(include, gitIncludePrefix))
+	if repo == "" || path == "" {
+		return "", fmt.Errorf("git include must be of the form %s<repository>//<path>[?ref=<ref>]", gitIncludePrefix)
+	}
+	//a repository or ref starting with - would be read as an option by git
+	if strings.HasPrefix(repo, "-") || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git include repository %q or ref %q", repo, ref)
+	}
+	path = filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("git include path %s must be within the repository", path)
+	}
+	checkout, err := l.checkout(repo, ref)
+	if err != nil {
+		return "", err
+	}
+	included := filepath.Join(checkout, path)
+	if !insideDir(checkout, included) {
+		return "", fmt.Errorf("git include path %s must be within the repository", path)
+	}
+	return included, nil
+}
+
+//insideDir tell whether a path, once its symbolic links are resolved, is inside the directory
+func insideDir(dir, path string) bool {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+//parseGitInclude split a git include into its repository, the path of the arc file in it and the optional ref
+func parseGitInclude(include string) (repo, path, ref string) {
+	if i := strings.LastIndex(include, "?ref="); i >= 0 {
+		include, ref = include[:i], include[i+len("?ref="):]
+	}
+	start := 0
+	if i := strings.Index(include, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	i := strings.Index(include[start:], "//")
+	if i < 0 {
+		return include, "", ref
+	}
+	return include[:start+i], include[start+i+2:], ref
+}
+
+//checkout fetch the ref, or the default branch, of a git repository into the cache and return the checkout directory
+func (l *arcLoader) checkout(repo, ref string) (string, error) {
+	dir := filepath.Join(l.cacheDir, strings.Trim(unsafePathChars.ReplaceAllString(repo+"@"+ref, "_"), "_"))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("fail to create checkout directory: %v", err)
+		}
+		if err := git(dir, "init", "-q"); err != nil {
+			return "", err
+		}
+		if err := git(dir, "remote", "add", "--", "origin", repo); err != nil {
+			return "", err
+		}
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := git(dir, "fetch", "-q", "--depth", "1", "--", "origin", ref); err != nil {
+		return "", err
+	}
+	if err := git(dir, "checkout", "-q", "--detach", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+//git run a git command in the given directory
+func git(dir string, args ...string) error {
+	//the ext transport run arbitrary commands
+	out, err := exec.Command("git", append([]string{"-c", "protocol.ext.allow=never", "-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s failed: %v %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitInclude(t *testing.T) {
+	tests := []struct {
+		include, repo, path, ref string
+	}{
+		{"https://host/repo//path/arc.yaml?ref=v1", "https://host/repo", "path/arc.yaml", "v1"},
+		{"https://host/repo//arc.yaml", "https://host/repo", "arc.yaml", ""},
+		{"git@host:org/repo.git//services/arc.yaml?ref=main", "git@host:org/repo.git", "services/arc.yaml", "main"},
+		{"host/repo//arc.yaml?ref=v1", "host/repo", "arc.yaml", "v1"},
+		{"https://host/repo?ref=v1", "https://host/repo", "", "v1"},
+	}
+	for _, test := range tests {
+		repo, path, ref := parseGitInclude(test.include)
+		if repo != test.repo || path != test.path || ref != test.ref {
+			t.Errorf("%s: expect %s %s %s, get %s %s %s", test.include, test.repo, test.path, test.ref, repo, path, ref)
+		}
+	}
+}
+
+func TestResolveGitInclude(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo, outside := t.TempDir(), t.TempDir()
+	writeFiles(t, repo, map[string]string{"arc.yaml": "app: remote\n"})
+	writeFiles(t, outside, map[string]string{"secret.yaml": "app: secret\n"})
+	if err := os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(repo, "link.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"-c", "user.name=test", "-c", "user.email=test@test", "commit", "-q", "-m", "arc"}} {
+		if err := git(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l := newArcLoader()
+	l.cacheDir = t.TempDir()
+	included, err := l.resolve("git::"+repo+"//arc.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := ioutil.ReadFile(included); string(content) != "app: remote\n" {
+		t.Errorf("expect the arc file of the repository, get %q", content)
+	}
+	pwned := filepath.Join(outside, "pwned")
+	for _, include := range []string{
+		"git::" + repo + "//arc.yaml?ref=--upload-pack=touch " + pwned + ";git-upload-pack",
+		"git::--upload-pack=touch " + pwned + "//arc.yaml",
+		"git::" + repo + "//../../" + filepath.Base(outside) + "/secret.yaml",
+		"git::" + repo + "//sub/../../secret.yaml",
+		"git::" + repo + "//link.yaml",
+		"git::" + repo + "//arc.yaml?ref=no-such-ref",
+	} {
+		if _, err := l.resolve(include, ""); err == nil {
+			t.Errorf("expect %s to be rejected", include)
+		}
+	}
+	if _, err := os.Stat(pwned); err == nil {
+		t.Error("expect git options in an include not to be run")
+	}
+	//a failing fetch is reported rather than falling back to the previous checkout
+	if err := os.RemoveAll(filepath.Join(repo, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.resolve("git::"+repo+"//arc.yaml", ""); err == nil {
+		t.Error("expect a failing fetch to be reported")
+	}
+}
@@ -0,0 +1,450 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/koderizer/arc/model"
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var initOut string
+var initForce bool
+var initYes bool
+var initDetect bool
+var initApp string
+var initDesc string
+var initUsers []string
+var initSystems []string
+var initContainers []string
+var initExternals []string
+
+//composeFiles are the names of the docker compose files seeding the containers of the application
+var composeFiles = []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
+
+//goModule match the module path of a go.mod file
+var goModule = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?`)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init [directory]",
+	Short: "Create a commented arc.yaml for the application in a directory",
+	Long: `
+Create an arc.yaml for the application in the given directory, or the current one, asking for the application, its users, systems and containers.
+
+The containers are seeded from what is found in the directory:
+ - the services of a docker-compose.yml
+ - the module of a go.mod
+ - the deployments of Helm charts, as systems
+
+Without terminal, or with --yes, nothing is asked: the answers are taken from the flags, the detected containers and defaults.
+The written file is validated, and an existing file is only replaced with --force.
+
+Eg:
+	arcli init
+	arcli init --yes --app shop --user buyer:"Buy things" --system shop --container shop.api:golang --container shop.db:postgres`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		out := initOut
+		if !filepath.IsAbs(out) {
+			out = filepath.Join(dir, out)
+		}
+		if _, err := os.Stat(out); err == nil && !initForce {
+			fmt.Printf("%s already exist, use --force to replace it\n", out)
+			os.Exit(1)
+		}
+		interactive := !initYes && isTerminal(os.Stdin)
+		arc, err := scaffoldArc(dir, &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout, enabled: interactive})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		content, err := writeScaffold(arc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := validateScaffold(out, content); err != nil {
+			fmt.Printf("the generated arc is not valid: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ioutil.WriteFile(out, content, 0644); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s, inspect it with: arcli inspect landscape -f %s\n", out, out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVarP(&initOut, "out", "o", defaultArcFile, "Name of the arc file to create, relative to the directory")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Replace an existing arc file")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Do not ask anything, taking the answers from the flags, the detected containers and defaults")
+	initCmd.Flags().BoolVar(&initDetect, "detect", true, "Seed the containers from the docker-compose.yml, go.mod and Helm charts of the directory")
+	initCmd.Flags().StringVar(&initApp, "app", "", "Name of the application, the directory name by default")
+	initCmd.Flags().StringVar(&initDesc, "desc", "", "Description of the application")
+	initCmd.Flags().StringSliceVar(&initUsers, "user", nil, "User of the application, as name[:role]")
+	initCmd.Flags().StringSliceVar(&initSystems, "system", nil, "Internal system, as name[:description], the application by default")
+	initCmd.Flags().StringSliceVar(&initContainers, "container", nil, "Container of an internal system, as system.name[:technology]")
+	initCmd.Flags().StringSliceVar(&initExternals, "external", nil, "External system, as name[:description]")
+}
+
+//prompter ask questions on a terminal, or answer them with their default when disabled
+type prompter struct {
+	in      *bufio.Reader
+	out     io.Writer
+	enabled bool
+}
+
+//ask return the answer to the question, or its default when the answer is empty
+func (p *prompter) ask(question, def string) string {
+	if !p.enabled {
+		return def
+	}
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, _ := p.in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
+
+//confirm ask a yes or no question, yes by default
+func (p *prompter) confirm(question string) bool {
+	answer := strings.ToLower(p.ask(question+" (y/n)", "y"))
+	return answer == "y" || answer == "yes"
+}
+
+//isTerminal tell whether the file is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+//scaffoldArc build the arc of the application in the directory from the flags, the detected containers and the answers to the prompts
+func scaffoldArc(dir string, p *prompter) (*model.ArcType, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	arc := &model.ArcType{Version: model.Version}
+	arc.App = p.ask("Application name", orDefault(initApp, filepath.Base(abs)))
+	arc.Desc = p.ask("Description", orDefault(initDesc, "Architecture of "+arc.App))
+
+	users := p.ask("Users, as name[:role] separated by commas", orDefault(strings.Join(initUsers, ","), "user"))
+	for _, u := range splitList(users) {
+		name, role := splitPair(u)
+		arc.Users = append(arc.Users, model.User{Name: name, Role: orDefault(role, "Use "+arc.App)})
+	}
+
+	//detected containers belong to the application system, detected Helm deployments are systems of their own
+	seeded := make(map[string][]model.Container)
+	seededSystems := make([]model.InternalSystem, 0)
+	if initDetect {
+		containers, sources := detectContainers(abs)
+		systems, charts := detectCharts(abs)
+		if charts > 0 {
+			sources = append(sources, fmt.Sprintf("%d Helm chart(s)", charts))
+		}
+		if len(sources) > 0 {
+			fmt.Fprintf(p.out, "Detected %s\n", strings.Join(sources, ", "))
+			if p.confirm("Seed the arc with the detected containers") {
+				seeded[arc.App] = containers
+				seededSystems = systems
+			}
+		}
+	}
+
+	for _, sys := range seededSystems {
+		seeded[sys.Name] = sys.Containers
+	}
+	containerSystems := make([]string, 0)
+	for _, c := range initContainers {
+		path, technology := splitPair(c)
+		system, name := splitPath(path)
+		if system == "" {
+			return nil, fmt.Errorf("container %s must be given as system.name[:technology]", c)
+		}
+		if len(seeded[system]) == 0 {
+			containerSystems = append(containerSystems, system)
+		}
+		seeded[system] = append(seeded[system], model.Container{Name: name, Technology: technology})
+	}
+	defaultSystems := strings.Join(initSystems, ",")
+	if defaultSystems == "" {
+		names := make([]string, 0)
+		if len(seeded[arc.App]) > 0 || len(seededSystems)+len(containerSystems) == 0 {
+			names = append(names, arc.App)
+		}
+		for _, sys := range seededSystems {
+			names = append(names, sys.Name)
+		}
+		for _, sys := range containerSystems {
+			if sys != arc.App {
+				names = append(names, sys)
+			}
+		}
+		defaultSystems = strings.Join(names, ",")
+	}
+
+	systems := p.ask("Internal systems, as name[:description] separated by commas", defaultSystems)
+	for _, s := range splitList(systems) {
+		name, desc := splitPair(s)
+		sys := model.InternalSystem{Name: name, Desc: orDefault(desc, "System "+name+" of "+arc.App)}
+		for _, seededSys := range seededSystems {
+			if seededSys.Name == name && desc == "" {
+				sys.Desc = seededSys.Desc
+			}
+		}
+		names := make([]string, 0)
+		for _, c := range seeded[name] {
+			names = append(names, strings.TrimSuffix(c.Name+":"+c.Technology, ":"))
+		}
+		answer := p.ask(fmt.Sprintf("Containers of %s, as name[:technology] separated by commas", name), strings.Join(names, ","))
+		for _, c := range splitList(answer) {
+			cname, technology := splitPair(c)
+			container := model.Container{Name: cname, Technology: technology}
+			for _, existing := range seeded[name] {
+				if existing.Name == cname && (technology == "" || technology == existing.Technology) {
+					container = existing
+				}
+			}
+			sys.Containers = append(sys.Containers, container)
+		}
+		arc.InternalSystems = append(arc.InternalSystems, sys)
+	}
+
+	externals := p.ask("External systems, as name[:description] separated by commas", strings.Join(initExternals, ","))
+	for _, e := range splitList(externals) {
+		name, desc := splitPair(e)
+		arc.ExternalSystems = append(arc.ExternalSystems, model.ExternalSystem{Name: name, Desc: orDefault(desc, "External system "+name)})
+	}
+
+	if len(arc.Users) > 0 && len(arc.InternalSystems) > 0 {
+		arc.Relations = append(arc.Relations, model.Relation{Subject: arc.Users[0].Name, Pointer: "use", Object: arc.InternalSystems[0].Name})
+	}
+	return arc, nil
+}
+
+//detectContainers return the containers of the docker compose services and of the go module of the directory, with the files they come from
+func detectContainers(dir string) ([]model.Container, []string) {
+	containers := make([]model.Container, 0)
+	sources := make([]string, 0)
+	for _, name := range composeFiles {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		compose := struct {
+			Services map[string]struct {
+				Image string `yaml:"image"`
+			} `yaml:"services"`
+		}{}
+		if err := yamlv3.Unmarshal(content, &compose); err != nil || len(compose.Services) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(compose.Services))
+		for service := range compose.Services {
+			names = append(names, service)
+		}
+		sort.Strings(names)
+		for _, service := range names {
+			technology := "docker"
+			if image := compose.Services[service].Image; image != "" {
+				//the technology is the image name without registry nor tag, eg postgres for docker.io/library/postgres:13
+				image = strings.Split(image, "@")[0]
+				if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+					image = image[:i]
+				}
+				technology = image[strings.LastIndex(image, "/")+1:]
+			}
+			containers = append(containers, model.Container{Name: service, Runtime: "docker", Technology: technology})
+		}
+		sources = append(sources, fmt.Sprintf("%s (%s)", name, strings.Join(names, ", ")))
+		break
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		if m := goModule.FindSubmatch(content); m != nil {
+			module := string(m[1])
+			name := module[strings.LastIndex(module, "/")+1:]
+			exist := false
+			for _, c := range containers {
+				exist = exist || c.Name == name
+			}
+			if !exist {
+				containers = append(containers, model.Container{Name: name, Technology: "golang", Desc: "Go module " + module})
+			}
+			sources = append(sources, fmt.Sprintf("go.mod (%s)", module))
+		}
+	}
+	return containers, sources
+}
+
+//detectCharts return the systems mapped from the deployments of the Helm charts of the directory, and the number of charts
+func detectCharts(dir string) ([]model.InternalSystem, int) {
+	charts := getHelms(dir)
+	if len(charts) == 0 {
+		return nil, 0
+	}
+	systems, _ := mapHelmCharts(charts)
+	return systems, len(charts)
+}
+
+//splitList split a comma separated answer
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+//splitPair split an answer of the form name[:detail]
+func splitPair(pair string) (string, string) {
+	if i := strings.Index(pair, ":"); i >= 0 {
+		return strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+	}
+	return strings.TrimSpace(pair), ""
+}
+
+//scaffoldTemplate write a new arc file, commented to introduce the format
+var scaffoldTemplate = template.Must(template.New("scaffold").Funcs(template.FuncMap{"yaml": yamlScalar}).Parse(`# Software architecture of {{yaml .App}}, see https://github.com/koderizer/arc for the format.
+# View it with: arcli inspect landscape
+version: {{.Version}}
+app: {{yaml .App}}
+desc: {{yaml .Desc}}
+
+# People who use the application
+users:
+{{- range .Users}}
+  - name: {{yaml .Name}}
+    role: {{yaml .Role}}
+{{- end}}
+
+# Software systems of the application, made of containers such as services, web apps and databases.
+# The kind of a container is inferred from its runtime and technology, or set with kind.
+internal-systems:
+{{- range .InternalSystems}}
+  - name: {{yaml .Name}}
+    desc: {{yaml .Desc}}
+{{- if .Containers}}
+    containers:
+{{- range .Containers}}
+      - name: {{yaml .Name}}
+{{- if .Desc}}
+        desc: {{yaml .Desc}}
+{{- end}}
+{{- if .Runtime}}
+        runtime: {{yaml .Runtime}}
+{{- end}}
+{{- if .Technology}}
+        technology: {{yaml .Technology}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+
+# Software systems outside of the application that it interacts with
+{{- if .ExternalSystems}}
+external-systems:
+{{- range .ExternalSystems}}
+  - name: {{yaml .Name}}
+    desc: {{yaml .Desc}}
+{{- end}}
+{{- else}}
+# external-systems:
+#   - name: payment-provider
+#     desc: Process card payments
+#
+{{- end}}
+
+# Relationships between elements, given by path such as system.container.component
+relations:
+{{- range .Relations}}
+  - {s: {{yaml .Subject}}, p: {{yaml .Pointer}}, o: {{yaml .Object}}}
+{{- end}}
+`))
+
+//yamlScalar write a string as a yaml scalar, quoted only when needed
+func yamlScalar(s string) (string, error) {
+	node := &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: s}
+	//flow indicators are quoted too, as relations are written in flow style
+	if strings.ContainsAny(s, "{}[],") {
+		node.Style = yamlv3.DoubleQuotedStyle
+	}
+	out, err := yamlv3.Marshal(node)
+	return strings.TrimSuffix(string(out), "\n"), err
+}
+
+//writeScaffold write the arc file of a scaffolded arc
+func writeScaffold(arc *model.ArcType) ([]byte, error) {
+	out := &bytes.Buffer{}
+	if err := scaffoldTemplate.Execute(out, arc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+//validateScaffold check that a scaffolded arc file can be read and viewed
+func validateScaffold(filename string, content []byte) error {
+	arc, err := readArc(filename, content)
+	if err != nil {
+		return err
+	}
+	if err := newArcLoader().finish(arc); err != nil {
+		return err
+	}
+	if _, err := analyse(arc, model.PresentationPerspective_LANDSCAPE); err != nil {
+		return err
+	}
+	for _, sys := range arc.InternalSystems {
+		if _, err := analyse(arc, model.PresentationPerspective_CONTAINER, sys.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//orDefault return the value, or the default when the value is empty
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -16,22 +16,29 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os/exec"
 	"runtime"
 
 	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/ascii"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
-	"gopkg.in/yaml.v2"
 )
 
 var vizAddress string
 var arcFilename string
 var outFormat string
+var inspectASCII bool
+var inspectCharset string
+var inspectFocus string
+var inspectDepth int
+var inspectDirection string
+var inspectExclude []string
+var inspectHideUsers bool
+var inspectHideExternal bool
+var inspectRelationLevel string
+var inspectRollUp string
 
 //defaultArcFile point to the arc.yaml in the current directory arcli run
 const defaultArcFile = "./arc.yaml"
@@ -54,93 +61,121 @@ inspect take in 0 to n arguments, as follow:
 Eg: 
 To render the Container perspective of your amazingSystem1 and amazingSystem2 as specified in an arc.yaml file in the current directory
 
-	arcli inspect container amazingSystem1 amazingSystem2`,
+	arcli inspect container amazingSystem1 amazingSystem2
+
+A target can also name a group of systems, to render all the systems of the group and of its nested groups
+
+	arcli inspect context payments
+
+With --ascii, the perspective is drawn as boxes and arrows in the terminal instead, without an arcviz server
+
+	arcli inspect container amazingSystem1 --ascii
+
+With --focus, any element is drawn with its neighbours up to --depth hops away through the relations, in place of a perspective.
+Neighbours are shown at the level the relations refer to them, and --direction restricts the relations followed to the
+outgoing or incoming ones
+
+	arcli inspect --focus amazingSystem1.api --depth 2 --direction out
+
+Elements can be left out of any view with their children, by path or glob with --exclude, or as targets prefixed with !.
+--hide-users and --hide-external leave out all users or external systems, and --relation-level system or container
+hides the relations of the elements more detailed than the level
+
+	arcli inspect landscape --exclude "legacy-*" --hide-users
+	arcli inspect context payments '!payments.legacy-*'
+
+The relations of components are rolled up between their containers in container views, and the relations of containers
+and components between their systems in landscape and context views, each labelled with the number, pointers and
+technologies of the relations it rolls up. --roll-up container only rolls up the relations of components, and none draws
+the declared relations only
+
+	arcli inspect container amazingSystem1 --roll-up none`,
 
 	Run: func(cmd *cobra.Command, args []string) {
-		arcFile, err := ioutil.ReadFile(arcFilename)
-		if err != nil {
-			fmt.Println("fail to read arc yaml file")
-			return
-		}
-		arc := &model.ArcType{}
-		err = yaml.Unmarshal(arcFile, arc)
+		arc, err := loadArc(arcFilename)
 		if err != nil {
-			fmt.Println("fail to parse yaml content", err)
+			fmt.Println(err)
 			return
 		}
 		targets := make([]string, 0)
 		var pers model.PresentationPerspective = model.PresentationPerspective_LANDSCAPE
 		if len(args) > 0 {
-			switch args[0] {
-			case "context":
-				pers = model.PresentationPerspective_CONTEXT
-			case "container":
-				pers = model.PresentationPerspective_CONTAINER
-			case "component":
-				pers = model.PresentationPerspective_COMPONENT
-			case "code":
-				log.Println("Not supported for now. Make simple readable code")
-				return
-			case "landscape":
-				pers = model.PresentationPerspective_LANDSCAPE
-			default:
-				log.Printf("Perspective %s not supported, please indicate one of: landscape, context, container, component", args[0])
+			pers, err = parsePerspective(args[0])
+			if err != nil {
+				log.Println(err)
 				return
 			}
 		}
 		if len(args) > 1 {
 			targets = args[1:]
 		}
-		data, err := arc.Encode()
+		vizform, err := parseVisualFormat(outFormat)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		req, err := newRequest(arc, pers, vizform, targets...)
 		if err != nil {
-			fmt.Println("Fail to encode data")
+			log.Println(err)
 			return
 		}
-		opts := grpc.WithInsecure()
-		conn, err := grpc.Dial(vizAddress, opts)
+		relationLevel, err := parseRelationLevel(inspectRelationLevel)
 		if err != nil {
-			log.Printf("Unable to contact arc-viz server at %s with error: %+v", vizAddress, err)
+			log.Println(err)
 			return
 		}
-		defer conn.Close()
-
-		client := model.NewArcVizClient(conn)
-
-		var vizform model.ArcVisualFormat
-		switch outFormat {
-		case "svg":
-			vizform = model.ArcVisualFormat_SVG
-		case "png":
-			vizform = model.ArcVisualFormat_PNG
-		default:
-			log.Printf("Visual form not supported")
+		rollUp, err := parseRollUp(inspectRollUp)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		req.RollUp = rollUp
+		req.Exclude, req.HideUsers, req.HideExternalSystems, req.RelationLevel = inspectExclude, inspectHideUsers, inspectHideExternal, relationLevel
+		if inspectFocus != "" {
+			if len(args) > 0 {
+				log.Println("A focus view takes no perspective nor targets")
+				return
+			}
+			direction, err := parseFocusDirection(inspectDirection)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			req.Focus, req.Depth, req.Direction = inspectFocus, int32(inspectDepth), direction
+		}
+		if inspectASCII {
+			charset, err := parseCharset(inspectCharset)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			g, err := analyseRequest(req)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			diagram, err := ascii.Generate(g, charset)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Print(diagram)
+			return
+		}
+		client, err := dialViz(vizAddress)
+		if err != nil {
+			log.Println(err)
 			return
 		}
+		defer client.close()
 
-		pngViz, err := client.Render(context.Background(), &model.RenderRequest{
-			VisualFormat: vizform,
-			DataFormat:   model.ArcDataFormat_ARC,
-			Data:         data,
-			Target:       targets,
-			Perspective:  pers,
-		})
+		uri, err := client.renderRequest(req)
 		if err != nil {
-			log.Printf("Fail to render with error: %+v", err)
+			log.Println(err)
 			return
 		}
-		// png := base64.StdEncoding.EncodeToString(pngViz.GetData())
-
-		// htm := fmt.Sprintf("<img src=\"data:image/png;base64,%s\" />", png)
-		// err = ioutil.WriteFile("./index.html", []byte(htm), 0644)
-		// if err != nil {
-		// 	log.Printf("Fail to write output %s", err)
-		// 	return
-		// }
-		// http.Handle("/", http.FileServer(http.Dir("./")))
-		uri := string(pngViz.GetData())
 		fmt.Println(uri)
 		open(uri)
-		// panic(http.ListenAndServe(":10001", nil))
 	},
 }
 
@@ -168,5 +203,15 @@ func init() {
 	inspectCmd.PersistentFlags().StringVar(&vizAddress, "viz", "localhost:10000", "URI of an acrviz app")
 	inspectCmd.PersistentFlags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to inspect")
 	inspectCmd.PersistentFlags().StringVarP(&outFormat, "outform", "o", defaultOutForm, "Output format (png | svg)")
+	inspectCmd.Flags().BoolVar(&inspectASCII, "ascii", false, "Draw the perspective in the terminal instead of opening it in the browser")
+	inspectCmd.Flags().StringVar(&inspectCharset, "charset", "unicode", "Characters of the terminal drawing (unicode | ascii)")
+	inspectCmd.Flags().StringVar(&inspectFocus, "focus", "", "Path of an element to draw with its neighbours instead of a perspective")
+	inspectCmd.Flags().IntVar(&inspectDepth, "depth", 1, "Number of hops from the focus to its drawn neighbours")
+	inspectCmd.Flags().StringVar(&inspectDirection, "direction", "both", "Relations followed from the focus (both | out | in)")
+	inspectCmd.Flags().StringSliceVar(&inspectExclude, "exclude", nil, "Paths or globs of the elements to leave out with their children")
+	inspectCmd.Flags().BoolVar(&inspectHideUsers, "hide-users", false, "Leave out all users")
+	inspectCmd.Flags().BoolVar(&inspectHideExternal, "hide-external", false, "Leave out all external systems")
+	inspectCmd.Flags().StringVar(&inspectRollUp, "roll-up", "full", "Relations rolled up between the parents of their elements (full | container | none)")
+	inspectCmd.Flags().StringVar(&inspectRelationLevel, "relation-level", "all", "Most detailed level of the elements whose relations are shown (all | system | container)")
 
 }
@@ -0,0 +1,59 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/koderizer/arc/cli/lsp"
+	"github.com/koderizer/arc/model"
+	"github.com/spf13/cobra"
+)
+
+// lspCmd represents the lsp command
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server for arc yaml files",
+	Long: `
+Run a language server for arc yaml files, speaking the Language Server Protocol over the standard input and output.
+
+The server reports the errors of the open arc files, completes element paths in the s and o of relations,
+go to the definition of elements, find their references, rename them across all relations and describe them on hover.
+Elements defined in included files, or in the other files of the workspace given with -w, are known to the server.
+
+Configure the editor to start "arcli lsp" for yaml files named arc.yaml, eg with Neovim:
+
+	vim.lsp.start({ name = "arcli", cmd = { "arcli", "lsp" } })`,
+	Run: func(cmd *cobra.Command, args []string) {
+		server := lsp.NewServer(os.Stdin, os.Stdout)
+		server.Load = func(filename string) (*model.ArcType, error) {
+			loader := newArcLoader()
+			if workspaceRoot != "" {
+				return loader.loadWorkspace(workspaceRoot)
+			}
+			return loader.load(filename)
+		}
+		if err := server.Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
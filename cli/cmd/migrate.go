@@ -0,0 +1,237 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var migrateDryRun bool
+
+//migrations upgrade an arc yaml document by one version, migrations[i] upgrading version i+1 to i+2.
+//Files without version are of version 1. Migrations edit the text of the nodes they change only, keeping the rest of the file as is.
+var migrations = []func(d *arcDocument){
+	migrateV1,
+}
+
+//pointerTech match the technology embedded in a relation pointer of version 1, eg "send request (gRPC)"
+var pointerTech = regexp.MustCompile(`\((.*?)\)`)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [arc files]",
+	Short: "Upgrade arc yaml files to the latest version of the arc format",
+	Long: `
+Rewrite the given arc files, or the one given with -f, in the latest version of the arc format, only rewriting the migrated keys and values so their layout and comments are kept.
+
+Older files are still accepted by all commands, which migrate them in memory, so migrating is only needed to use the latest syntax.
+Version 2 changes:
+ - components use code instead of code-path
+ - relations give their technology with tech instead of within parentheses in the pointer, eg { s: a, p: call, tech: gRPC, o: b }
+
+Eg:
+	arcli migrate
+	arcli migrate --dry-run services/*/arc.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := args
+		if len(files) == 0 {
+			files = []string{arcFilename}
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			migrated, from, err := migrateArc(content)
+			if err != nil {
+				fmt.Printf("fail to migrate %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			if from == model.Version {
+				fmt.Printf("%s is up to date\n", file)
+				continue
+			}
+			if migrateDryRun {
+				fmt.Printf("# %s migrated from version %d to %d\n%s", file, from, model.Version, migrated)
+				continue
+			}
+			if err := ioutil.WriteFile(file, migrated, 0644); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("Migrated %s from version %d to %d\n", file, from, model.Version)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to migrate")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the migrated files instead of writing them")
+}
+
+//migrateArc upgrade the content of an arc yaml file to the latest version and return the version it was of.
+//Content already at the latest version is returned untouched.
+func migrateArc(content []byte) ([]byte, int, error) {
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(content, doc); err != nil {
+		return nil, 0, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return content, model.Version, nil
+	}
+	root := doc.Content[0]
+	version := 1
+	if v := mappingValue(root, "version"); v != nil {
+		n, err := strconv.Atoi(v.Value)
+		if err != nil || n < 1 {
+			return nil, 0, fmt.Errorf("invalid version %s", v.Value)
+		}
+		version = n
+	}
+	if version > model.Version {
+		return nil, version, fmt.Errorf("version %d is newer than the supported version %d, please upgrade arcli", version, model.Version)
+	}
+	if version == model.Version {
+		return content, version, nil
+	}
+	for v := version; v < model.Version; v++ {
+		if v > version {
+			doc = &yamlv3.Node{}
+			if err := yamlv3.Unmarshal(content, doc); err != nil {
+				return nil, version, err
+			}
+		}
+		d := newArcDocument("", content, doc.Content[0])
+		migrations[v-1](d)
+		setVersion(d, v+1)
+		content = d.edited()
+	}
+	return content, version, nil
+}
+
+//migrateV1 rename the code-path of components to code and move the technology of relation pointers to tech
+func migrateV1(d *arcDocument) {
+	root := d.root
+	containers := make([]*yamlv3.Node, 0)
+	for _, key := range []string{"internal-systems", "external-systems"} {
+		for _, sys := range sequenceItems(mappingValue(root, key)) {
+			containers = append(containers, sequenceItems(mappingValue(sys, "containers"))...)
+		}
+	}
+	containers = append(containers, sequenceItems(mappingValue(root, "containers"))...)
+	if c := mappingValue(root, "container"); c != nil {
+		containers = append(containers, c)
+	}
+	for _, c := range containers {
+		for _, com := range sequenceItems(mappingValue(c, "components")) {
+			if mappingValue(com, "code") == nil {
+				if key := mappingKey(com, "code-path"); key != nil {
+					d.setScalar(key, "code")
+				}
+			}
+		}
+	}
+	for _, rel := range sequenceItems(mappingValue(root, "relations")) {
+		p := mappingValue(rel, "p")
+		if p == nil || p.Kind != yamlv3.ScalarNode || mappingValue(rel, "tech") != nil {
+			continue
+		}
+		tech := pointerTech.FindStringSubmatch(p.Value)
+		if tech == nil {
+			continue
+		}
+		d.setScalar(p, strings.TrimSpace(pointerTech.ReplaceAllString(p.Value, "")))
+		_, end := d.scalarRange(p)
+		flow := rel.Style&yamlv3.FlowStyle != 0
+		text := ", tech: " + plainScalar(tech[1], flow)
+		if !flow {
+			text = "\n" + strings.Repeat(" ", mappingKey(rel, "p").Column-1) + "tech: " + plainScalar(tech[1], flow)
+		}
+		d.edits = append(d.edits, textEdit{start: end, end: end, text: text})
+	}
+}
+
+//plainScalar write a value as a yaml scalar, quoted when it would not read back as the same string
+func plainScalar(value string, flow bool) string {
+	out, err := yamlv3.Marshal(value)
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	scalar := strings.TrimSuffix(string(out), "\n")
+	if strings.Contains(scalar, "\n") || (flow && strings.ContainsAny(value, ",[]{}:#")) {
+		return strconv.Quote(value)
+	}
+	return scalar
+}
+
+//setVersion set the version key of the document, adding it before the first key when missing
+func setVersion(d *arcDocument, version int) {
+	value := strconv.Itoa(version)
+	if v := mappingValue(d.root, "version"); v != nil {
+		d.setScalar(v, value)
+		return
+	}
+	if d.root.Style&yamlv3.FlowStyle != 0 || len(d.root.Content) == 0 {
+		return
+	}
+	at := d.lineStarts[d.root.Content[0].Line-1]
+	d.edits = append(d.edits, textEdit{start: at, end: at, text: "version: " + value + "\n"})
+}
+
+//mappingKey return the key node of the given key in a mapping node
+func mappingKey(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+//mappingValue return the value node of the given key in a mapping node
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+//sequenceItems return the items of a sequence node
+func sequenceItems(node *yamlv3.Node) []*yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
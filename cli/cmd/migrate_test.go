@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+)
+
+const arcV1 = `app: shop
+# the systems
+internal-systems:
+  - name: web
+    containers:
+      - name: api
+        components:
+          - name: cart
+            code-path: internal/cart # the basket
+relations:
+  - {s: web.api, p: "charge (https)", o: payments}
+  - {s: web.api, p: "notify (smtp)", o: mail, tech: email}
+  - {s: web.api, p: log, o: mail}
+`
+
+const arcV1Migrated = `version: 2
+app: shop
+# the systems
+internal-systems:
+  - name: web
+    containers:
+      - name: api
+        components:
+          - name: cart
+            code: internal/cart # the basket
+relations:
+  - {s: web.api, p: "charge", tech: https, o: payments}
+  - {s: web.api, p: "notify (smtp)", o: mail, tech: email}
+  - {s: web.api, p: log, o: mail}
+`
+
+func TestMigrateArc(t *testing.T) {
+	tests := []struct {
+		content, migrated string
+		from              int
+	}{
+		{arcV1, arcV1Migrated, 1},
+		//fragments are migrated the same way
+		{"system: web\ncontainer:\n  name: api\n  components:\n    - {name: cart, code-path: cart}\n",
+			"version: 2\nsystem: web\ncontainer:\n  name: api\n  components:\n    - {name: cart, code: cart}\n", 1},
+		//the version is updated in place, keeping the order of the keys
+		{"app: shop\nversion: 1\nrelations:\n  - {s: a, p: call (grpc), o: b}\n",
+			"app: shop\nversion: 2\nrelations:\n  - {s: a, p: call, tech: grpc, o: b}\n", 1},
+		//files of the latest version are left untouched
+		{"version: 2\napp: shop\nrelations:\n  - {s: a,   p: call (grpc), o: b}\n",
+			"version: 2\napp: shop\nrelations:\n  - {s: a,   p: call (grpc), o: b}\n", 2},
+		//the untouched nodes keep their layout: block scalars with trailing spaces, blank lines, spacing in flow mappings
+		{"app: shop\ndesc: |\n  An online shop   \n  selling things\n\n\nrelations:\n  - s: a\n    p: call (grpc/v2) # sync\n    o: b\n\n  - {s: a,  p: \"send (smtp, tls)\", o: b}\n",
+			"version: 2\napp: shop\ndesc: |\n  An online shop   \n  selling things\n\n\nrelations:\n  - s: a\n    p: call\n    tech: grpc/v2 # sync\n    o: b\n\n  - {s: a,  p: \"send\", tech: \"smtp, tls\", o: b}\n", 1},
+	}
+	for i, test := range tests {
+		migrated, from, err := migrateArc([]byte(test.content))
+		if err != nil {
+			t.Errorf("Test %d: %v", i, err)
+			continue
+		}
+		if from != test.from || string(migrated) != test.migrated {
+			t.Errorf("Test %d: expect from version %d\n%s\nget from version %d\n%s", i, test.from, test.migrated, from, migrated)
+		}
+	}
+	for _, content := range []string{"version: 3\napp: shop\n", "version: zero\napp: shop\n"} {
+		if _, _, err := migrateArc([]byte(content)); err == nil {
+			t.Errorf("expect %q to be rejected", content)
+		}
+	}
+}
+
+func TestLoadMigratedArc(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"arc.yaml": arcV1 + "external-systems:\n  - name: payments\n  - name: mail\n"})
+	arc, err := newArcLoader().load(filepath.Join(dir, "arc.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := arc.InternalSystems[0].Containers[0].Components[0].Code; code != "internal/cart" {
+		t.Errorf("expect the code-path of a version 1 file to be read as code, get %q", code)
+	}
+	expect := []model.Relation{
+		{Subject: "web.api", Pointer: "charge", Technology: "https", Object: "payments"},
+		{Subject: "web.api", Pointer: "notify (smtp)", Technology: "email", Object: "mail"},
+		{Subject: "web.api", Pointer: "log", Object: "mail"},
+	}
+	if !reflect.DeepEqual(arc.Relations, expect) {
+		t.Errorf("expect relations %+v, get %+v", expect, arc.Relations)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+	"github.com/koderizer/arc/viz/ascii"
+	"github.com/spf13/cobra"
+)
+
+var pathAll bool
+var pathMaxHops int
+var pathLimit int
+var pathDiagram bool
+var pathCharset string
+
+// pathCmd represents the path command
+var pathCmd = &cobra.Command{
+	Use:   "path <from> <to>",
+	Short: "Show how an element reaches another through the relations of an architecture",
+	Long: `
+Print the shortest path through declared relations from an element to another, or to one of its children,
+with the relations of each hop. An element reached by a relation carries on through its own relations, and through
+the relations of the elements it is part of or made of: a call to a component carries on with the relations of its
+container, and the relations of a system are the ones of its containers. The hops going through the relations of
+such an element are labelled with the subject of the relation.
+
+With --all, all the paths visiting no element twice are printed, fewest hops first, up to --limit paths of at most
+--max-hops hops. With --diagram, the shortest path is drawn as boxes and arrows instead.
+
+Eg:
+	arcli path dev arc-intel.db
+	arcli path dev arc-intel.db --all --limit 5
+	arcli path dev arc-intel.db --diagram`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		g, err := analyse(arc, model.PresentationPerspective_LANDSCAPE)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		from, to := args[0], args[1]
+		if pathAll && !pathDiagram {
+			paths, err := g.AllPaths(from, to, pathMaxHops, pathLimit)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if len(paths) == 0 {
+				fmt.Printf("No path of at most %d hops from %s to %s\n", pathMaxHops, from, to)
+				os.Exit(1)
+			}
+			fmt.Printf("%d path(s) from %s to %s:\n", len(paths), from, to)
+			for i, p := range paths {
+				fmt.Printf("\n%d. %d hop(s)\n", i+1, len(p))
+				writePath(os.Stdout, p)
+			}
+			return
+		}
+		p, err := g.ShortestPath(from, to)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if p == nil {
+			fmt.Printf("No path from %s to %s\n", from, to)
+			os.Exit(1)
+		}
+		if len(p) == 0 {
+			fmt.Printf("%s is part of %s\n", from, to)
+			return
+		}
+		if pathDiagram {
+			charset, err := parseCharset(pathCharset)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			diagram, err := ascii.GeneratePath(g, p, charset)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Print(diagram)
+			return
+		}
+		fmt.Printf("Shortest path from %s to %s, %d hop(s):\n", from, to, len(p))
+		writePath(os.Stdout, p)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pathCmd)
+	pathCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to search paths in")
+	pathCmd.Flags().BoolVar(&pathAll, "all", false, "Print all the paths visiting no element twice, fewest hops first")
+	pathCmd.Flags().IntVar(&pathMaxHops, "max-hops", 6, "Maximum number of hops of the paths printed with --all")
+	pathCmd.Flags().IntVar(&pathLimit, "limit", 10, "Maximum number of paths printed with --all")
+	pathCmd.Flags().BoolVar(&pathDiagram, "diagram", false, "Draw the shortest path in the terminal")
+	pathCmd.Flags().StringVar(&pathCharset, "charset", "unicode", "Characters of the drawing (unicode | ascii)")
+}
+
+//writePath write the elements of a path one per line, each with the relations leading to it
+func writePath(w io.Writer, p analyzer.Path) {
+	fmt.Fprintf(w, "  %s\n", p[0].From)
+	for _, hop := range p {
+		labels := make([]string, 0, len(hop.Relations))
+		for _, rel := range hop.Relations {
+			label := rel.Pointer
+			if rel.Technology != "" {
+				label += " [" + rel.Technology + "]"
+			}
+			if rel.Subject != hop.From {
+				label = rel.Subject + ": " + label
+			}
+			labels = append(labels, label)
+		}
+		fmt.Fprintf(w, "    └ %s ▶ %s\n", strings.Join(labels, ", "), hop.To)
+	}
+}
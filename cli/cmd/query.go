@@ -0,0 +1,130 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var queryFormat string
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query <expression>",
+	Short: "Query the elements and relations of an architecture",
+	Long: `
+Query the elements and relations of the arc file given with -f, or of the workspace given with -w, with an expression:
+
+  <elements> [without relations | with relations] [where <conditions>]
+      elements is one of elements, users, systems, external-systems, containers, external-containers, components
+      fields are name, path, kind, technology (tech), desc, role and tag
+  relations [from <pattern>] [to <pattern>] [where <conditions>]
+      patterns match element paths, * standing for any characters, eg arc.*
+      fields are subject (s), pointer (p), technology (tech), object (o) and tag
+  dependents(<path>[, depth=<n>]) [where <conditions>]
+  dependencies(<path>[, depth=<n>]) [where <conditions>]
+      the elements relating to the element, or the element relates to, up to n hops, 1 by default
+
+Conditions compare a field to a value, quoted if it holds spaces, with = and !=, or to a regular expression with ~ and !~,
+and are joined with and. A tag condition holds when one of the tags match.
+
+Eg:
+	arcli query 'containers where technology ~ "golang"'
+	arcli query 'relations from arc.* to arc-intel.*' --format json
+	arcli query 'dependents(arc-intel.db, depth=2)'
+	arcli query components without relations`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		g, err := analyse(arc, model.PresentationPerspective_LANDSCAPE)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		result, err := g.Query(strings.Join(args, " "))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := writeQueryResult(os.Stdout, result, queryFormat); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to query")
+	queryCmd.Flags().StringVar(&queryFormat, "format", "table", "Format of the result (table | json | yaml)")
+}
+
+//writeQueryResult write the result of a query as a table, or as a list of records in json or yaml
+func writeQueryResult(w io.Writer, result *analyzer.QueryResult, format string) error {
+	columns, rows := result.Table()
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case "json":
+		records := make([]map[string]string, 0, len(rows))
+		for _, row := range rows {
+			record := make(map[string]string, len(columns))
+			for i, c := range columns {
+				record[c] = row[i]
+			}
+			records = append(records, record)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "yaml":
+		records := make([]yaml.MapSlice, 0, len(rows))
+		for _, row := range rows {
+			record := make(yaml.MapSlice, 0, len(columns))
+			for i, c := range columns {
+				record = append(record, yaml.MapItem{Key: c, Value: row[i]})
+			}
+			records = append(records, record)
+		}
+		out, err := yaml.Marshal(records)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return fmt.Errorf("Format %s not supported, please indicate one of: table, json, yaml", format)
+	}
+}
@@ -0,0 +1,711 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/koderizer/arc/model"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var refactorDryRun bool
+var refactorDocs []string
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv <path> <new path>",
+	Short: "Rename or move an element, updating all references to it",
+	Long: `
+Rename an element, or move a container to another system or a component to another container,
+and update the relations, groups and the view markers of the documents given with --docs that reference it or its children.
+
+The arc file given with -f and the local files it includes, or all arc files of the workspace given with -w, are updated, keeping their comments.
+With --dry-run, the changes are printed as a diff instead of written.
+
+Eg:
+	arcli mv arc.arcli arc.cli
+	arcli mv arc-intel.api.inspector arc-intel.index.inspector --dry-run
+	arcli mv arc.gui arc.web --docs README.md`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := newRefactoring()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := r.move(args[0], args[1]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := r.save(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// rmCmd represents the rm command
+var rmCmd = &cobra.Command{
+	Use:   "rm <path>",
+	Short: "Remove an element with its children and the relations to them",
+	Long: `
+Remove an element and its children, along with the relations referencing them, the element from groups,
+and the element from the targets of the view markers of the documents given with --docs.
+
+The arc file given with -f and the local files it includes, or all arc files of the workspace given with -w, are updated, keeping their comments.
+With --dry-run, the changes are printed as a diff instead of written.
+
+Eg:
+	arcli rm arc.gui --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := newRefactoring()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := r.remove(args[0]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := r.save(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{mvCmd, rmCmd} {
+		rootCmd.AddCommand(cmd)
+		cmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to update")
+		cmd.Flags().BoolVar(&refactorDryRun, "dry-run", false, "Print the changes as a diff instead of writing them")
+		cmd.Flags().StringSliceVar(&refactorDocs, "docs", nil, "Markdown documents whose view markers are updated")
+	}
+}
+
+//arcDocument is an arc file being refactored with text edits, so the rest of the file is kept as is
+type arcDocument struct {
+	file       string
+	content    []byte
+	root       *yamlv3.Node
+	lineStarts []int
+	edits      []textEdit
+}
+
+//textEdit replace the content between two offsets of a document
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+//located is an element found in an arc document
+type located struct {
+	doc *arcDocument
+	//node is the mapping node of the element
+	node *yamlv3.Node
+	//name is the scalar node naming the element
+	name *yamlv3.Node
+	//line is the first line of the element, from 0, or -1 when the element is the system of a fragment
+	line int
+	//single tell whether the element is the single container of a fragment rather than an item of a list
+	single bool
+}
+
+//refactoring edit the elements of the loaded arc files and the references to them
+type refactoring struct {
+	kinds map[string]string
+	docs  []*arcDocument
+	//markdown are the contents of the documents given with --docs, by file
+	markdown map[string][]byte
+	original map[string][]byte
+}
+
+//newRefactoring load the arc file with the local files it includes, or all arc files of the workspace, and the documents given with --docs
+func newRefactoring() (*refactoring, error) {
+	loader := newArcLoader()
+	var arc *model.ArcType
+	var err error
+	if workspaceRoot != "" {
+		arc, err = loader.loadWorkspace(workspaceRoot)
+	} else {
+		arc, err = loader.load(arcFilename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := loader.finish(arc); err != nil {
+		return nil, err
+	}
+	r := &refactoring{kinds: arcElements(arc), markdown: make(map[string][]byte), original: make(map[string][]byte)}
+	files := make([]string, 0, len(loader.loaded))
+	for file := range loader.loaded {
+		//files of git includes belong to other repositories
+		if !strings.HasPrefix(file, loader.cacheDir+string(filepath.Separator)) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		doc := &yamlv3.Node{}
+		if err := yamlv3.Unmarshal(content, doc); err != nil {
+			return nil, fmt.Errorf("fail to parse yaml content of %s: %v", loader.rel(file), err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+			continue
+		}
+		r.docs = append(r.docs, newArcDocument(loader.rel(file), content, doc.Content[0]))
+	}
+	for _, file := range refactorDocs {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		r.markdown[file] = content
+		r.original[file] = content
+	}
+	return r, nil
+}
+
+func newArcDocument(file string, content []byte, root *yamlv3.Node) *arcDocument {
+	d := &arcDocument{file: file, content: content, root: root, lineStarts: []int{0}}
+	for i, b := range content {
+		if b == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	return d
+}
+
+//move rename the element of the path, or move it under another parent, and update the references to it
+func (r *refactoring) move(from, to string) error {
+	kind := r.kinds[from]
+	if kind == "" {
+		return fmt.Errorf("unknown element %s", from)
+	}
+	if r.kinds[to] != "" {
+		return fmt.Errorf("%s already exist", to)
+	}
+	fromParent, _ := splitPath(from)
+	toParent, name := splitPath(to)
+	if name == "" || strings.TrimSpace(name) != name {
+		return fmt.Errorf("invalid element name %q", name)
+	}
+	if toParent != fromParent {
+		if fromParent == "" || toParent == "" {
+			return fmt.Errorf("%s can only be renamed, not moved under another element", kind)
+		}
+		if r.kinds[toParent] != r.kinds[fromParent] {
+			return fmt.Errorf("%s must be moved under a %s, %s is not", kind, r.kinds[fromParent], toParent)
+		}
+	}
+
+	locations := r.locate(from)
+	if len(locations) == 0 {
+		return fmt.Errorf("%s is not defined in a local arc file", from)
+	}
+	if toParent == fromParent {
+		for _, l := range locations {
+			l.doc.setScalar(l.name, name)
+		}
+	} else {
+		if len(locations) > 1 {
+			return fmt.Errorf("%s is defined in several files, it can only be renamed", from)
+		}
+		if err := r.moveBlock(locations[0], from, toParent, name); err != nil {
+			return err
+		}
+	}
+
+	rename := func(path string) (string, bool) {
+		if path == from || strings.HasPrefix(path, from+".") {
+			return to + strings.TrimPrefix(path, from), true
+		}
+		return path, false
+	}
+	for _, doc := range r.docs {
+		for _, ref := range references(doc.root) {
+			if renamed, ok := rename(ref.Value); ok {
+				doc.setScalar(ref, renamed)
+			}
+		}
+	}
+	r.updateViews(func(targets []string) []string {
+		for i, t := range targets {
+			targets[i], _ = rename(t)
+		}
+		return targets
+	})
+	return nil
+}
+
+//moveBlock cut the text of an element, renamed, and paste it at the end of the children of the new parent
+func (r *refactoring) moveBlock(l located, from, parent, name string) error {
+	if l.single || l.line < 0 {
+		return fmt.Errorf("the single container of the fragment %s can only be renamed", l.doc.file)
+	}
+	targets := r.locate(parent)
+	if len(targets) == 0 {
+		return fmt.Errorf("%s is not defined in a local arc file", parent)
+	}
+	target := targets[0]
+	children := childrenKey(r.kinds[parent])
+
+	src := l.doc
+	start, end := src.lineStarts[l.line], src.blockEnd(l.line)
+	nameStart, nameEnd := src.scalarRange(l.name)
+	block := string(src.content[start:nameStart]) + quoteScalar(l.name, name) + string(src.content[nameEnd:end])
+	if !strings.HasSuffix(block, "\n") {
+		block += "\n"
+	}
+	r.removeElement(l, from)
+
+	dst := target.doc
+	var at int
+	var text string
+	seq := mappingValue(target.node, children)
+	switch {
+	case seq == nil:
+		indent := 0
+		at = len(dst.content)
+		if target.node != dst.root {
+			indent = target.node.Column - 1
+			at = dst.blockEnd(target.line)
+		}
+		text = strings.Repeat(" ", indent) + children + ":\n" + reindent(block, indent+2)
+	case seq.Kind == yamlv3.SequenceNode && len(seq.Content) > 0 && seq.Style&yamlv3.FlowStyle == 0:
+		last := seq.Content[len(seq.Content)-1].Line - 1
+		at = dst.blockEnd(last)
+		text = reindent(block, indentOf(dst.line(last)))
+	default:
+		return fmt.Errorf("the %s of %s in %s must be a list of elements", children, parent, dst.file)
+	}
+	if at == len(dst.content) && at > 0 && dst.content[at-1] != '\n' {
+		text = "\n" + text
+	}
+	dst.edits = append(dst.edits, textEdit{start: at, end: at, text: text})
+	return nil
+}
+
+//remove remove the element of the path with its children, and the relations and references to them
+func (r *refactoring) remove(path string) error {
+	if r.kinds[path] == "" {
+		return fmt.Errorf("unknown element %s", path)
+	}
+	locations := r.locate(path)
+	if len(locations) == 0 {
+		return fmt.Errorf("%s is not defined in a local arc file", path)
+	}
+	for _, l := range locations {
+		if l.line < 0 {
+			return fmt.Errorf("%s is the system of the fragment %s, remove the file instead", path, l.doc.file)
+		}
+		r.removeElement(l, path)
+	}
+	removed := func(ref string) bool {
+		return ref == path || strings.HasPrefix(ref, path+".")
+	}
+	for _, doc := range r.docs {
+		for _, rel := range sequenceItems(mappingValue(doc.root, "relations")) {
+			if s, o := mappingValue(rel, "s"), mappingValue(rel, "o"); (s != nil && removed(s.Value)) || (o != nil && removed(o.Value)) {
+				doc.removeBlock(rel.Line - 1)
+			}
+		}
+		var groups func(nodes []*yamlv3.Node)
+		groups = func(nodes []*yamlv3.Node) {
+			for _, g := range nodes {
+				systems := mappingValue(g, "systems")
+				if systems != nil && systems.Style&yamlv3.FlowStyle != 0 {
+					kept := make([]string, 0)
+					for _, sys := range systems.Content {
+						if !removed(sys.Value) {
+							kept = append(kept, quoteScalar(sys, sys.Value))
+						}
+					}
+					if len(kept) != len(systems.Content) {
+						start := doc.offset(systems.Line, systems.Column)
+						end := start + bytes.IndexByte(doc.content[start:], ']') + 1
+						doc.edits = append(doc.edits, textEdit{start: start, end: end, text: "[" + strings.Join(kept, ", ") + "]"})
+					}
+				} else {
+					for _, sys := range sequenceItems(systems) {
+						if removed(sys.Value) {
+							doc.removeBlock(sys.Line - 1)
+						}
+					}
+				}
+				groups(sequenceItems(mappingValue(g, "groups")))
+			}
+		}
+		groups(sequenceItems(mappingValue(doc.root, "groups")))
+	}
+	r.updateViews(func(targets []string) []string {
+		kept := make([]string, 0, len(targets))
+		for _, t := range targets {
+			if !removed(t) {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	})
+	return nil
+}
+
+//removeElement remove the block of an element, along with the key of its list when it is the last item of it,
+//so that no empty list is left behind
+func (r *refactoring) removeElement(l located, path string) {
+	d := l.doc
+	start, end := d.lineStarts[l.line], d.blockEnd(l.line)
+	if l.single {
+		d.edits = append(d.edits, textEdit{start: start, end: end})
+		return
+	}
+	parent, _ := splitPath(path)
+	var node *yamlv3.Node
+	var key string
+	if parent == "" {
+		node = d.root
+		key = map[string]string{"user": "users", "internal system": "internal-systems", "external system": "external-systems"}[r.kinds[path]]
+	} else {
+		key = childrenKey(r.kinds[parent])
+		for _, p := range r.locate(parent) {
+			if p.doc == d {
+				node = p.node
+			}
+		}
+	}
+	seq := mappingValue(node, key)
+	if seq != nil && seq.Style&yamlv3.FlowStyle == 0 && len(seq.Content) == 1 && seq.Content[0] == l.node {
+		keyLine := mappingKey(node, key).Line - 1
+		start = d.lineStarts[keyLine]
+		if keyEnd := d.blockEnd(keyLine); keyEnd > end {
+			end = keyEnd
+		}
+	}
+	d.edits = append(d.edits, textEdit{start: start, end: end})
+}
+
+//locate find the definitions of the element of the path in the arc documents
+func (r *refactoring) locate(path string) []located {
+	locations := make([]located, 0)
+	segments := strings.Split(path, ".")
+	for _, doc := range r.docs {
+		//systems are defined in full arc files, or by fragments
+		parents := make([]located, 0)
+		for _, key := range []string{"users", "internal-systems", "external-systems"} {
+			if l, ok := findItem(doc, mappingValue(doc.root, key), segments[0]); ok {
+				parents = append(parents, l)
+			}
+		}
+		if system := mappingValue(doc.root, "system"); system != nil && system.Value == segments[0] {
+			parents = append(parents, located{doc: doc, node: doc.root, name: system, line: -1})
+		}
+		if mappingValue(doc.root, "system") == nil && len(segments) > 1 && r.kinds[segments[0]] == "internal system" {
+			//the containers of a fragment without system belong to the single system owning a container of the same name
+			parents = append(parents, located{doc: doc, node: doc.root, line: -1})
+		}
+		for _, l := range parents {
+			for depth, segment := range segments[1:] {
+				seq := mappingValue(l.node, childrenKey(r.kinds[strings.Join(segments[:depth+1], ".")]))
+				found, ok := findItem(doc, seq, segment)
+				if !ok && depth == 0 && l.node == doc.root {
+					found, ok = findSingle(doc, segment)
+				}
+				if !ok {
+					l = located{}
+					break
+				}
+				l = found
+			}
+			if l.name != nil {
+				locations = append(locations, l)
+			}
+		}
+	}
+	return locations
+}
+
+//findItem find the item of the given name in a list of elements
+func findItem(doc *arcDocument, seq *yamlv3.Node, name string) (located, bool) {
+	for _, item := range sequenceItems(seq) {
+		if n := mappingValue(item, "name"); n != nil && n.Value == name {
+			return located{doc: doc, node: item, name: n, line: item.Line - 1}, true
+		}
+	}
+	return located{}, false
+}
+
+//findSingle find the single container of a fragment, given with container instead of containers
+func findSingle(doc *arcDocument, name string) (located, bool) {
+	key, c := mappingKey(doc.root, "container"), mappingValue(doc.root, "container")
+	n := mappingValue(c, "name")
+	if n == nil || n.Value != name {
+		return located{}, false
+	}
+	return located{doc: doc, node: c, name: n, line: key.Line - 1, single: true}, true
+}
+
+//childrenKey return the key of the children of an element of the given kind
+func childrenKey(kind string) string {
+	switch kind {
+	case "internal system", "external system":
+		return "containers"
+	default:
+		return "components"
+	}
+}
+
+//splitPath split an element path into the path of its parent and its name
+func splitPath(path string) (string, string) {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return "", path
+}
+
+//references return the scalar nodes referencing elements by path, in relations and groups
+func references(root *yamlv3.Node) []*yamlv3.Node {
+	refs := make([]*yamlv3.Node, 0)
+	for _, rel := range sequenceItems(mappingValue(root, "relations")) {
+		for _, key := range []string{"s", "o"} {
+			if n := mappingValue(rel, key); n != nil && n.Kind == yamlv3.ScalarNode {
+				refs = append(refs, n)
+			}
+		}
+	}
+	var groups func(nodes []*yamlv3.Node)
+	groups = func(nodes []*yamlv3.Node) {
+		for _, g := range nodes {
+			for _, sys := range sequenceItems(mappingValue(g, "systems")) {
+				if sys.Kind == yamlv3.ScalarNode {
+					refs = append(refs, sys)
+				}
+			}
+			groups(sequenceItems(mappingValue(g, "groups")))
+		}
+	}
+	groups(sequenceItems(mappingValue(root, "groups")))
+	return refs
+}
+
+//line return the text of a line of the document, from 0
+func (d *arcDocument) line(i int) string {
+	end := len(d.content)
+	if i+1 < len(d.lineStarts) {
+		end = d.lineStarts[i+1] - 1
+	}
+	return strings.TrimSuffix(string(d.content[d.lineStarts[i]:end]), "\r")
+}
+
+//offset return the offset of a line and column, from 1 as given by the yaml parser
+func (d *arcDocument) offset(line, column int) int {
+	offset := d.lineStarts[line-1]
+	for i := 1; i < column && offset < len(d.content); i++ {
+		_, size := utf8.DecodeRune(d.content[offset:])
+		offset += size
+	}
+	return offset
+}
+
+//scalarRange return the offsets of the text of a scalar, quotes included
+func (d *arcDocument) scalarRange(n *yamlv3.Node) (int, int) {
+	start := d.offset(n.Line, n.Column)
+	switch {
+	case n.Style&yamlv3.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(d.content); i++ {
+			if d.content[i] == '\\' {
+				i++
+			} else if d.content[i] == '"' {
+				return start, i + 1
+			}
+		}
+	case n.Style&yamlv3.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(d.content); i++ {
+			if d.content[i] == '\'' {
+				if i+1 < len(d.content) && d.content[i+1] == '\'' {
+					i++
+					continue
+				}
+				return start, i + 1
+			}
+		}
+	}
+	return start, start + len(n.Value)
+}
+
+//setScalar replace the value of a scalar, keeping its quotes
+func (d *arcDocument) setScalar(n *yamlv3.Node, value string) {
+	start, end := d.scalarRange(n)
+	d.edits = append(d.edits, textEdit{start: start, end: end, text: quoteScalar(n, value)})
+}
+
+//blockEnd return the offset following the block starting at the line, made of the more indented lines that follow it
+func (d *arcDocument) blockEnd(line int) int {
+	indent := indentOf(d.line(line))
+	last := line
+	for i := line + 1; i < len(d.lineStarts); i++ {
+		text := d.line(i)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if indentOf(text) <= indent {
+			break
+		}
+		last = i
+	}
+	if last+1 < len(d.lineStarts) {
+		return d.lineStarts[last+1]
+	}
+	return len(d.content)
+}
+
+//removeBlock remove the block starting at the line
+func (d *arcDocument) removeBlock(line int) {
+	d.edits = append(d.edits, textEdit{start: d.lineStarts[line], end: d.blockEnd(line)})
+}
+
+//edited return the content of the document with its edits applied
+func (d *arcDocument) edited() []byte {
+	edits := append([]textEdit{}, d.edits...)
+	//edits are applied from the end, a removal before an insertion at the same offset
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	content := append([]byte{}, d.content...)
+	for _, e := range edits {
+		content = append(content[:e.start], append([]byte(e.text), content[e.end:]...)...)
+	}
+	return content
+}
+
+//quoteScalar write a value in the quoting style of a scalar
+func quoteScalar(n *yamlv3.Node, value string) string {
+	switch {
+	case n.Style&yamlv3.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case n.Style&yamlv3.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	default:
+		return value
+	}
+}
+
+//reindent shift the lines of a block so its first line is indented by the given number of spaces
+func reindent(block string, indent int) string {
+	lines := strings.SplitAfter(block, "\n")
+	shift := indent - indentOf(lines[0])
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		switch {
+		case shift >= 0:
+			lines[i] = strings.Repeat(" ", shift) + line
+		case indentOf(line) < -shift:
+			lines[i] = strings.TrimLeft(line, " ")
+		default:
+			lines[i] = line[-shift:]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+//updateViews rewrite the targets of the view markers of the documents
+func (r *refactoring) updateViews(update func(targets []string) []string) {
+	for file, content := range r.markdown {
+		r.markdown[file] = replaceViews(content, func(block []byte) []byte {
+			m := viewMarker.FindSubmatchIndex(block)
+			args := strings.Fields(string(block[m[2]:m[3]]))
+			if len(args) == 0 {
+				return block
+			}
+			options, targets := make([]string, 0), make([]string, 0)
+			for _, arg := range args[1:] {
+				if strings.Contains(arg, "=") {
+					options = append(options, arg)
+				} else {
+					targets = append(targets, arg)
+				}
+			}
+			spec := append(append([]string{args[0]}, update(targets)...), options...)
+			return append(append(append([]byte{}, block[:m[2]]...), strings.Join(spec, " ")...), block[m[3]:]...)
+		})
+	}
+}
+
+//save write the changed files, or print their diff on a dry run
+func (r *refactoring) save() error {
+	changes := make(map[string][2][]byte)
+	for _, doc := range r.docs {
+		if len(doc.edits) > 0 {
+			changes[doc.file] = [2][]byte{doc.content, doc.edited()}
+		}
+	}
+	for file, content := range r.markdown {
+		if !bytes.Equal(content, r.original[file]) {
+			changes[file] = [2][]byte{r.original[file], content}
+		}
+	}
+	files := make([]string, 0, len(changes))
+	for file := range changes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		change := changes[file]
+		if refactorDryRun {
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(change[0])),
+				B:        difflib.SplitLines(string(change[1])),
+				FromFile: "a/" + filepath.ToSlash(file),
+				ToFile:   "b/" + filepath.ToSlash(file),
+				Context:  3,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Print(diff)
+			continue
+		}
+		if err := ioutil.WriteFile(file, change[1], 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s\n", file)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const refactorArc = `version: 2
+app: shop
+users:
+  - name: customer
+internal-systems:
+  # the shop front
+  - name: web
+    containers:
+      - name: api
+        technology: go
+        components:
+          - name: cart # the basket
+          - name: checkout
+      - name: ui
+  - name: legacy
+    containers:
+      - name: monolith
+groups:
+  - name: sales
+    systems: [web, legacy]
+relations:
+  - {s: customer, p: use, o: web.ui}
+  - {s: web.ui, p: call, o: web.api.cart}
+  - {s: web.api.checkout, p: "store orders", o: legacy.monolith}
+`
+
+const refactorDoc = `# Shop
+<!-- arc:view component web.api format=mermaid -->
+<!-- /arc:view -->
+`
+
+//refactorFiles write the arc file and document of a refactoring test and point the flags to them
+func refactorFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"arc.yaml": refactorArc, "README.md": refactorDoc})
+	arcFilename, workspaceRoot, refactorDryRun = filepath.Join(dir, "arc.yaml"), "", false
+	refactorDocs = []string{filepath.Join(dir, "README.md")}
+	return filepath.Join(dir, "arc.yaml"), filepath.Join(dir, "README.md")
+}
+
+//captureOutput return what the function print on the standard output
+func captureOutput(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	f()
+	w.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestRefactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		apply   func(r *refactoring) error
+		arc     []string
+		missing []string
+		doc     string
+	}{
+		{
+			name:  "rename a component",
+			apply: func(r *refactoring) error { return r.move("web.api.cart", "web.api.basket") },
+			arc:   []string{"- name: basket # the basket", "{s: web.ui, p: call, o: web.api.basket}"},
+			doc:   "component web.api format=mermaid",
+		},
+		{
+			name:  "move a component to another container",
+			apply: func(r *refactoring) error { return r.move("web.api.checkout", "web.ui.checkout") },
+			arc: []string{"      - name: ui\n        components:\n          - name: checkout\n",
+				"{s: web.ui.checkout, p: \"store orders\", o: legacy.monolith}"},
+			doc: "component web.api format=mermaid",
+		},
+		{
+			name:  "rename a container with its children and document targets",
+			apply: func(r *refactoring) error { return r.move("web.api", "web.backend") },
+			arc: []string{"      - name: backend\n        technology: go", "{s: web.ui, p: call, o: web.backend.cart}",
+				"{s: web.backend.checkout, p: \"store orders\", o: legacy.monolith}"},
+			doc: "component web.backend format=mermaid",
+		},
+		{
+			name:  "move the last container of a system out of it",
+			apply: func(r *refactoring) error { return r.move("legacy.monolith", "web.monolith") },
+			arc:   []string{"  - name: legacy\ngroups:", "      - name: ui\n      - name: monolith\n", "o: web.monolith}"},
+		},
+		{
+			name:  "rename a system in groups",
+			apply: func(r *refactoring) error { return r.move("legacy", "erp") },
+			arc:   []string{"systems: [web, erp]", "o: erp.monolith}"},
+		},
+		{
+			name:    "remove a container with the relations to its children",
+			apply:   func(r *refactoring) error { return r.remove("web.api") },
+			arc:     []string{"  - {s: customer, p: use, o: web.ui}\n"},
+			missing: []string{"name: api", "cart", "checkout", "store orders"},
+			doc:     "<!-- arc:view component format=mermaid -->",
+		},
+		{
+			name:    "remove a system from groups",
+			apply:   func(r *refactoring) error { return r.remove("legacy") },
+			arc:     []string{"systems: [web]"},
+			missing: []string{"legacy", "store orders"},
+		},
+		{
+			name:    "remove the last container of a system",
+			apply:   func(r *refactoring) error { return r.remove("legacy.monolith") },
+			arc:     []string{"  - name: legacy\ngroups:"},
+			missing: []string{"monolith", "store orders"},
+		},
+	}
+	for _, test := range tests {
+		arcFile, docFile := refactorFiles(t)
+		r, err := newRefactoring()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.apply(r); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		captureOutput(t, func() {
+			if err := r.save(); err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+		})
+		arc, _ := ioutil.ReadFile(arcFile)
+		//comments are kept
+		for _, expect := range append(test.arc, "  # the shop front\n") {
+			if !strings.Contains(string(arc), expect) {
+				t.Errorf("%s: expect %q in\n%s", test.name, expect, arc)
+			}
+		}
+		for _, unexpect := range test.missing {
+			if strings.Contains(string(arc), unexpect) {
+				t.Errorf("%s: expect no %q in\n%s", test.name, unexpect, arc)
+			}
+		}
+		if test.doc != "" {
+			if doc, _ := ioutil.ReadFile(docFile); !strings.Contains(string(doc), test.doc) {
+				t.Errorf("%s: expect %q in\n%s", test.name, test.doc, doc)
+			}
+		}
+		//the refactored file must still load
+		if _, err := newRefactoring(); err != nil {
+			t.Errorf("%s: fail to load the refactored arc: %v", test.name, err)
+		}
+	}
+}
+
+func TestRefactorErrors(t *testing.T) {
+	refactorFiles(t)
+	r, err := newRefactoring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, move := range [][2]string{
+		{"web.nowhere", "web.somewhere"},
+		{"web.api", "web.ui"},
+		{"web.api.cart", "legacy.cart"},
+		{"customer", "web.customer"},
+	} {
+		if err := r.move(move[0], move[1]); err == nil {
+			t.Errorf("expect moving %s to %s to fail", move[0], move[1])
+		}
+	}
+	if err := r.remove("web.nowhere"); err == nil {
+		t.Error("expect removing an unknown element to fail")
+	}
+}
+
+func TestRefactorDryRun(t *testing.T) {
+	arcFile, _ := refactorFiles(t)
+	refactorDryRun = true
+	defer func() { refactorDryRun = false }()
+	r, err := newRefactoring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.move("web.ui", "web.front"); err != nil {
+		t.Fatal(err)
+	}
+	out := captureOutput(t, func() {
+		if err := r.save(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	for _, expect := range []string{"-      - name: ui\n", "+      - name: front\n", "-  - {s: customer, p: use, o: web.ui}\n",
+		"+  - {s: customer, p: use, o: web.front}\n", "+++ b/"} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("expect %q in the diff\n%s", expect, out)
+		}
+	}
+	if arc, _ := ioutil.ReadFile(arcFile); string(arc) != refactorArc {
+		t.Errorf("expect a dry run to leave the arc file untouched, get\n%s", arc)
+	}
+}
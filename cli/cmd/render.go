@@ -0,0 +1,107 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/mermaid"
+	"github.com/koderizer/arc/viz/puml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var renderFormat string
+var renderOut string
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render <perspective> <targets>",
+	Short: "Generate locally the diagram code of an architecture perspective",
+	Long: `
+Generate the PlantUML or mermaid code of a perspective of the arc yaml config file, without any arcviz server.
+Arguments are the same as inspect: the perspective followed by the targets, landscape by default.
+
+PlantUML code is generated with the built-in templates, unless overridden by the templates found in the
+directory given by --templates, or the "templates" key of the arcli config. It may hold any of
+landscape.puml, context.puml, container.puml, component.puml and common.puml,
+missing ones fall back to the built-in templates.
+
+Eg:
+	arcli render container amazingSystem1 -o container.puml
+	arcli render context amazingSystem1 --templates ./arc-templates
+	arcli render landscape --format mermaid`,
+	Run: func(cmd *cobra.Command, args []string) {
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		var pers model.PresentationPerspective = model.PresentationPerspective_LANDSCAPE
+		targets := make([]string, 0)
+		if len(args) > 0 {
+			if pers, err = parsePerspective(args[0]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			targets = args[1:]
+		}
+		g, err := analyse(arc, pers, targets...)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		var src string
+		switch renderFormat {
+		case "puml":
+			templates, err := puml.NewTemplates(viper.GetString("templates"))
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			src, err = templates.Generate(g, targets...)
+		case "mermaid":
+			src, err = mermaid.Generate(g)
+		default:
+			err = fmt.Errorf("format %s not supported, please indicate one of: puml, mermaid", renderFormat)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if renderOut == "" {
+			fmt.Print(src)
+			return
+		}
+		if err := ioutil.WriteFile(renderOut, []byte(src), 0644); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to render")
+	renderCmd.Flags().StringVar(&renderFormat, "format", "puml", "Format of the generated code (puml | mermaid)")
+	renderCmd.Flags().StringVarP(&renderOut, "out", "o", "", "File to write the generated code to (default is stdout)")
+	renderCmd.Flags().String("templates", "", "Directory of the templates overriding the built-in ones")
+	viper.BindPFlag("templates", renderCmd.Flags().Lookup("templates"))
+}
@@ -56,6 +56,8 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.arcli)")
+	rootCmd.PersistentFlags().StringVarP(&workspaceRoot, "workspace", "w", "", "Discover and merge all arc files under this directory instead of reading a single arc file")
+	rootCmd.PersistentFlags().StringSliceVar(&workspaceIgnore, "ignore", nil, "Patterns of the workspace paths not searched for arc files, added to the ones of the .arcignore file at its root")
 
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
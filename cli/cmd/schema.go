@@ -0,0 +1,66 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/koderizer/arc/model"
+	"github.com/spf13/cobra"
+)
+
+var schemaOut string
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of arc yaml files",
+	Long: `
+Print the JSON Schema of arc yaml files, full arc files as well as fragments, so editors can complete and validate them.
+
+With the VS Code YAML extension, reference the schema from the arc file:
+
+	# yaml-language-server: $schema=./arc.schema.json
+
+or map it to arc files in the settings of the extension:
+
+	"yaml.schemas": { "./arc.schema.json": ["arc.yaml", "arc.yml"] }
+
+Eg:
+	arcli schema -o arc.schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := model.JSONSchema()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if schemaOut == "" {
+			fmt.Print(string(schema))
+			return
+		}
+		if err := ioutil.WriteFile(schemaOut, schema, 0644); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringVarP(&schemaOut, "out", "o", "", "Write the schema to this file instead of the standard output")
+}
@@ -0,0 +1,397 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/spf13/cobra"
+)
+
+var siteOut string
+var siteInline bool
+
+// siteCmd represents the site command
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Generate a static html documentation site of the architecture",
+	Long: `
+Generate a browsable static html site from an arc yaml file, ready to be published to any static web hosting.
+
+The site is made of:
+ - a landscape page listing all users, systems and their relations
+ - one page per internal system with its context and container diagrams
+ - one page per container with its component diagram
+
+Diagrams are rendered by the arcviz server, and linked from the plantuml server unless --inline is set,
+in which case the visuals are downloaded and embedded into the pages.
+
+Eg:
+	arcli site -o ./public`,
+	Run: func(cmd *cobra.Command, args []string) {
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		vizform, err := parseVisualFormat(outFormat)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		client, err := dialViz(vizAddress)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer client.close()
+
+		site := &siteBuilder{
+			arc:    arc,
+			out:    siteOut,
+			client: client,
+			format: vizform,
+			inline: siteInline,
+		}
+		pages, err := site.build()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated %d pages into %s\n", pages, siteOut)
+	},
+}
+
+//siteDiagram is a rendered visual embedded in a site page
+type siteDiagram struct {
+	Title  string
+	URI    string
+	Inline template.HTML
+}
+
+//sitePage hold the data to render any page of the site
+type sitePage struct {
+	Title     string
+	Arc       *model.ArcType
+	System    model.InternalSystem
+	Container model.Container
+	Path      string
+	Diagrams  []siteDiagram
+	Relations []model.Relation
+}
+
+//siteBuilder generate all pages of the site for an arc
+type siteBuilder struct {
+	arc    *model.ArcType
+	out    string
+	client *vizClient
+	format model.ArcVisualFormat
+	inline bool
+	links  map[string]string
+}
+
+//build write out every page of the site and return the number of pages generated
+func (s *siteBuilder) build() (int, error) {
+	if err := os.MkdirAll(s.out, 0755); err != nil {
+		return 0, err
+	}
+	s.links = siteLinks(s.arc)
+	tpl, err := template.New("site").Funcs(template.FuncMap{
+		"link": s.link,
+		"tags": siteTags,
+	}).Parse(siteTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	landscape, err := s.diagram("Landscape", model.PresentationPerspective_LANDSCAPE)
+	if err != nil {
+		return count, err
+	}
+	if err := s.write(tpl, "landscape", "index.html", sitePage{
+		Title:     s.arc.App,
+		Arc:       s.arc,
+		Diagrams:  []siteDiagram{landscape},
+		Relations: s.arc.Relations,
+	}); err != nil {
+		return count, err
+	}
+	count++
+
+	for _, sys := range s.arc.InternalSystems {
+		page := sitePage{
+			Title:     sys.Name,
+			Arc:       s.arc,
+			System:    sys,
+			Path:      sys.Name,
+			Relations: relationsOf(s.arc, sys.Name),
+		}
+		ctx, err := s.diagram("Context", model.PresentationPerspective_CONTEXT, sys.Name)
+		if err != nil {
+			return count, err
+		}
+		page.Diagrams = append(page.Diagrams, ctx)
+		if len(sys.Containers) > 0 {
+			con, err := s.diagram("Containers", model.PresentationPerspective_CONTAINER, sys.Name)
+			if err != nil {
+				return count, err
+			}
+			page.Diagrams = append(page.Diagrams, con)
+		}
+		if err := s.write(tpl, "system", s.links[sys.Name], page); err != nil {
+			return count, err
+		}
+		count++
+
+		for _, container := range sys.Containers {
+			path := sys.Name + "." + container.Name
+			page := sitePage{
+				Title:     path,
+				Arc:       s.arc,
+				System:    sys,
+				Container: container,
+				Path:      path,
+				Relations: relationsOf(s.arc, path),
+			}
+			if len(container.Components) > 0 {
+				com, err := s.diagram("Components", model.PresentationPerspective_COMPONENT, path)
+				if err != nil {
+					return count, err
+				}
+				page.Diagrams = append(page.Diagrams, com)
+			}
+			if err := s.write(tpl, "container", s.links[path], page); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+//diagram render one perspective of the arc, downloading the visual when inlined
+func (s *siteBuilder) diagram(title string, pers model.PresentationPerspective, targets ...string) (siteDiagram, error) {
+	uri, err := s.client.render(s.arc, pers, s.format, targets...)
+	if err != nil {
+		return siteDiagram{}, fmt.Errorf("%s diagram of %s: %v", title, strings.Join(targets, ", "), err)
+	}
+	d := siteDiagram{Title: title, URI: uri}
+	if !s.inline {
+		return d, nil
+	}
+	resp, err := http.Get(uri)
+	if err != nil {
+		return d, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return d, fmt.Errorf("Fail to download %s with code %d", uri, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return d, err
+	}
+	if s.format == model.ArcVisualFormat_SVG {
+		d.Inline = template.HTML(body)
+	} else {
+		d.Inline = template.HTML(fmt.Sprintf(`<img src="data:image/png;base64,%s" alt="%s"/>`, base64.StdEncoding.EncodeToString(body), template.HTMLEscapeString(title)))
+	}
+	return d, nil
+}
+
+func (s *siteBuilder) write(tpl *template.Template, name, file string, page sitePage) error {
+	wr := &bytes.Buffer{}
+	if err := tpl.ExecuteTemplate(wr, name, page); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.out, file), wr.Bytes(), 0644)
+}
+
+//link return the hyperlink to the page documenting the element at the given path
+func (s *siteBuilder) link(path string) string {
+	if href, ok := s.links[path]; ok {
+		return href
+	}
+	return "index.html"
+}
+
+//siteTags list the tags of an element for display
+func siteTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+//siteLinks map every element path of the arc to the page and anchor documenting it
+func siteLinks(arc *model.ArcType) map[string]string {
+	links := make(map[string]string)
+	for _, user := range arc.Users {
+		links[user.Name] = "index.html#user-" + user.Name
+	}
+	for _, sys := range arc.ExternalSystems {
+		links[sys.Name] = "index.html#external-" + sys.Name
+	}
+	for _, sys := range arc.InternalSystems {
+		links[sys.Name] = "system-" + sys.Name + ".html"
+		for _, container := range sys.Containers {
+			path := sys.Name + "." + container.Name
+			links[path] = "container-" + path + ".html"
+			for _, component := range container.Components {
+				links[path+"."+component.Name] = links[path] + "#component-" + component.Name
+			}
+		}
+	}
+	return links
+}
+
+//relationsOf return all relations to or from the element at path or any of its children
+func relationsOf(arc *model.ArcType, path string) []model.Relation {
+	relations := make([]model.Relation, 0)
+	for _, r := range arc.Relations {
+		if r.Subject == path || strings.HasPrefix(r.Subject, path+".") ||
+			r.Object == path || strings.HasPrefix(r.Object, path+".") {
+			relations = append(relations, r)
+		}
+	}
+	return relations
+}
+
+var siteTemplate = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.Arc.App}} architecture</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; color: #333; }
+nav { min-width: 14em; padding: 1em; background: #fdf6e3; min-height: 100vh; }
+nav ul { list-style: none; padding-left: 1em; }
+main { padding: 1em 2em; flex: 1; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #93a1a1; padding: 0.3em 0.8em; text-align: left; }
+figure { margin: 1em 0; }
+figure img, figure svg { max-width: 100%; height: auto; }
+</style>
+</head>
+<body>
+<nav>
+<a href="index.html"><strong>{{.Arc.App}}</strong></a>
+<ul>
+{{range .Arc.InternalSystems}}<li><a href="{{link .Name}}">{{.Name}}</a></li>
+{{end}}</ul>
+</nav>
+<main>
+{{end}}
+
+{{define "footer"}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "diagrams"}}{{range .Diagrams}}
+<h2>{{.Title}}</h2>
+<figure>{{if .Inline}}{{.Inline}}{{else}}<a href="{{.URI}}"><img src="{{.URI}}" alt="{{.Title}}"/></a>{{end}}</figure>
+{{end}}{{end}}
+
+{{define "relations"}}{{if .Relations}}
+<h2>Relations</h2>
+<table>
+<tr><th>Subject</th><th>Pointer</th><th>Object</th></tr>
+{{range .Relations}}<tr><td><a href="{{link .Subject}}">{{.Subject}}</a></td><td>{{.Pointer}}{{with .Technology}} ({{.}}){{end}}</td><td><a href="{{link .Object}}">{{.Object}}</a></td></tr>
+{{end}}</table>
+{{end}}{{end}}
+
+{{define "landscape"}}{{template "header" .}}
+<h1>{{.Arc.App}}</h1>
+<p>{{.Arc.Desc}}</p>
+{{template "diagrams" .}}
+{{if .Arc.Users}}
+<h2>Users</h2>
+<table>
+<tr><th>Name</th><th>Role</th><th>Description</th><th>Tags</th></tr>
+{{range .Arc.Users}}<tr id="user-{{.Name}}"><td>{{.Name}}{{if .External}} (external){{end}}</td><td>{{.Role}}</td><td>{{.Desc}}</td><td>{{tags .Tags}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Internal systems</h2>
+<table>
+<tr><th>Name</th><th>Role</th><th>Description</th><th>Tags</th></tr>
+{{range .Arc.InternalSystems}}<tr><td><a href="{{link .Name}}">{{.Name}}</a></td><td>{{.Role}}</td><td>{{.Desc}}</td><td>{{tags .Tags}}</td></tr>
+{{end}}</table>
+{{if .Arc.ExternalSystems}}
+<h2>External systems</h2>
+<table>
+<tr><th>Name</th><th>Role</th><th>Description</th><th>Containers</th><th>Tags</th></tr>
+{{range .Arc.ExternalSystems}}<tr id="external-{{.Name}}"><td>{{.Name}}</td><td>{{.Role}}</td><td>{{.Desc}}</td><td>{{range $i, $c := .Containers}}{{if $i}}, {{end}}{{.Name}}{{with .Technology}} ({{.}}){{end}}{{end}}</td><td>{{tags .Tags}}</td></tr>
+{{end}}</table>
+{{end}}
+{{template "relations" .}}
+{{template "footer" .}}{{end}}
+
+{{define "system"}}{{template "header" .}}
+<h1>{{.System.Name}}</h1>
+{{if .System.Role}}<p><em>{{.System.Role}}</em></p>{{end}}
+<p>{{.System.Desc}}</p>
+{{with .System.Tags}}<p>Tags: {{tags .}}</p>{{end}}
+{{template "diagrams" .}}
+{{if .System.Containers}}
+<h2>Containers</h2>
+<table>
+<tr><th>Name</th><th>Kind</th><th>Runtime</th><th>Technology</th><th>Description</th><th>Tags</th></tr>
+{{$sys := .System.Name}}{{range .System.Containers}}<tr><td><a href="{{link (printf "%s.%s" $sys .Name)}}">{{.Name}}</a></td><td>{{.ResolveKind}}</td><td>{{.Runtime}}</td><td>{{.Technology}}</td><td>{{.Desc}}</td><td>{{tags .Tags}}</td></tr>
+{{end}}</table>
+{{end}}
+{{template "relations" .}}
+{{template "footer" .}}{{end}}
+
+{{define "container"}}{{template "header" .}}
+<h1><a href="{{link .System.Name}}">{{.System.Name}}</a>.{{.Container.Name}}</h1>
+{{if .Container.Role}}<p><em>{{.Container.Role}}</em></p>{{end}}
+<p>{{.Container.Desc}}</p>
+<table>
+<tr><th>Kind</th><td>{{.Container.ResolveKind}}</td></tr>
+<tr><th>Runtime</th><td>{{.Container.Runtime}}</td></tr>
+<tr><th>Technology</th><td>{{.Container.Technology}}</td></tr>
+<tr><th>Tags</th><td>{{tags .Container.Tags}}</td></tr>
+</table>
+{{template "diagrams" .}}
+{{if .Container.Components}}
+<h2>Components</h2>
+<table>
+<tr><th>Name</th><th>Role</th><th>Technology</th><th>Code</th><th>Description</th><th>Tags</th></tr>
+{{range .Container.Components}}<tr id="component-{{.Name}}"><td>{{.Name}}</td><td>{{.Role}}</td><td>{{.Technology}}</td><td>{{.Code}}</td><td>{{.Desc}}</td><td>{{tags .Tags}}</td></tr>
+{{end}}</table>
+{{end}}
+{{template "relations" .}}
+{{template "footer" .}}{{end}}
+`
+
+func init() {
+	rootCmd.AddCommand(siteCmd)
+
+	siteCmd.PersistentFlags().StringVar(&vizAddress, "viz", "localhost:10000", "URI of an acrviz app")
+	siteCmd.PersistentFlags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to document")
+	siteCmd.PersistentFlags().StringVar(&outFormat, "outform", defaultOutForm, "Diagram format (png | svg)")
+	siteCmd.PersistentFlags().StringVarP(&siteOut, "out", "o", "./public", "Output directory of the site")
+	siteCmd.PersistentFlags().BoolVar(&siteInline, "inline", false, "Download and embed the diagrams into the pages")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+	"google.golang.org/grpc"
+)
+
+//fakeViz render every request as the location of a visual named after its perspective and targets
+type fakeViz struct{}
+
+func (fakeViz) Render(ctx context.Context, in *model.RenderRequest, opts ...grpc.CallOption) (*model.ArcPresentation, error) {
+	uri := "https://viz/" + strings.ToLower(in.VisualFormat.String()) + "/" + strings.ToLower(in.Perspective.String())
+	for _, target := range in.Target {
+		uri += "/" + target
+	}
+	return &model.ArcPresentation{Data: []byte(uri)}, nil
+}
+
+func TestSiteBuild(t *testing.T) {
+	arc, err := loadArc("testdata/site/arc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	site := &siteBuilder{arc: arc, out: out, client: &vizClient{client: fakeViz{}}, format: model.ArcVisualFormat_SVG}
+	pages, err := site.build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 5 {
+		t.Errorf("expect 5 pages, get %d", pages)
+	}
+	tests := []struct {
+		page   string
+		expect []string
+	}{
+		{"index.html", []string{
+			`<img src="https://viz/svg/landscape" alt="Landscape"/>`,
+			`<tr id="user-customer"><td>customer</td><td>buy things</td><td></td><td>vip</td></tr>`,
+			`<td><a href="system-web.html">web</a></td><td>storefront</td><td></td><td>core</td>`,
+			`<tr id="external-payments"><td>payments</td><td>card payments</td><td></td><td>gateway (rest)</td><td></td></tr>`,
+			`<td><a href="index.html#user-customer">customer</a></td><td>use</td><td><a href="container-web.api.html">web.api</a></td>`,
+		}},
+		{"system-web.html", []string{
+			`<img src="https://viz/svg/context/web" alt="Context"/>`,
+			`<img src="https://viz/svg/container/web" alt="Containers"/>`,
+			`<p>Tags: core</p>`,
+			`<td><a href="container-web.api.html">api</a></td><td>service</td><td>docker</td><td>go</td><td></td><td>public</td>`,
+			`<td><a href="container-web.db.html">db</a></td><td>database</td><td></td><td>postgres</td>`,
+			`<td><a href="container-web.events.html">events</a></td><td>queue</td><td></td><td>nats</td>`,
+			`<td><a href="index.html#external-payments">payments</a></td>`,
+		}},
+		{"container-web.api.html", []string{
+			`<h1><a href="system-web.html">web</a>.api</h1>`,
+			`<img src="https://viz/svg/component/web.api" alt="Components"/>`,
+			`<tr><th>Kind</th><td>service</td></tr>`,
+			`<tr><th>Runtime</th><td>docker</td></tr>`,
+			`<tr><th>Technology</th><td>go</td></tr>`,
+			`<tr><th>Tags</th><td>public</td></tr>`,
+			`<tr id="component-cart"><td>cart</td><td></td><td>go</td><td>internal/cart</td><td></td><td>hot</td></tr>`,
+			`<td><a href="container-web.api.html#component-cart">web.api.cart</a></td><td>store (sql)</td><td><a href="container-web.db.html">web.db</a></td>`,
+		}},
+		{"container-web.db.html", []string{`<tr><th>Kind</th><td>database</td></tr>`}},
+		{"container-web.events.html", []string{`<tr><th>Kind</th><td>queue</td></tr>`}},
+	}
+	href := regexp.MustCompile(`href="([^"#]+)(#[^"]*)?"`)
+	for _, test := range tests {
+		content, err := ioutil.ReadFile(filepath.Join(out, test.page))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for _, expect := range test.expect {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("expect %s in %s\n%s", expect, test.page, content)
+			}
+		}
+		//every link lead to a generated page, with the anchor of the element
+		for _, link := range href.FindAllStringSubmatch(string(content), -1) {
+			if strings.HasPrefix(link[1], "https://") {
+				continue
+			}
+			target, err := ioutil.ReadFile(filepath.Join(out, link[1]))
+			if err != nil {
+				t.Errorf("%s: broken link %s", test.page, link[0])
+				continue
+			}
+			if link[2] != "" && !strings.Contains(string(target), `id="`+link[2][1:]+`"`) {
+				t.Errorf("%s: broken anchor %s", test.page, link[0])
+			}
+		}
+	}
+}
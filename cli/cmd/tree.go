@@ -0,0 +1,188 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/ascii"
+	"github.com/spf13/cobra"
+)
+
+var treeCharset string
+
+// treeCmd represents the tree command
+var treeCmd = &cobra.Command{
+	Use:   "tree [paths]",
+	Short: "Print the hierarchy of the elements of an architecture with their relation counts",
+	Long: `
+Print the users, systems with their containers and components, and external systems of the arc file given with -f,
+or of the workspace given with -w, as a tree. Each element shows the number of relations leaving it and coming to it,
+relations between its own children not being counted.
+
+Given element paths, only the subtrees of these elements are printed.
+
+Eg:
+	arcli tree
+	arcli tree arc.arcviz --charset ascii`,
+	Run: func(cmd *cobra.Command, args []string) {
+		arc, err := loadArc(arcFilename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		charset, err := parseCharset(treeCharset)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		root := arcTree(arc)
+		if len(args) == 0 {
+			root.print(os.Stdout, charset)
+			return
+		}
+		for _, path := range args {
+			node := root.find(path)
+			if node == nil {
+				fmt.Printf("element %s not found\n", path)
+				os.Exit(1)
+			}
+			node.print(os.Stdout, charset)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+	treeCmd.Flags().StringVarP(&arcFilename, "file", "f", defaultArcFile, "Path to the arc.yaml file to print")
+	treeCmd.Flags().StringVar(&treeCharset, "charset", "unicode", "Characters of the tree branches (unicode | ascii)")
+}
+
+//treeNode is an element of the printed hierarchy, or a heading gathering elements of a kind
+type treeNode struct {
+	path     string
+	label    string
+	children []*treeNode
+}
+
+//arcTree build the hierarchy of the elements of an arc, labelled with their technology and relation counts
+func arcTree(arc *model.ArcType) *treeNode {
+	root := &treeNode{label: arc.App}
+	element := func(path, name, technology string) *treeNode {
+		label := name
+		if technology != "" {
+			label += " [" + technology + "]"
+		}
+		if counts := relationCounts(arc.Relations, path); counts != "" {
+			label += "  (" + counts + ")"
+		}
+		return &treeNode{path: path, label: label}
+	}
+	containers := func(parent *treeNode, conts []model.Container) {
+		for _, cont := range conts {
+			c := element(parent.path+"."+cont.Name, cont.Name, cont.Technology)
+			for _, comp := range cont.Components {
+				c.children = append(c.children, element(c.path+"."+comp.Name, comp.Name, comp.Technology))
+			}
+			parent.children = append(parent.children, c)
+		}
+	}
+	if len(arc.Users) > 0 {
+		users := &treeNode{label: "users"}
+		for _, u := range arc.Users {
+			users.children = append(users.children, element(u.Name, u.Name, ""))
+		}
+		root.children = append(root.children, users)
+	}
+	if len(arc.InternalSystems) > 0 {
+		systems := &treeNode{label: "internal systems"}
+		for _, iSys := range arc.InternalSystems {
+			s := element(iSys.Name, iSys.Name, "")
+			containers(s, iSys.Containers)
+			systems.children = append(systems.children, s)
+		}
+		root.children = append(root.children, systems)
+	}
+	if len(arc.ExternalSystems) > 0 {
+		systems := &treeNode{label: "external systems"}
+		for _, eSys := range arc.ExternalSystems {
+			s := element(eSys.Name, eSys.Name, "")
+			containers(s, eSys.Containers)
+			systems.children = append(systems.children, s)
+		}
+		root.children = append(root.children, systems)
+	}
+	return root
+}
+
+//relationCounts count the relations crossing the boundary of an element, leaving it or coming to it
+func relationCounts(relations []model.Relation, path string) string {
+	within := func(p string) bool { return p == path || strings.HasPrefix(p, path+".") }
+	out, in := 0, 0
+	for _, rel := range relations {
+		switch s, o := within(rel.Subject), within(rel.Object); {
+		case s && !o:
+			out++
+		case o && !s:
+			in++
+		}
+	}
+	counts := make([]string, 0, 2)
+	if out > 0 {
+		counts = append(counts, fmt.Sprintf("%d out", out))
+	}
+	if in > 0 {
+		counts = append(counts, fmt.Sprintf("%d in", in))
+	}
+	return strings.Join(counts, ", ")
+}
+
+//find return the node of the element at the given path
+func (n *treeNode) find(path string) *treeNode {
+	if n.path == path && path != "" {
+		return n
+	}
+	for _, child := range n.children {
+		if found := child.find(path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+//print write the node and its descendants, one per line, with branches drawn in the given charset
+func (n *treeNode) print(w io.Writer, charset ascii.Charset) {
+	fmt.Fprintln(w, n.label)
+	n.printChildren(w, "", charset)
+}
+
+func (n *treeNode) printChildren(w io.Writer, indent string, charset ascii.Charset) {
+	branch := string([]rune{charset.Tee, charset.Horizontal, charset.Horizontal}) + " "
+	last := string([]rune{charset.BottomLeft, charset.Horizontal, charset.Horizontal}) + " "
+	for i, child := range n.children {
+		if i == len(n.children)-1 {
+			fmt.Fprintln(w, indent+last+child.label)
+			child.printChildren(w, indent+"    ", charset)
+			continue
+		}
+		fmt.Fprintln(w, indent+branch+child.label)
+		child.printChildren(w, indent+string(charset.Vertical)+"   ", charset)
+	}
+}
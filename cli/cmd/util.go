@@ -0,0 +1,226 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+	"github.com/koderizer/arc/viz/ascii"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v2"
+)
+
+//loadArc read and parse the arc yaml file at the given path, merged with the arc files it includes,
+//or all the arc files of the workspace when a workspace root is given
+func loadArc(filename string) (*model.ArcType, error) {
+	loader := newArcLoader()
+	var arc *model.ArcType
+	var err error
+	if workspaceRoot != "" {
+		arc, err = loader.loadWorkspace(workspaceRoot)
+	} else {
+		arc, err = loader.load(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return arc, loader.finish(arc)
+}
+
+//readArc parse the content of a single arc yaml file, with its theme
+func readArc(filename string, content []byte) (*model.ArcType, error) {
+	arc := &model.ArcType{}
+	if err := yaml.Unmarshal(content, arc); err != nil {
+		return nil, fmt.Errorf("fail to parse yaml content of %s: %v", filename, err)
+	}
+	if arc.Theme != "" {
+		styles, err := loadTheme(filepath.Join(filepath.Dir(filename), arc.Theme))
+		if err != nil {
+			return nil, err
+		}
+		styles.Merge(arc.Styles)
+		arc.Styles = styles
+	}
+	return arc, nil
+}
+
+//loadTheme read the styles of a theme file, the inline styles of an arc file take precedence over them
+func loadTheme(filename string) (model.Styles, error) {
+	styles := model.Styles{}
+	themeFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return styles, fmt.Errorf("fail to read theme file %s: %v", filename, err)
+	}
+	if err := yaml.Unmarshal(themeFile, &styles); err != nil {
+		return styles, fmt.Errorf("fail to parse yaml content of %s: %v", filename, err)
+	}
+	return styles, nil
+}
+
+//parsePerspective map a perspective name given on command line to its model value
+func parsePerspective(name string) (model.PresentationPerspective, error) {
+	switch name {
+	case "landscape":
+		return model.PresentationPerspective_LANDSCAPE, nil
+	case "context":
+		return model.PresentationPerspective_CONTEXT, nil
+	case "container":
+		return model.PresentationPerspective_CONTAINER, nil
+	case "component":
+		return model.PresentationPerspective_COMPONENT, nil
+	case "code":
+		return model.PresentationPerspective_CODE, fmt.Errorf("Not supported for now. Make simple readable code")
+	default:
+		return model.PresentationPerspective_LANDSCAPE, fmt.Errorf("Perspective %s not supported, please indicate one of: landscape, context, container, component", name)
+	}
+}
+
+//parseVisualFormat map an output format given on command line to its model value
+func parseVisualFormat(format string) (model.ArcVisualFormat, error) {
+	switch format {
+	case "svg":
+		return model.ArcVisualFormat_SVG, nil
+	case "png":
+		return model.ArcVisualFormat_PNG, nil
+	default:
+		return model.ArcVisualFormat_SVG, fmt.Errorf("Visual form %s not supported", format)
+	}
+}
+
+//parseCharset map a charset name given on command line to the characters terminal drawings are made of
+func parseCharset(name string) (ascii.Charset, error) {
+	switch name {
+	case "unicode":
+		return ascii.Unicode, nil
+	case "ascii":
+		return ascii.ASCII, nil
+	default:
+		return ascii.Unicode, fmt.Errorf("Charset %s not supported, please indicate one of: unicode, ascii", name)
+	}
+}
+
+//parseFocusDirection map a direction given on command line to the relations followed from the focus of a view
+func parseFocusDirection(name string) (model.FocusDirection, error) {
+	switch name {
+	case "both":
+		return model.FocusDirection_BOTH, nil
+	case "out":
+		return model.FocusDirection_OUTGOING, nil
+	case "in":
+		return model.FocusDirection_INCOMING, nil
+	default:
+		return model.FocusDirection_BOTH, fmt.Errorf("Direction %s not supported, please indicate one of: both, out, in", name)
+	}
+}
+
+//parseRelationLevel map a level given on command line to the most detailed elements whose relations are shown
+func parseRelationLevel(name string) (model.RelationLevel, error) {
+	switch name {
+	case "all":
+		return model.RelationLevel_ALL_LEVELS, nil
+	case "system":
+		return model.RelationLevel_SYSTEM_LEVEL, nil
+	case "container":
+		return model.RelationLevel_CONTAINER_LEVEL, nil
+	default:
+		return model.RelationLevel_ALL_LEVELS, fmt.Errorf("Relation level %s not supported, please indicate one of: all, system, container", name)
+	}
+}
+
+//parseRollUp map a roll-up given on command line to how far relations are rolled up to the parents of their elements
+func parseRollUp(name string) (model.RollUp, error) {
+	switch name {
+	case "full":
+		return model.RollUp_FULL_ROLL_UP, nil
+	case "container":
+		return model.RollUp_CONTAINER_ROLL_UP, nil
+	case "none":
+		return model.RollUp_NO_ROLL_UP, nil
+	default:
+		return model.RollUp_FULL_ROLL_UP, fmt.Errorf("Roll-up %s not supported, please indicate one of: full, container, none", name)
+	}
+}
+
+//newRequest build the request to render a perspective of the arc data
+func newRequest(arc *model.ArcType, pers model.PresentationPerspective, format model.ArcVisualFormat, targets ...string) (*model.RenderRequest, error) {
+	data, err := arc.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("Fail to encode data: %v", err)
+	}
+	return &model.RenderRequest{
+		VisualFormat: format,
+		DataFormat:   model.ArcDataFormat_ARC,
+		Data:         data,
+		Target:       targets,
+		Perspective:  pers,
+	}, nil
+}
+
+//analyse build locally the graph of a perspective of the arc, the same way an arcviz server does
+func analyse(arc *model.ArcType, pers model.PresentationPerspective, targets ...string) (*analyzer.Graph, error) {
+	req, err := newRequest(arc, pers, model.ArcVisualFormat_SVG, targets...)
+	if err != nil {
+		return nil, err
+	}
+	return analyseRequest(req)
+}
+
+//analyseRequest build locally the graph of a render request, the same way an arcviz server does
+func analyseRequest(req *model.RenderRequest) (*analyzer.Graph, error) {
+	return analyzer.Process(context.Background(), req)
+}
+
+//vizClient hold a connection to an arcviz server
+type vizClient struct {
+	conn   *grpc.ClientConn
+	client model.ArcVizClient
+}
+
+//dialViz connect to the arcviz server at the given address
+func dialViz(address string) (*vizClient, error) {
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("Unable to contact arc-viz server at %s with error: %+v", address, err)
+	}
+	return &vizClient{conn: conn, client: model.NewArcVizClient(conn)}, nil
+}
+
+//render request the arcviz server to render a perspective of the arc data and return the location of the visual
+func (v *vizClient) render(arc *model.ArcType, pers model.PresentationPerspective, format model.ArcVisualFormat, targets ...string) (string, error) {
+	req, err := newRequest(arc, pers, format, targets...)
+	if err != nil {
+		return "", err
+	}
+	return v.renderRequest(req)
+}
+
+//renderRequest request the arcviz server to render the given request and return the location of the visual
+func (v *vizClient) renderRequest(req *model.RenderRequest) (string, error) {
+	viz, err := v.client.Render(context.Background(), req)
+	if err != nil {
+		return "", fmt.Errorf("Fail to render with error: %+v", err)
+	}
+	return string(viz.GetData()), nil
+}
+
+func (v *vizClient) close() error {
+	return v.conn.Close()
+}
@@ -0,0 +1,383 @@
+/*
+Copyright © 2020 Koderizer
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"gopkg.in/yaml.v2"
+)
+
+//workspaceRoot is the directory under which arc files are discovered, instead of loading a single arc file
+var workspaceRoot string
+
+//arcFileNames are the names of the arc files discovered in a workspace
+var arcFileNames = map[string]bool{"arc.yaml": true, "arc.yml": true}
+
+//skippedDirs are never searched for arc files
+var skippedDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+//ignoreFileName is the file at the root of a workspace listing the patterns of the paths not searched for arc files
+const ignoreFileName = ".arcignore"
+
+//workspaceIgnore are patterns of paths not searched for arc files, added to the ones of the ignore file
+var workspaceIgnore []string
+
+//orphan are containers of a fragment without system, attached once all arc files are loaded
+type orphan struct {
+	file       string
+	containers []model.Container
+}
+
+//arcLoader read arc files and merge them into one arc, remembering the files each element come from
+type arcLoader struct {
+	loaded    map[string]bool
+	stack     []string
+	cacheDir  string
+	origins   map[string][]string
+	relations map[string]string
+	orphans   []orphan
+}
+
+func newArcLoader() *arcLoader {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return &arcLoader{
+		loaded:    make(map[string]bool),
+		cacheDir:  filepath.Join(cacheDir, "arcli", "includes"),
+		origins:   make(map[string][]string),
+		relations: make(map[string]string),
+	}
+}
+
+//loadWorkspace discover all arc files under the root directory and merge them, shallower files taking precedence
+func (l *arcLoader) loadWorkspace(root string) (*model.ArcType, error) {
+	files, err := discoverArcFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no arc file found under %s", root)
+	}
+	arc := &model.ArcType{}
+	for _, file := range files {
+		if l.loaded[file] {
+			continue
+		}
+		sub, err := l.load(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.merge(arc, sub); err != nil {
+			return nil, err
+		}
+	}
+	return arc, nil
+}
+
+//discoverArcFiles return the absolute paths of the arc files under the root directory, shallowest first
+func discoverArcFiles(root string) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := ignorePatterns(root)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		skipped := ignored(root, path, patterns)
+		if info.IsDir() {
+			if skipped || strings.HasPrefix(info.Name(), ".") || skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if arcFileNames[info.Name()] && !skipped {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to discover arc files under %s: %v", root, err)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		di, dj := strings.Count(files[i], string(filepath.Separator)), strings.Count(files[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return files[i] < files[j]
+	})
+	return files, nil
+}
+
+//ignorePatterns return the patterns of the ignore file at the root of the workspace, one per line with # comments,
+//followed by the ones given with --ignore
+func ignorePatterns(root string) ([]string, error) {
+	patterns := make([]string, 0)
+	content, err := ioutil.ReadFile(filepath.Join(root, ignoreFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("fail to read %s: %v", ignoreFileName, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return append(patterns, workspaceIgnore...), nil
+}
+
+//ignored tell whether a path of the workspace match one of the patterns, either by its path relative to the root,
+//eg examples or services/*/testdata, or by its name when the pattern has no slash, eg testdata
+func ignored(root, path string, patterns []string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "/"), "/")
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//read parse an arc file or fragment and record the elements it defines
+func (l *arcLoader) read(path string) (*model.ArcType, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read arc yaml file %s: %v", l.rel(path), err)
+	}
+	content, _, err = migrateArc(content)
+	if err != nil {
+		return nil, fmt.Errorf("fail to migrate %s: %v", l.rel(path), err)
+	}
+	keys := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("fail to parse yaml content of %s: %v", l.rel(path), err)
+	}
+	var arc *model.ArcType
+	if isFragment(keys) {
+		arc, err = l.readFragment(path, content)
+	} else {
+		arc, err = readArc(path, content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.record(arc, path)
+	return arc, nil
+}
+
+//isFragment tell whether the keys of an arc file are the ones of a fragment rather than of a full arc
+func isFragment(keys map[string]interface{}) bool {
+	for _, key := range []string{"app", "users", "internal-systems", "external-systems"} {
+		if _, ok := keys[key]; ok {
+			return false
+		}
+	}
+	_, system := keys["system"]
+	_, container := keys["container"]
+	_, containers := keys["containers"]
+	return system || container || containers
+}
+
+//readFragment turn a fragment into an arc holding its system, or put aside its containers when it has no system
+func (l *arcLoader) readFragment(path string, content []byte) (*model.ArcType, error) {
+	fragment := model.Fragment{}
+	if err := yaml.Unmarshal(content, &fragment); err != nil {
+		return nil, fmt.Errorf("fail to parse yaml content of %s: %v", l.rel(path), err)
+	}
+	containers := fragment.Containers
+	if fragment.Container != nil {
+		containers = append(containers, *fragment.Container)
+	}
+	arc := &model.ArcType{Relations: fragment.Relations, Include: fragment.Include}
+	if fragment.System == "" {
+		l.orphans = append(l.orphans, orphan{file: path, containers: containers})
+		return arc, nil
+	}
+	arc.InternalSystems = []model.InternalSystem{{
+		Name:       fragment.System,
+		Role:       fragment.Role,
+		Desc:       fragment.Desc,
+		Tags:       fragment.Tags,
+		Containers: containers,
+	}}
+	return arc, nil
+}
+
+//finish attach the containers of fragments without system and check that relations only reference known elements
+func (l *arcLoader) finish(arc *model.ArcType) error {
+	for _, o := range l.orphans {
+		for _, c := range o.containers {
+			systems := make([]string, 0)
+			for _, sys := range arc.InternalSystems {
+				for _, existing := range sys.Containers {
+					if existing.Name == c.Name {
+						systems = append(systems, sys.Name)
+					}
+				}
+			}
+			if len(systems) != 1 {
+				return fmt.Errorf("container %s of %s must belong to exactly one system, found %d: set the system of the fragment", c.Name, l.rel(o.file), len(systems))
+			}
+			part := &model.ArcType{InternalSystems: []model.InternalSystem{{Name: systems[0], Containers: []model.Container{c}}}}
+			l.record(part, o.file)
+			if err := l.merge(arc, part); err != nil {
+				return err
+			}
+		}
+	}
+	l.orphans = nil
+
+	known := arcElements(arc)
+	for _, r := range arc.Relations {
+		for _, end := range []string{r.Subject, r.Object} {
+			if known[end] == "" {
+				return fmt.Errorf("relation %s -> %s of %s reference unknown element %s", r.Subject, r.Object, l.rel(l.relations[relationKey(r)]), end)
+			}
+		}
+	}
+	return nil
+}
+
+//arcElements return the kind of each element of the arc by path
+func arcElements(arc *model.ArcType) map[string]string {
+	kinds := make(map[string]string)
+	for _, u := range arc.Users {
+		kinds[u.Name] = "user"
+	}
+	for _, sys := range arc.InternalSystems {
+		kinds[sys.Name] = "internal system"
+		for _, c := range sys.Containers {
+			kinds[sys.Name+"."+c.Name] = "container"
+			for _, com := range c.Components {
+				kinds[sys.Name+"."+c.Name+"."+com.Name] = "component"
+			}
+		}
+	}
+	for _, sys := range arc.ExternalSystems {
+		kinds[sys.Name] = "external system"
+		for _, c := range sys.Containers {
+			kinds[sys.Name+"."+c.Name] = "external container"
+		}
+	}
+	return kinds
+}
+
+//merge merge the sub arc into the arc, reporting the files defining a conflicting element
+func (l *arcLoader) merge(arc, sub *model.ArcType) error {
+	err := arc.Merge(*sub)
+	if conflict, ok := err.(*model.ConflictError); ok {
+		files := make([]string, 0)
+		for _, f := range l.origins[originKey(conflict.Kind, conflict.Path)] {
+			files = append(files, l.rel(f))
+		}
+		return fmt.Errorf("%v, defined in %s", conflict, strings.Join(files, ", "))
+	}
+	return err
+}
+
+//record remember the file defining each element of the arc
+func (l *arcLoader) record(arc *model.ArcType, path string) {
+	add := func(key string) {
+		for _, f := range l.origins[key] {
+			if f == path {
+				return
+			}
+		}
+		l.origins[key] = append(l.origins[key], path)
+	}
+	for _, u := range arc.Users {
+		add(u.Name)
+	}
+	for _, sys := range arc.InternalSystems {
+		add(sys.Name)
+		for _, c := range sys.Containers {
+			add(sys.Name + "." + c.Name)
+			for _, com := range c.Components {
+				add(sys.Name + "." + c.Name + "." + com.Name)
+			}
+		}
+	}
+	for _, sys := range arc.ExternalSystems {
+		add(sys.Name)
+		for _, c := range sys.Containers {
+			add(sys.Name + "." + c.Name)
+		}
+	}
+	var addGroups func(groups []model.Group)
+	addGroups = func(groups []model.Group) {
+		for _, g := range groups {
+			add(originKey("group", g.Name))
+			addGroups(g.Groups)
+		}
+	}
+	addGroups(arc.Groups)
+	for _, r := range arc.Relations {
+		if _, ok := l.relations[relationKey(r)]; !ok {
+			l.relations[relationKey(r)] = path
+		}
+	}
+}
+
+//relationKey identify a relation among the recorded origins
+func relationKey(r model.Relation) string {
+	return r.Subject + "\x00" + r.Pointer + "\x00" + r.Object
+}
+
+//originKey is the key of an element among the recorded origins, groups having their own namespace
+func originKey(kind, path string) string {
+	if kind == "group" {
+		return "group " + path
+	}
+	return path
+}
+
+//rel return the path relative to the working directory when it is shorter
+func (l *arcLoader) rel(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && len(rel) < len(path) {
+		return rel
+	}
+	return path
+}
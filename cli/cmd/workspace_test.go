@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+//writeFiles create the files of the given content under the directory
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiscoverArcFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"arc.yaml":                         "app: test\n",
+		"api/arc.yml":                      "system: api\n",
+		"api/testdata/arc.yaml":            "system: api\n",
+		"examples/arc.yaml":                "app: example\n",
+		"web/node_modules/lib/arc.yaml":    "app: lib\n",
+		".git/arc.yaml":                    "app: git\n",
+		"services/pay/arc.yaml":            "system: pay\n",
+		"services/pay/fixtures/arc.yaml":   "system: pay\n",
+		"services/orders/fixtures/arc.yml": "system: orders\n",
+	})
+	tests := []struct {
+		ignoreFile string
+		ignore     []string
+		files      []string
+	}{
+		{"", nil, []string{"arc.yaml", "api/arc.yml", "examples/arc.yaml", "api/testdata/arc.yaml", "services/pay/arc.yaml",
+			"services/orders/fixtures/arc.yml", "services/pay/fixtures/arc.yaml"}},
+		//patterns without slash match names at any depth, others match paths from the root
+		{"# not part of the architecture\nexamples/\ntestdata\n", []string{"services/*/fixtures"}, []string{"arc.yaml", "api/arc.yml", "services/pay/arc.yaml"}},
+		{"", []string{"api/arc.yml", "services"}, []string{"arc.yaml", "examples/arc.yaml", "api/testdata/arc.yaml"}},
+	}
+	for i, test := range tests {
+		os.Remove(filepath.Join(root, ignoreFileName))
+		if test.ignoreFile != "" {
+			writeFiles(t, root, map[string]string{ignoreFileName: test.ignoreFile})
+		}
+		workspaceIgnore = test.ignore
+		files, err := discoverArcFiles(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels := make([]string, 0, len(files))
+		for _, f := range files {
+			rel, _ := filepath.Rel(root, f)
+			rels = append(rels, filepath.ToSlash(rel))
+		}
+		if !reflect.DeepEqual(rels, test.files) {
+			t.Errorf("Test %d: expect %v, get %v", i, test.files, rels)
+		}
+	}
+	workspaceIgnore = nil
+}
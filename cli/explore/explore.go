@@ -0,0 +1,463 @@
+//Package explore implement an interactive terminal browser of an analysed architecture
+package explore
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+const help = "↑↓ move  → open  ← back  tab relations  ⏎ jump  t tag  r render  q quit"
+
+//Explorer hold the state of the browsing of an architecture, driven by keys and drawn as lines of text
+type Explorer struct {
+	//Render render the element at the given path, eg in the browser, and return a message to show
+	Render func(path string) (string, error)
+
+	graph *analyzer.Graph
+	//parent is the path of the element whose children are listed, empty for the top level elements
+	parent string
+	items  []analyzer.Vertice
+	cursor int
+	//focus is on the relations of the selected element rather than on the list of elements
+	focus     bool
+	relCursor int
+	tag       string
+	//typing is set while a tag is typed in input
+	typing bool
+	input  string
+	status string
+}
+
+//relation is a relation of the selected element, with the element at its other end
+type relation struct {
+	model.Relation
+	other    string
+	outgoing bool
+}
+
+//New return an explorer listing the top level elements of the analysed graph
+func New(g *analyzer.Graph) *Explorer {
+	e := &Explorer{graph: g}
+	e.open("", "")
+	return e
+}
+
+//Selected return the element under the cursor
+func (e *Explorer) Selected() (analyzer.Vertice, bool) {
+	if e.cursor < 0 || e.cursor >= len(e.items) {
+		return analyzer.Vertice{}, false
+	}
+	return e.items[e.cursor], true
+}
+
+//open list the children of the parent matching the tag filter, with the cursor on the given element if listed
+func (e *Explorer) open(parent, selected string) {
+	e.parent, e.cursor, e.focus, e.relCursor = parent, 0, false, 0
+	e.items = e.items[:0]
+	for _, v := range e.graph.Children(parent) {
+		if e.tag == "" || e.tagged(v.Path) {
+			if v.Path == selected {
+				e.cursor = len(e.items)
+			}
+			e.items = append(e.items, v)
+		}
+	}
+}
+
+//tagged tell whether the element at the path, or one of its descendants, has the tag of the filter
+func (e *Explorer) tagged(path string) bool {
+	v, ok := e.graph.Element(path)
+	if !ok {
+		return false
+	}
+	for _, tag := range tags(v) {
+		if tag == e.tag {
+			return true
+		}
+	}
+	for _, child := range e.graph.Children(path) {
+		if e.tagged(child.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+//relations return the relations of the selected element, outgoing first
+func (e *Explorer) relations() []relation {
+	v, ok := e.Selected()
+	if !ok {
+		return nil
+	}
+	out, in := e.graph.ElementRelations(v.Path)
+	rels := make([]relation, 0, len(out)+len(in))
+	for _, r := range out {
+		rels = append(rels, relation{Relation: r, other: r.Object, outgoing: true})
+	}
+	for _, r := range in {
+		rels = append(rels, relation{Relation: r, other: r.Subject})
+	}
+	return rels
+}
+
+//Handle update the state with a key, a character or one of up, down, left, right, enter, tab, backspace, esc and ctrl-c,
+//and return false when the explorer is quit
+func (e *Explorer) Handle(key string) bool {
+	if e.typing {
+		switch key {
+		case "enter":
+			e.typing = false
+			e.filter(strings.TrimSpace(e.input))
+		case "esc":
+			e.typing = false
+		case "backspace":
+			if _, size := utf8.DecodeLastRuneInString(e.input); size > 0 {
+				e.input = e.input[:len(e.input)-size]
+			}
+		case "ctrl-c":
+			return false
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				e.input += key
+			}
+		}
+		return true
+	}
+	e.status = ""
+	rels := e.relations()
+	switch key {
+	case "q", "ctrl-c":
+		return false
+	case "up", "k":
+		if e.focus && e.relCursor > 0 {
+			e.relCursor--
+		} else if !e.focus && e.cursor > 0 {
+			e.cursor--
+		}
+	case "down", "j":
+		if e.focus && e.relCursor < len(rels)-1 {
+			e.relCursor++
+		} else if !e.focus && e.cursor < len(e.items)-1 {
+			e.cursor++
+		}
+	case "right", "l":
+		e.drill()
+	case "enter":
+		if e.focus && e.relCursor < len(rels) {
+			e.jump(rels[e.relCursor].other)
+		} else {
+			e.drill()
+		}
+	case "left", "h", "backspace":
+		if e.focus {
+			e.focus = false
+		} else if e.parent != "" {
+			e.back()
+		}
+	case "esc":
+		e.focus = false
+	case "tab":
+		if len(rels) > 0 {
+			e.focus, e.relCursor = !e.focus, 0
+		}
+	case "t", "/":
+		e.typing, e.input = true, e.tag
+	case "r":
+		e.render()
+	}
+	return true
+}
+
+//drill list the children of the selected element
+func (e *Explorer) drill() {
+	v, ok := e.Selected()
+	if !ok {
+		return
+	}
+	if len(e.graph.Children(v.Path)) == 0 {
+		e.status = fmt.Sprintf("%s has no children", v.Path)
+		return
+	}
+	e.open(v.Path, "")
+	if len(e.items) == 0 {
+		e.status = fmt.Sprintf("no children of %s tagged %s", v.Path, e.tag)
+	}
+}
+
+//back list the siblings of the parent, with the cursor on it
+func (e *Explorer) back() {
+	e.open(parentPath(e.parent), e.parent)
+}
+
+//jump select the element at the given path, listed among its siblings, clearing the tag filter if it hides the element
+func (e *Explorer) jump(path string) {
+	if e.tag != "" && !e.tagged(path) {
+		e.tag = ""
+		e.status = fmt.Sprintf("tag filter cleared to show %s", path)
+	}
+	e.open(parentPath(path), path)
+}
+
+//filter list only the elements with the tag, or with descendants having it, the empty tag listing all elements
+func (e *Explorer) filter(tag string) {
+	e.tag = tag
+	parent := e.parent
+	//the parent may no longer be reachable through the filter
+	for parent != "" && tag != "" && !e.tagged(parent) {
+		parent = parentPath(parent)
+	}
+	selected := ""
+	if v, ok := e.Selected(); ok {
+		selected = v.Path
+	}
+	e.open(parent, selected)
+	if len(e.items) == 0 {
+		e.status = fmt.Sprintf("no element tagged %s", tag)
+	}
+}
+
+//render render the selected element with the Render callback
+func (e *Explorer) render() {
+	v, ok := e.Selected()
+	if !ok || e.Render == nil {
+		return
+	}
+	msg, err := e.Render(v.Path)
+	if err != nil {
+		e.status = err.Error()
+		return
+	}
+	e.status = msg
+}
+
+//View draw the explorer in lines of at most the given width, the list of elements on the left
+//and the selected element with its relations on the right
+func (e *Explorer) View(width, height int) []string {
+	title := "arc explorer: " + e.graph.Arc.App
+	if e.parent != "" {
+		title += " › " + strings.Join(strings.Split(e.parent, "."), " › ")
+	}
+	if e.tag != "" {
+		title += fmt.Sprintf("  [tag: %s]", e.tag)
+	}
+	lines := []string{truncate(title, width), strings.Repeat("─", width)}
+
+	body := height - 4
+	if body < 1 {
+		body = 1
+	}
+	listWidth := width / 3
+	if listWidth < 20 {
+		listWidth = 20
+	}
+	if listWidth > 40 {
+		listWidth = 40
+	}
+	list := make([]string, 0, len(e.items))
+	for i, v := range e.items {
+		marker := "  "
+		if i == e.cursor {
+			marker = "▸ "
+			if e.focus {
+				marker = "› "
+			}
+		}
+		name := v.Path[strings.LastIndex(v.Path, ".")+1:]
+		if len(e.graph.Children(v.Path)) > 0 {
+			name += " ›"
+		}
+		list = append(list, marker+name)
+	}
+	details, selectedLine := e.details(width - listWidth - 3)
+	list = scroll(list, e.cursor, body)
+	details = scroll(details, selectedLine, body)
+	for i := 0; i < body; i++ {
+		left, right := "", ""
+		if i < len(list) {
+			left = list[i]
+		}
+		if i < len(details) {
+			right = details[i]
+		}
+		lines = append(lines, strings.TrimRight(truncate(pad(truncate(left, listWidth), listWidth)+" │ "+right, width), " "))
+	}
+
+	lines = append(lines, strings.Repeat("─", width))
+	switch {
+	case e.typing:
+		lines = append(lines, "tag (empty for all, ⏎ apply, esc cancel): "+e.input)
+	case e.status != "":
+		lines = append(lines, truncate(e.status, width))
+	default:
+		lines = append(lines, truncate(help, width))
+	}
+	return lines
+}
+
+//details return the lines describing the selected element and its relations, and the line of the relation under the cursor
+func (e *Explorer) details(width int) ([]string, int) {
+	v, ok := e.Selected()
+	if !ok {
+		return []string{"no element"}, 0
+	}
+	lines := []string{v.Path, "[" + kind(v) + "]"}
+	lines = append(lines, wrap(desc(v), width)...)
+	if t := tags(v); len(t) > 0 {
+		lines = append(lines, "tags: "+strings.Join(t, ", "))
+	}
+	selected := 0
+	rels := e.relations()
+	for i, r := range rels {
+		switch {
+		case i == 0 && r.outgoing:
+			lines = append(lines, "", "Outgoing")
+		case !r.outgoing && (i == 0 || rels[i-1].outgoing):
+			lines = append(lines, "", "Incoming")
+		}
+		marker := "  "
+		if e.focus && i == e.relCursor {
+			marker, selected = "▸ ", len(lines)
+		}
+		arrow := "▶"
+		if !r.outgoing {
+			arrow = "◀"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s  %s", marker, arrow, r.other, pointer(r.Relation)))
+	}
+	if len(rels) == 0 {
+		lines = append(lines, "", "no relations")
+	}
+	return lines, selected
+}
+
+//scroll return the window of lines of the given height showing the line at index
+func scroll(lines []string, index, height int) []string {
+	start := 0
+	if index >= height {
+		start = index - height + 1
+	}
+	if start > len(lines) {
+		return nil
+	}
+	if end := start + height; end < len(lines) {
+		return lines[start:end]
+	}
+	return lines[start:]
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func kind(v analyzer.Vertice) string {
+	switch v.Kind {
+	case analyzer.VerticeTypeUser:
+		if v.Entity.(model.User).External {
+			return "external user"
+		}
+		return "user"
+	case analyzer.VerticeTypeInternalSystem:
+		return "system"
+	case analyzer.VerticeTypeExternalSystem:
+		return "external system"
+	case analyzer.VerticeTypeContainer:
+		return withTech("container", v.Entity.(model.Container).Technology)
+	case analyzer.VerticeTypeExternalContainer:
+		return withTech("external container", v.Entity.(model.Container).Technology)
+	case analyzer.VerticeTypeComponent:
+		return withTech("component", v.Entity.(model.Component).Technology)
+	}
+	return "element"
+}
+
+func withTech(kind, technology string) string {
+	if technology == "" {
+		return kind
+	}
+	return kind + ": " + technology
+}
+
+func desc(v analyzer.Vertice) string {
+	switch e := v.Entity.(type) {
+	case model.User:
+		return e.Role
+	case model.InternalSystem:
+		return e.Desc
+	case model.ExternalSystem:
+		return e.Desc
+	case model.Container:
+		return e.Desc
+	case model.Component:
+		return e.Desc
+	}
+	return ""
+}
+
+func tags(v analyzer.Vertice) []string {
+	switch e := v.Entity.(type) {
+	case model.User:
+		return e.Tags
+	case model.InternalSystem:
+		return e.Tags
+	case model.ExternalSystem:
+		return e.Tags
+	case model.Container:
+		return e.Tags
+	case model.Component:
+		return e.Tags
+	}
+	return nil
+}
+
+//pointer format the pointer of a relation with its technology in brackets
+func pointer(r model.Relation) string {
+	if r.Technology == "" {
+		return r.Pointer
+	}
+	return r.Pointer + " [" + r.Technology + "]"
+}
+
+//wrap split a text in lines of at most the given width, breaking between words
+func wrap(text string, width int) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width < 1 {
+		return ""
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
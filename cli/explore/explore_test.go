@@ -0,0 +1,195 @@
+package explore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+var arc = model.ArcType{
+	App:   "test",
+	Users: []model.User{{Name: "customer", Role: "Buy things"}},
+	InternalSystems: []model.InternalSystem{
+		{
+			Name: "shop",
+			Desc: "Online shop",
+			Containers: []model.Container{
+				{
+					Name:       "api",
+					Technology: "golang",
+					Tags:       []string{"backend"},
+					Components: []model.Component{{Name: "orders"}, {Name: "carts", Tags: []string{"beta"}}},
+				},
+				{Name: "web", Technology: "react"},
+			},
+		},
+	},
+	ExternalSystems: []model.ExternalSystem{{Name: "payments", Desc: "Payment provider"}},
+	Relations: []model.Relation{
+		{Subject: "customer", Pointer: "buy", Object: "shop.web"},
+		{Subject: "shop.web", Pointer: "call", Technology: "https", Object: "shop.api"},
+		{Subject: "shop.api.orders", Pointer: "charge", Object: "payments"},
+	},
+}
+
+func newExplorer(t *testing.T) *Explorer {
+	data, err := arc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := analyzer.Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_LANDSCAPE,
+		Data:         data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(g)
+}
+
+func handle(e *Explorer, keys ...string) {
+	for _, key := range keys {
+		e.Handle(key)
+	}
+}
+
+func selected(e *Explorer) string {
+	v, _ := e.Selected()
+	return v.Path
+}
+
+func TestNavigation(t *testing.T) {
+	e := newExplorer(t)
+	if len(e.items) != 3 || selected(e) != "customer" {
+		t.Fatalf("expect the top level elements, get %+v", e.items)
+	}
+	handle(e, "down", "right")
+	if e.parent != "shop" || selected(e) != "shop.api" {
+		t.Errorf("expect the containers of shop, get %s in %s", selected(e), e.parent)
+	}
+	handle(e, "enter", "down")
+	if selected(e) != "shop.api.carts" {
+		t.Errorf("expect the second component of shop.api, get %s", selected(e))
+	}
+	handle(e, "right")
+	if e.status != "shop.api.carts has no children" {
+		t.Errorf("expect components to have no children, get status %q", e.status)
+	}
+	handle(e, "left", "left")
+	if e.parent != "" || selected(e) != "shop" {
+		t.Errorf("expect shop selected at the top level, get %s in %s", selected(e), e.parent)
+	}
+	if handle(e, "up"); selected(e) != "customer" || e.Handle("q") {
+		t.Error("expect q to quit")
+	}
+}
+
+func TestRelations(t *testing.T) {
+	e := newExplorer(t)
+	handle(e, "down")
+	rels := e.relations()
+	others := make([]string, 0)
+	for _, r := range rels {
+		others = append(others, r.other)
+	}
+	//relations of containers and components are rolled up to the systems as in the diagrams
+	if !reflect.DeepEqual(others, []string{"payments", "customer"}) || !rels[0].outgoing || rels[1].outgoing {
+		t.Errorf("expect shop to use payments and be used by customer, get %+v", rels)
+	}
+
+	handle(e, "right", "down", "tab")
+	if !e.focus {
+		t.Fatal("expect tab to focus the relations of shop.web")
+	}
+	handle(e, "enter")
+	if e.focus || e.parent != "shop" || selected(e) != "shop.api" {
+		t.Errorf("expect a jump to shop.api, get %s in %s", selected(e), e.parent)
+	}
+	handle(e, "tab")
+	//the relation of the orders component is rolled up to shop.api as in the container view
+	if rels := e.relations(); len(rels) != 2 || rels[0].other != "payments" || rels[1].other != "shop.web" {
+		t.Errorf("expect shop.api to use payments and be called by shop.web, get %+v", rels)
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	e := newExplorer(t)
+	handle(e, "t", "b", "e", "t", "a", "backspace", "a", "enter")
+	if e.tag != "beta" || len(e.items) != 1 || selected(e) != "shop" {
+		t.Fatalf("expect only shop holding a beta component, get %+v tagged %s", e.items, e.tag)
+	}
+	handle(e, "right", "right")
+	if len(e.items) != 1 || selected(e) != "shop.api.carts" {
+		t.Errorf("expect only the beta component, get %+v", e.items)
+	}
+	handle(e, "t", "esc")
+	if e.tag != "beta" {
+		t.Errorf("expect esc to keep the filter, get %s", e.tag)
+	}
+	handle(e, "/", "backspace", "backspace", "backspace", "backspace", "enter")
+	if e.tag != "" || len(e.items) != 2 || selected(e) != "shop.api.carts" {
+		t.Errorf("expect all components of shop.api with an empty tag, get %+v", e.items)
+	}
+}
+
+func TestRender(t *testing.T) {
+	e := newExplorer(t)
+	rendered := ""
+	e.Render = func(path string) (string, error) {
+		rendered = path
+		if path == "customer" {
+			return "", errors.New("unavailable")
+		}
+		return "http://viz/" + path, nil
+	}
+	handle(e, "r")
+	if rendered != "customer" || e.status != "unavailable" {
+		t.Errorf("expect the render error as status, get %q", e.status)
+	}
+	handle(e, "down", "r")
+	if e.status != "http://viz/shop" {
+		t.Errorf("expect the location of the render as status, get %q", e.status)
+	}
+}
+
+func TestView(t *testing.T) {
+	e := newExplorer(t)
+	handle(e, "down", "right", "down", "tab")
+	lines := e.View(80, 12)
+	if len(lines) != 12 {
+		t.Fatalf("expect 12 lines, get %d", len(lines))
+	}
+	view := strings.Join(lines, "\n")
+	for _, expect := range []string{
+		"arc explorer: test › shop",
+		"› web",
+		"  api ›",
+		"[container: react]",
+		"▸ ▶ shop.api  call [https]",
+		"  ◀ customer  buy",
+		help,
+	} {
+		if !strings.Contains(view, expect) {
+			t.Errorf("expect the view to contain %q, get\n%s", expect, view)
+		}
+	}
+	for _, line := range lines {
+		if n := len([]rune(line)); n > 80 {
+			t.Errorf("expect lines of at most 80 characters, get %d in %q", n, line)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("\x1b[A\x1bOBj\r\t\x7f\x1b\x03é"))
+	if expect := []string{"up", "down", "j", "enter", "tab", "backspace", "esc", "ctrl-c", "é"}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("expect %v, get %v", expect, keys)
+	}
+}
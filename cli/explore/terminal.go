@@ -0,0 +1,103 @@
+package explore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+//Run draw the explorer full screen on the terminal and handle the keys typed until it is quit
+func Run(e *Explorer, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer terminal.Restore(fd, state)
+	//switch to the alternate screen and hide the cursor, restoring both on exit
+	io.WriteString(out, "\x1b[?1049h\x1b[?25l")
+	defer io.WriteString(out, "\x1b[?25h\x1b[?1049l")
+
+	w := bufio.NewWriter(out)
+	buf := make([]byte, 64)
+	for {
+		width, height, err := terminal.GetSize(fd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		w.WriteString("\x1b[H")
+		w.WriteString(strings.Join(e.View(width, height), "\x1b[K\r\n"))
+		w.WriteString("\x1b[K\x1b[J")
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			if !e.Handle(key) {
+				return nil
+			}
+		}
+	}
+}
+
+//escapes map the escape sequences of the terminal keys to their names
+var escapes = map[string]string{
+	"\x1b[A": "up",
+	"\x1b[B": "down",
+	"\x1b[C": "right",
+	"\x1b[D": "left",
+	"\x1bOA": "up",
+	"\x1bOB": "down",
+	"\x1bOC": "right",
+	"\x1bOD": "left",
+}
+
+//parseKeys turn the bytes read from a raw terminal into the keys handled by the explorer
+func parseKeys(b []byte) []string {
+	keys := make([]string, 0)
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			matched := false
+			for seq, key := range escapes {
+				if strings.HasPrefix(string(b), seq) {
+					keys, b, matched = append(keys, key), b[len(seq):], true
+					break
+				}
+			}
+			if !matched {
+				keys, b = append(keys, "esc"), b[1:]
+				//the rest of an unknown sequence is skipped
+				if len(b) > 0 && (b[0] == '[' || b[0] == 'O') {
+					b = b[len(b):]
+				}
+			}
+			continue
+		}
+		switch b[0] {
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case 0x7f, 0x08:
+			keys = append(keys, "backspace")
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= ' ' {
+				keys = append(keys, string(r))
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
@@ -0,0 +1,335 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/koderizer/arc/model"
+	yaml "gopkg.in/yaml.v3"
+)
+
+//yamlErrorLine match the line given in the yaml parser errors
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+//element is an element defined in an arc file, with the range of its name
+type element struct {
+	Path       string
+	Kind       string
+	Desc       string
+	Technology string
+	Name       Range
+}
+
+//reference is a value referencing an element by path, in a relation or a group
+type reference struct {
+	Path  string
+	Range Range
+	//Systems only accept internal systems, as the systems of a group
+	System bool
+}
+
+//index hold the elements defined and referenced in an arc file
+type index struct {
+	elements    map[string]*element
+	order       []string
+	refs        []reference
+	diagnostics []Diagnostic
+	//fragment files are completed by other files, so unknown references are only warnings
+	fragment bool
+	//include tell whether the file include other arc files
+	include bool
+	//invalid tell whether the text is not valid yaml, eg while typing, so nothing is indexed
+	invalid bool
+}
+
+//newIndex parse the text of an arc file and index its elements and references
+func newIndex(text string) *index {
+	idx := &index{elements: make(map[string]*element)}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(text), doc); err != nil {
+		idx.invalid = true
+		line := 0
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			line--
+		}
+		idx.diagnostics = append(idx.diagnostics, Diagnostic{
+			Range:    Range{Start: Position{Line: line}, End: Position{Line: line + 1}},
+			Severity: SeverityError,
+			Message:  err.Error(),
+		})
+		return idx
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return idx
+	}
+	root := doc.Content[0]
+	idx.include = value(root, "include") != nil
+	idx.checkVersion(root)
+	for _, u := range items(value(root, "users")) {
+		idx.define("", u, "user", "")
+	}
+	for _, sys := range items(value(root, "internal-systems")) {
+		path := idx.define("", sys, "internal system", "")
+		idx.defineContainers(path, items(value(sys, "containers")), "container")
+	}
+	for _, sys := range items(value(root, "external-systems")) {
+		path := idx.define("", sys, "external system", "")
+		idx.defineContainers(path, items(value(sys, "containers")), "external container")
+	}
+	if system := value(root, "system"); system != nil || value(root, "containers") != nil || value(root, "container") != nil {
+		idx.fragment = true
+		if system != nil && system.Kind == yaml.ScalarNode {
+			idx.add(&element{Path: system.Value, Kind: "internal system", Desc: scalar(root, "desc"), Name: nodeRange(system)})
+			containers := items(value(root, "containers"))
+			if c := value(root, "container"); c != nil {
+				containers = append(containers, c)
+			}
+			idx.defineContainers(system.Value, containers, "container")
+		}
+	}
+	var groups func(nodes []*yaml.Node)
+	groups = func(nodes []*yaml.Node) {
+		for _, g := range nodes {
+			for _, sys := range items(value(g, "systems")) {
+				if sys.Kind == yaml.ScalarNode {
+					idx.refs = append(idx.refs, reference{Path: sys.Value, Range: nodeRange(sys), System: true})
+				}
+			}
+			groups(items(value(g, "groups")))
+		}
+	}
+	groups(items(value(root, "groups")))
+	for _, rel := range items(value(root, "relations")) {
+		for _, key := range []string{"s", "o"} {
+			end := value(rel, key)
+			if end == nil || end.Kind != yaml.ScalarNode || end.Value == "" {
+				idx.diagnostics = append(idx.diagnostics, Diagnostic{
+					Range:    nodeRange(rel),
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("relation without %s", key),
+				})
+				continue
+			}
+			idx.refs = append(idx.refs, reference{Path: end.Value, Range: nodeRange(end)})
+		}
+	}
+	return idx
+}
+
+//checkVersion report a version newer than the supported one
+func (idx *index) checkVersion(root *yaml.Node) {
+	v := value(root, "version")
+	if v == nil {
+		return
+	}
+	if n, err := strconv.Atoi(v.Value); err != nil || n < 1 || n > model.Version {
+		idx.diagnostics = append(idx.diagnostics, Diagnostic{
+			Range:    nodeRange(v),
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("unsupported version %s, the latest version is %d", v.Value, model.Version),
+		})
+	}
+}
+
+//define index the element of a mapping node under the parent path, and return its path
+func (idx *index) define(parent string, node *yaml.Node, kind, technology string) string {
+	name := value(node, "name")
+	if name == nil || name.Kind != yaml.ScalarNode || name.Value == "" {
+		idx.diagnostics = append(idx.diagnostics, Diagnostic{
+			Range:    nodeRange(node),
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s without name", kind),
+		})
+		return ""
+	}
+	path := name.Value
+	if parent != "" {
+		path = parent + "." + name.Value
+	}
+	desc := scalar(node, "desc")
+	if desc == "" {
+		desc = scalar(node, "role")
+	}
+	idx.add(&element{Path: path, Kind: kind, Desc: desc, Technology: technology, Name: nodeRange(name)})
+	return path
+}
+
+//defineContainers index the containers of a system and their components
+func (idx *index) defineContainers(system string, containers []*yaml.Node, kind string) {
+	if system == "" {
+		return
+	}
+	for _, c := range containers {
+		path := idx.define(system, c, kind, scalar(c, "technology"))
+		if path == "" {
+			continue
+		}
+		for _, com := range items(value(c, "components")) {
+			idx.define(path, com, "component", scalar(com, "technology"))
+		}
+	}
+}
+
+//add index an element, reporting duplicated definitions
+func (idx *index) add(e *element) {
+	if _, ok := idx.elements[e.Path]; ok {
+		idx.diagnostics = append(idx.diagnostics, Diagnostic{
+			Range:    e.Name,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("duplicated definition of %s", e.Path),
+		})
+		return
+	}
+	idx.elements[e.Path] = e
+	idx.order = append(idx.order, e.Path)
+}
+
+//validate return the diagnostics of the file, references being checked against the elements of the file and the known ones
+func (idx *index) validate(known map[string]*element) []Diagnostic {
+	diagnostics := append([]Diagnostic{}, idx.diagnostics...)
+	for _, ref := range idx.refs {
+		e, ok := idx.elements[ref.Path]
+		if !ok {
+			e, ok = known[ref.Path]
+		}
+		switch {
+		case !ok:
+			severity := SeverityError
+			if idx.fragment || idx.include {
+				severity = SeverityWarning
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    ref.Range,
+				Severity: severity,
+				Message:  fmt.Sprintf("unknown element %s", ref.Path),
+			})
+		case ref.System && e.Kind != "internal system":
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    ref.Range,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%s is a %s, groups only hold internal systems", ref.Path, e.Kind),
+			})
+		}
+	}
+	return diagnostics
+}
+
+//at return the path of the element defined or referenced at the position, a reference giving the path up to the segment under the cursor
+func (idx *index) at(pos Position) string {
+	for _, path := range idx.order {
+		if idx.elements[path].Name.contains(pos) {
+			return path
+		}
+	}
+	for _, ref := range idx.refs {
+		if !ref.Range.contains(pos) {
+			continue
+		}
+		segments := strings.Split(ref.Path, ".")
+		offset := ref.Range.Start.Character
+		for i, segment := range segments {
+			offset += utf16Len(segment)
+			if pos.Character <= offset || i == len(segments)-1 {
+				return strings.Join(segments[:i+1], ".")
+			}
+			offset++
+		}
+	}
+	return ""
+}
+
+//references return the ranges naming the element of the given path in references, as the whole reference or one of its segments
+func (idx *index) references(path string) []Range {
+	ranges := make([]Range, 0)
+	depth := strings.Count(path, ".")
+	for _, ref := range idx.refs {
+		if ref.Path != path && !strings.HasPrefix(ref.Path, path+".") {
+			continue
+		}
+		segments := strings.Split(ref.Path, ".")
+		start := ref.Range.Start.Character
+		for _, segment := range segments[:depth] {
+			start += utf16Len(segment) + 1
+		}
+		ranges = append(ranges, Range{
+			Start: Position{Line: ref.Range.Start.Line, Character: start},
+			End:   Position{Line: ref.Range.Start.Line, Character: start + utf16Len(segments[depth])},
+		})
+	}
+	return ranges
+}
+
+//nodeRange return the range of the value of a scalar node, or of the first line of another node
+func nodeRange(n *yaml.Node) Range {
+	start := Position{Line: n.Line - 1, Character: n.Column - 1}
+	if n.Kind != yaml.ScalarNode {
+		return Range{Start: start, End: Position{Line: start.Line, Character: start.Character + 1}}
+	}
+	if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		start.Character++
+	}
+	return Range{Start: start, End: Position{Line: start.Line, Character: start.Character + utf16Len(n.Value)}}
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+//value return the value node of the given key in a mapping node
+func value(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+//scalar return the text of the given key in a mapping node
+func scalar(node *yaml.Node, key string) string {
+	if v := value(node, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+//items return the items of a sequence node
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+//knownElements index the elements of a loaded arc, eg merged with the files it includes
+func knownElements(arc *model.ArcType) map[string]*element {
+	known := make(map[string]*element)
+	for _, u := range arc.Users {
+		known[u.Name] = &element{Path: u.Name, Kind: "user", Desc: u.Role}
+	}
+	containers := func(system string, cs []model.Container, kind string) {
+		for _, c := range cs {
+			path := system + "." + c.Name
+			known[path] = &element{Path: path, Kind: kind, Desc: c.Desc, Technology: c.Technology}
+			for _, com := range c.Components {
+				known[path+"."+com.Name] = &element{Path: path + "." + com.Name, Kind: "component", Desc: com.Desc, Technology: com.Technology}
+			}
+		}
+	}
+	for _, sys := range arc.InternalSystems {
+		known[sys.Name] = &element{Path: sys.Name, Kind: "internal system", Desc: sys.Desc}
+		containers(sys.Name, sys.Containers, "container")
+	}
+	for _, sys := range arc.ExternalSystems {
+		known[sys.Name] = &element{Path: sys.Name, Kind: "external system", Desc: sys.Desc}
+		containers(sys.Name, sys.Containers, "external container")
+	}
+	return known
+}
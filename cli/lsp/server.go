@@ -0,0 +1,391 @@
+//Package lsp implement a language server for arc yaml files, speaking the Language Server Protocol over a stream
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/koderizer/arc/model"
+)
+
+//relationEnd match a relation subject or object being typed at the end of a line, eg "- { s: arc-intel.api."
+var relationEnd = regexp.MustCompile(`(?:^|[\s{,])[so]\s*:\s*["']?([\w.\-]*)$`)
+
+//validElementName match the name an element can be renamed to
+var validElementName = regexp.MustCompile(`^[^.\s]+$`)
+
+//Server is a language server for arc yaml files
+type Server struct {
+	//Load return the saved arc of a file merged with the files it includes, or the workspace, to resolve the elements defined in other files
+	Load func(filename string) (*model.ArcType, error)
+
+	in   *bufio.Reader
+	out  io.Writer
+	mu   sync.Mutex
+	docs map[string]*document
+}
+
+//document is an open arc file
+type document struct {
+	text  string
+	index *index
+	//valid is the index of the last valid text, to complete while the text is being typed
+	valid *index
+	known map[string]*element
+}
+
+//NewServer return a language server reading requests from in and writing responses to out
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, docs: make(map[string]*document)}
+}
+
+//Serve handle requests until the client exit or the input is closed
+func (s *Server) Serve() error {
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		result, rpcErr := s.handle(msg)
+		if msg.ID == nil {
+			continue
+		}
+		var reply interface{} = &response{JSONRPC: "2.0", ID: msg.ID, Result: result}
+		if rpcErr != nil {
+			reply = &message{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr}
+		}
+		if err := s.write(reply); err != nil {
+			return err
+		}
+	}
+}
+
+//read read a message framed by a Content-Length header
+func (s *Server) read() (*message, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %v", err)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %v", err)
+	}
+	return msg, nil
+}
+
+//write send a message framed by a Content-Length header
+func (s *Server) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = s.out.Write(body)
+	return err
+}
+
+//handle dispatch a message and return the result of a request
+func (s *Server) handle(msg *message) (interface{}, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1,
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{"."}},
+				"definitionProvider": true,
+				"referencesProvider": true,
+				"renameProvider":     true,
+				"hoverProvider":      true,
+			},
+			"serverInfo": map[string]string{"name": "arcli"},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if err := json.Unmarshal(msg.Params, &params); err == nil {
+			s.update(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if err := json.Unmarshal(msg.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didSave":
+		params := struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}{}
+		if err := json.Unmarshal(msg.Params, &params); err == nil {
+			if doc, ok := s.docs[params.TextDocument.URI]; ok {
+				s.update(params.TextDocument.URI, doc.text)
+			}
+		}
+	case "textDocument/didClose":
+		params := struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}{}
+		if err := json.Unmarshal(msg.Params, &params); err == nil {
+			delete(s.docs, params.TextDocument.URI)
+		}
+	case "textDocument/completion":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.completion(params), nil
+	case "textDocument/definition":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.definition(params), nil
+	case "textDocument/references":
+		params := referenceParams{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.references(params), nil
+	case "textDocument/rename":
+		params := renameParams{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.rename(params)
+	case "textDocument/hover":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.hover(params), nil
+	default:
+		if msg.ID != nil {
+			return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+//update index the new text of a document and publish its diagnostics
+func (s *Server) update(uri, text string) {
+	doc := &document{text: text, index: newIndex(text), known: map[string]*element{}}
+	doc.valid = doc.index
+	if previous, ok := s.docs[uri]; ok && doc.index.invalid {
+		doc.valid = previous.valid
+	}
+	var loadErr error
+	if filename := uriPath(uri); s.Load != nil && filename != "" {
+		arc, err := s.Load(filename)
+		if err == nil {
+			doc.known = knownElements(arc)
+		} else {
+			loadErr = err
+		}
+	}
+	s.docs[uri] = doc
+	diagnostics := doc.index.validate(doc.known)
+	if loadErr != nil {
+		diagnostics = append(diagnostics, Diagnostic{Severity: SeverityWarning, Message: loadErr.Error()})
+	}
+	for i := range diagnostics {
+		diagnostics[i].Source = "arcli"
+	}
+	s.write(&message{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: mustMarshal(publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})})
+}
+
+//element return the element of the given path, defined in the document or in the files it includes
+func (d *document) element(path string) (*element, bool) {
+	if e, ok := d.index.elements[path]; ok {
+		return e, true
+	}
+	e, ok := d.known[path]
+	return e, ok
+}
+
+//completion propose the children of the element path being typed in a relation subject or object
+func (s *Server) completion(params textDocumentPositionParams) []CompletionItem {
+	items := make([]CompletionItem, 0)
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return items
+	}
+	lines := strings.Split(doc.text, "\n")
+	if params.Position.Line >= len(lines) {
+		return items
+	}
+	line := []rune(lines[params.Position.Line])
+	if params.Position.Character < len(line) {
+		line = line[:params.Position.Character]
+	}
+	m := relationEnd.FindStringSubmatch(string(line))
+	if m == nil {
+		return items
+	}
+	parent, typed := "", m[1]
+	if i := strings.LastIndex(m[1], "."); i >= 0 {
+		parent, typed = m[1][:i], m[1][i+1:]
+	}
+	replace := Range{
+		Start: Position{Line: params.Position.Line, Character: params.Position.Character - utf16Len(typed)},
+		End:   params.Position,
+	}
+	seen := make(map[string]bool)
+	for _, elements := range []map[string]*element{doc.valid.elements, doc.known} {
+		for path, e := range elements {
+			name := path
+			if parent != "" {
+				if !strings.HasPrefix(path, parent+".") {
+					continue
+				}
+				name = strings.TrimPrefix(path, parent+".")
+			}
+			if strings.Contains(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			items = append(items, CompletionItem{
+				Label:         name,
+				Kind:          completionKind(e.Kind),
+				Detail:        e.Kind,
+				Documentation: e.Desc,
+				TextEdit:      &TextEdit{Range: replace, NewText: name},
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+func completionKind(kind string) int {
+	switch kind {
+	case "user":
+		return completionReference
+	case "internal system", "external system":
+		return completionModule
+	case "component":
+		return completionField
+	default:
+		return completionClass
+	}
+}
+
+//definition return the location of the name of the element under the cursor
+func (s *Server) definition(params textDocumentPositionParams) []Location {
+	locations := make([]Location, 0)
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return locations
+	}
+	if e, ok := doc.index.elements[doc.index.at(params.Position)]; ok {
+		locations = append(locations, Location{URI: params.TextDocument.URI, Range: e.Name})
+	}
+	return locations
+}
+
+//references return the locations referencing the element under the cursor
+func (s *Server) references(params referenceParams) []Location {
+	locations := make([]Location, 0)
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return locations
+	}
+	path := doc.index.at(params.Position)
+	if path == "" {
+		return locations
+	}
+	if e, ok := doc.index.elements[path]; ok && params.Context.IncludeDeclaration {
+		locations = append(locations, Location{URI: params.TextDocument.URI, Range: e.Name})
+	}
+	for _, r := range doc.index.references(path) {
+		locations = append(locations, Location{URI: params.TextDocument.URI, Range: r})
+	}
+	return locations
+}
+
+//rename rename the element under the cursor in its definition and all references
+func (s *Server) rename(params renameParams) (interface{}, *responseError) {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	if !validElementName.MatchString(params.NewName) {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid element name %q", params.NewName)}
+	}
+	path := doc.index.at(params.Position)
+	if path == "" {
+		return nil, nil
+	}
+	edits := make([]TextEdit, 0)
+	if e, ok := doc.index.elements[path]; ok {
+		edits = append(edits, TextEdit{Range: e.Name, NewText: params.NewName})
+	}
+	for _, r := range doc.index.references(path) {
+		edits = append(edits, TextEdit{Range: r, NewText: params.NewName})
+	}
+	return WorkspaceEdit{Changes: map[string][]TextEdit{params.TextDocument.URI: edits}}, nil
+}
+
+//hover describe the element under the cursor
+func (s *Server) hover(params textDocumentPositionParams) *Hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	path := doc.index.at(params.Position)
+	e, ok := doc.element(path)
+	if !ok {
+		return nil
+	}
+	text := fmt.Sprintf("**%s** (%s)", e.Path, e.Kind)
+	if e.Technology != "" {
+		text += fmt.Sprintf(" [%s]", e.Technology)
+	}
+	if e.Desc != "" {
+		text += "\n\n" + e.Desc
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: text}}
+}
+
+//uriPath return the file path of a file URI
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,233 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+)
+
+const testURI = "file:///tmp/arc.yaml"
+
+var testArc = `app: test
+users:
+  - name: customer
+    role: Buy things
+internal-systems:
+  - name: arc-intel
+    desc: Intelligence of arc
+    containers:
+      - name: api
+        technology: golang
+        components:
+          - name: inspector
+            desc: Inspect arcs
+          - name: update
+groups:
+  - name: core
+    systems: [arc-intel]
+relations:
+  - { s: customer, p: use, o: arc-intel.api.inspector }
+  - { s: arc-intel.api.update, p: call, o: arc-intel.api.inspector }
+`
+
+func testServer(text string) *Server {
+	s := NewServer(&bytes.Buffer{}, &bytes.Buffer{})
+	s.update(testURI, text)
+	return s
+}
+
+//position return the position of the nth occurrence of the substring in the text, plus an offset
+func position(text, sub string, nth, offset int) Position {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		for col := 0; col < len(line); {
+			j := strings.Index(line[col:], sub)
+			if j < 0 {
+				break
+			}
+			if nth == 0 {
+				return Position{Line: i, Character: col + j + offset}
+			}
+			nth--
+			col += j + 1
+		}
+	}
+	panic(fmt.Sprintf("%s not found", sub))
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		messages []string
+	}{
+		{"valid", testArc, nil},
+		{"unknown element", testArc + "  - { s: customer, p: use, o: arc-intel.web }\n", []string{"unknown element arc-intel.web"}},
+		{"missing object", testArc + "  - { s: customer, p: use }\n", []string{"relation without o"}},
+		{"duplicated", strings.Replace(testArc, "name: update", "name: inspector", 1), []string{"duplicated definition of arc-intel.api.inspector", "unknown element arc-intel.api.update"}},
+		{"group of user", strings.Replace(testArc, "[arc-intel]", "[customer]", 1), []string{"customer is a user, groups only hold internal systems"}},
+		{"version", "version: 9\n" + testArc, []string{fmt.Sprintf("unsupported version 9, the latest version is %d", model.Version)}},
+		{"yaml", "app: [test\n", []string{"yaml: line 1: did not find expected ',' or ']'"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := newIndex(tt.text).validate(nil)
+			messages := make([]string, 0)
+			for _, d := range diagnostics {
+				messages = append(messages, d.Message)
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.messages, "\n") {
+				t.Errorf("expected diagnostics %v, got %v", tt.messages, messages)
+			}
+		})
+	}
+}
+
+func TestFragmentDiagnostics(t *testing.T) {
+	fragment := "system: arc-intel\ncontainers:\n  - name: web\nrelations:\n  - { s: customer, p: use, o: arc-intel.web }\n"
+	diagnostics := newIndex(fragment).validate(nil)
+	if len(diagnostics) != 1 || diagnostics[0].Severity != SeverityWarning {
+		t.Fatalf("expected a warning for the element defined elsewhere, got %v", diagnostics)
+	}
+	known := map[string]*element{"customer": {Path: "customer", Kind: "user"}}
+	if diagnostics := newIndex(fragment).validate(known); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics with known elements, got %v", diagnostics)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	text := testArc + "  - { s: arc-intel.api.\n"
+	s := testServer(testArc)
+	s.update(testURI, text)
+	pos := position(text, "arc-intel.api.", 3, len("arc-intel.api."))
+	items := s.completion(textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: pos})
+	labels := make([]string, 0)
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	if strings.Join(labels, ",") != "inspector,update" {
+		t.Errorf("expected inspector and update, got %v", labels)
+	}
+
+	text = testArc + "  - { s: customer, p: use, o: ar\n"
+	s.update(testURI, text)
+	pos = position(text, "o: ar", 2, len("o: ar"))
+	items = s.completion(textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: pos})
+	if len(items) != 2 || items[0].Label != "arc-intel" || items[1].Label != "customer" {
+		t.Fatalf("expected the top level elements, got %v", items)
+	}
+	if items[0].TextEdit.Range.Start.Character != pos.Character-2 {
+		t.Errorf("expected the typed text to be replaced, got %v", items[0].TextEdit.Range)
+	}
+
+	pos = position(text, "name: customer", 0, 0)
+	if items := s.completion(textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: testURI}, Position: pos}); len(items) != 0 {
+		t.Errorf("expected no completion outside relations, got %v", items)
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	s := testServer(testArc)
+	doc := textDocumentIdentifier{URI: testURI}
+	inspector := position(testArc, "inspector", 0, 2)
+	api := position(testArc, "api", 1, 1)
+
+	definition := s.definition(textDocumentPositionParams{TextDocument: doc, Position: api})
+	if len(definition) != 1 || definition[0].Range != s.docs[testURI].index.elements["arc-intel.api"].Name {
+		t.Errorf("expected the definition of arc-intel.api, got %v", definition)
+	}
+
+	params := referenceParams{}
+	params.TextDocument, params.Position = doc, inspector
+	if refs := s.references(params); len(refs) != 2 {
+		t.Errorf("expected 2 references of inspector, got %v", refs)
+	}
+	params.Position = api
+	params.Context.IncludeDeclaration = true
+	if refs := s.references(params); len(refs) != 4 {
+		t.Errorf("expected the definition and 3 references of api, got %v", refs)
+	}
+
+	hover := s.hover(textDocumentPositionParams{TextDocument: doc, Position: inspector})
+	if hover == nil || hover.Contents.Value != "**arc-intel.api.inspector** (component)\n\nInspect arcs" {
+		t.Errorf("unexpected hover %v", hover)
+	}
+}
+
+func TestRename(t *testing.T) {
+	s := testServer(testArc)
+	params := renameParams{NewName: "intel"}
+	params.TextDocument.URI, params.Position = testURI, position(testArc, "arc-intel", 0, 0)
+	result, rpcErr := s.rename(params)
+	if rpcErr != nil {
+		t.Fatal(rpcErr.Message)
+	}
+	renamed := applyEdits(testArc, result.(WorkspaceEdit).Changes[testURI])
+	if strings.Contains(renamed, "arc-intel") {
+		t.Errorf("expected all occurrences of arc-intel to be renamed, got\n%s", renamed)
+	}
+	if diagnostics := newIndex(renamed).validate(nil); len(diagnostics) != 0 {
+		t.Errorf("expected the renamed arc to be valid, got %v", diagnostics)
+	}
+	params.NewName = "a.b"
+	if _, rpcErr := s.rename(params); rpcErr == nil {
+		t.Error("expected names with dots to be rejected")
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := &bytes.Buffer{}
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q,"text":%q}}}`, testURI, testArc+"  - { s: nobody, p: use, o: customer }\n"),
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	}
+	for _, r := range requests {
+		fmt.Fprintf(in, "Content-Length: %d\r\n\r\n%s", len(r), r)
+	}
+	out := &bytes.Buffer{}
+	if err := NewServer(in, out).Serve(); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{in: bufio.NewReader(out)}
+	replies := make([]map[string]interface{}, 0)
+	for {
+		msg, err := s.read()
+		if err != nil {
+			break
+		}
+		reply := make(map[string]interface{})
+		data, _ := json.Marshal(msg)
+		json.Unmarshal(data, &reply)
+		replies = append(replies, reply)
+	}
+	if len(replies) != 3 {
+		t.Fatalf("expected 3 messages, got %v", replies)
+	}
+	if replies[1]["method"] != "textDocument/publishDiagnostics" || !strings.Contains(fmt.Sprint(replies[1]["params"]), "unknown element nobody") {
+		t.Errorf("expected the diagnostics of the document, got %v", replies[1])
+	}
+}
+
+//applyEdits apply text edits, which do not overlap and hold on a single line, to the text
+func applyEdits(text string, edits []TextEdit) string {
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].Range.Start.Line != edits[j].Range.Start.Line {
+			return edits[i].Range.Start.Line > edits[j].Range.Start.Line
+		}
+		return edits[i].Range.Start.Character > edits[j].Range.Start.Character
+	})
+	lines := strings.Split(text, "\n")
+	for _, e := range edits {
+		line := lines[e.Range.Start.Line]
+		lines[e.Range.Start.Line] = line[:e.Range.Start.Character] + e.NewText + line[e.Range.End.Character:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,17 +1,20 @@
 module github.com/koderizer/arc
 
-go 1.14
+go 1.16
 
 require (
 	github.com/golang/protobuf v1.4.1
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.0.0
 	github.com/spf13/viper v1.6.3
 	github.com/yourbasic/graph v0.0.0-20170921192928-40eb135c0b26
+	golang.org/x/crypto v0.0.0-20200414173820-0848c9571904
 	golang.org/x/net v0.0.0-20200425230154-ff2c4b7c35a0
 	google.golang.org/grpc v1.29.1
 	gopkg.in/yaml.v2 v2.2.8
+	gopkg.in/yaml.v3 v3.0.1
 	helm.sh/helm/v3 v3.2.1
 	k8s.io/api v0.18.3
 	k8s.io/apiextensions-apiserver v0.18.3
@@ -0,0 +1,46 @@
+package model
+
+//FindGroup look up a group by name at any level of nesting
+func (a ArcType) FindGroup(name string) (Group, bool) {
+	return findGroup(a.Groups, name)
+}
+
+func findGroup(groups []Group, name string) (Group, bool) {
+	for _, g := range groups {
+		if g.Name == name {
+			return g, true
+		}
+		if found, ok := findGroup(g.Groups, name); ok {
+			return found, true
+		}
+	}
+	return Group{}, false
+}
+
+//AllSystems return the names of the systems of the group and of all its nested groups
+func (g Group) AllSystems() []string {
+	systems := append([]string{}, g.Systems...)
+	for _, sub := range g.Groups {
+		systems = append(systems, sub.AllSystems()...)
+	}
+	return systems
+}
+
+//PruneGroups return a copy of the groups holding only the kept systems, groups left empty are dropped
+func PruneGroups(groups []Group, keep map[string]bool) []Group {
+	pruned := make([]Group, 0)
+	for _, g := range groups {
+		systems := make([]string, 0)
+		for _, sys := range g.Systems {
+			if keep[sys] {
+				systems = append(systems, sys)
+			}
+		}
+		g.Systems = systems
+		g.Groups = PruneGroups(g.Groups, keep)
+		if len(g.Systems) > 0 || len(g.Groups) > 0 {
+			pruned = append(pruned, g)
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+//Kinds of container, setting the shape they are drawn with
+const (
+	ContainerService   = "service"
+	ContainerWebApp    = "webapp"
+	ContainerDatabase  = "database"
+	ContainerQueue     = "queue"
+	ContainerFileStore = "filestore"
+	ContainerBatch     = "batch"
+)
+
+//containerKinds map the accepted spellings of an explicit kind to the kind
+var containerKinds = map[string]string{
+	"service":    ContainerService,
+	"webapp":     ContainerWebApp,
+	"web app":    ContainerWebApp,
+	"web-app":    ContainerWebApp,
+	"database":   ContainerDatabase,
+	"db":         ContainerDatabase,
+	"queue":      ContainerQueue,
+	"topic":      ContainerQueue,
+	"filestore":  ContainerFileStore,
+	"file store": ContainerFileStore,
+	"file-store": ContainerFileStore,
+	"batch":      ContainerBatch,
+	"batch job":  ContainerBatch,
+	"batch-job":  ContainerBatch,
+	"job":        ContainerBatch,
+}
+
+//kindHints map the names of technologies that leave no doubt about the kind of a container,
+//generic words and technologies of several uses, like redis, are left to an explicit kind
+var kindHints = map[string]string{
+	"postgres": ContainerDatabase, "postgresql": ContainerDatabase, "mysql": ContainerDatabase, "mariadb": ContainerDatabase,
+	"sqlite": ContainerDatabase, "mssql": ContainerDatabase, "dgraph": ContainerDatabase, "mongodb": ContainerDatabase,
+	"cassandra": ContainerDatabase, "cockroachdb": ContainerDatabase, "dynamodb": ContainerDatabase, "neo4j": ContainerDatabase,
+	"kafka": ContainerQueue, "rabbitmq": ContainerQueue, "nats": ContainerQueue, "sqs": ContainerQueue, "activemq": ContainerQueue,
+	"kinesis": ContainerQueue, "pulsar": ContainerQueue, "mqtt": ContainerQueue,
+	"s3": ContainerFileStore, "gcs": ContainerFileStore, "minio": ContainerFileStore, "nfs": ContainerFileStore,
+	"react": ContainerWebApp, "angular": ContainerWebApp, "vue": ContainerWebApp, "svelte": ContainerWebApp,
+	"cronjob": ContainerBatch, "spark": ContainerBatch, "airflow": ContainerBatch,
+}
+
+//kindSeparators split a runtime or technology description into words
+var kindSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+//ResolveKind return the kind of the container: its explicit kind if valid,
+//otherwise the kind revealed by a word of its runtime then its technology naming a well known technology, a service by default
+func (c Container) ResolveKind() string {
+	if kind, ok := containerKinds[strings.ToLower(strings.TrimSpace(c.Kind))]; ok {
+		return kind
+	}
+	for _, hint := range []string{c.Runtime, c.Technology} {
+		for _, word := range kindSeparators.Split(strings.ToLower(hint), -1) {
+			if kind, ok := kindHints[word]; ok {
+				return kind
+			}
+		}
+	}
+	return ContainerService
+}
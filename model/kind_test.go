@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestResolveKind(t *testing.T) {
+	tests := []struct {
+		container Container
+		kind      string
+	}{
+		{Container{Kind: "Batch Job", Technology: "postgres"}, ContainerBatch},
+		{Container{Kind: "unknown", Technology: "kafka"}, ContainerQueue},
+		{Container{Runtime: "aws-s3", Technology: "postgres"}, ContainerFileStore},
+		{Container{Technology: "PostgreSQL 13"}, ContainerDatabase},
+		{Container{Runtime: "k8s-cronjob"}, ContainerBatch},
+		{Container{Technology: "react spa"}, ContainerWebApp},
+		//generic words and technologies of several uses do not reveal a kind
+		{Container{Technology: "redis"}, ContainerService},
+		{Container{Technology: "cloud storage"}, ContainerService},
+		{Container{Runtime: "job runner"}, ContainerService},
+		{Container{Runtime: "database"}, ContainerService},
+		//only whole words are hints
+		{Container{Technology: "mypostgres"}, ContainerService},
+		{Container{Technology: "amazon-sqs"}, ContainerQueue},
+		{Container{}, ContainerService},
+	}
+	for i, test := range tests {
+		if kind := test.container.ResolveKind(); kind != test.kind {
+			t.Errorf("Test %d: expect %+v to be a %s, get %s", i, test.container, test.kind, kind)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//ConflictError report an element defined differently by two merged arcs
+type ConflictError struct {
+	//Kind of the element: user, internal system, external system, container, component or group
+	Kind string
+	//Path of the element, eg system.container for a container
+	Path string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting definitions of %s %s", e.Kind, e.Path)
+}
+
+//Merge add the elements of another arc, typically read from an included file, to this one.
+//An element defined in both must not conflict: systems and containers defined twice are completed field by field
+//and keep the union of their containers and components, any other element must be identical.
+//The application, description and styles of this arc take precedence.
+func (a *ArcType) Merge(o ArcType) error {
+	if a.App == "" {
+		a.App = o.App
+	}
+	if a.Desc == "" {
+		a.Desc = o.Desc
+	}
+	if err := checkKinds(*a, o); err != nil {
+		return err
+	}
+	styles := o.Styles
+	styles.Merge(a.Styles)
+	a.Styles = styles
+	for _, user := range o.Users {
+		i := findUser(a.Users, user.Name)
+		if i < 0 {
+			a.Users = append(a.Users, user)
+		} else if !reflect.DeepEqual(a.Users[i], user) {
+			return &ConflictError{Kind: "user", Path: user.Name}
+		}
+	}
+	for _, sys := range o.InternalSystems {
+		i := findInternalSystem(a.InternalSystems, sys.Name)
+		if i < 0 {
+			a.InternalSystems = append(a.InternalSystems, sys)
+			continue
+		}
+		merged := &a.InternalSystems[i]
+		if !sameText(&merged.Role, sys.Role) || !sameText(&merged.Desc, sys.Desc) || !sameTags(&merged.Tags, sys.Tags) {
+			return &ConflictError{Kind: "internal system", Path: sys.Name}
+		}
+		containers, err := mergeContainers(sys.Name, merged.Containers, sys.Containers)
+		if err != nil {
+			return err
+		}
+		merged.Containers = containers
+	}
+	for _, sys := range o.ExternalSystems {
+		i := findExternalSystem(a.ExternalSystems, sys.Name)
+		if i < 0 {
+			a.ExternalSystems = append(a.ExternalSystems, sys)
+			continue
+		}
+		merged := &a.ExternalSystems[i]
+		if !sameText(&merged.Role, sys.Role) || !sameText(&merged.Desc, sys.Desc) || !sameTags(&merged.Tags, sys.Tags) {
+			return &ConflictError{Kind: "external system", Path: sys.Name}
+		}
+		containers, err := mergeContainers(sys.Name, merged.Containers, sys.Containers)
+		if err != nil {
+			return err
+		}
+		merged.Containers = containers
+	}
+	for _, group := range o.Groups {
+		if existing, ok := findGroup(a.Groups, group.Name); !ok {
+			a.Groups = append(a.Groups, group)
+		} else if !reflect.DeepEqual(existing, group) {
+			return &ConflictError{Kind: "group", Path: group.Name}
+		}
+	}
+	for _, rel := range o.Relations {
+		found := false
+		for _, r := range a.Relations {
+			if reflect.DeepEqual(r, rel) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a.Relations = append(a.Relations, rel)
+		}
+	}
+	return nil
+}
+
+//checkKinds check that no name is given to a user, an internal system and an external system across both arcs
+func checkKinds(a, o ArcType) error {
+	kinds := make(map[string]string)
+	for _, arc := range []ArcType{a, o} {
+		names := make(map[string]string)
+		for _, u := range arc.Users {
+			names[u.Name] = "user"
+		}
+		for _, s := range arc.InternalSystems {
+			names[s.Name] = "internal system"
+		}
+		for _, s := range arc.ExternalSystems {
+			names[s.Name] = "external system"
+		}
+		for name, kind := range names {
+			if k, ok := kinds[name]; ok && k != kind {
+				return &ConflictError{Kind: k + " and " + kind, Path: name}
+			}
+			kinds[name] = kind
+		}
+	}
+	return nil
+}
+
+//mergeContainers return the union of two lists of containers of a system.
+//A container defined in both is completed field by field and keep the union of its components, which must be identical.
+func mergeContainers(system string, containers, others []Container) ([]Container, error) {
+	for _, c := range others {
+		i := -1
+		for j := range containers {
+			if containers[j].Name == c.Name {
+				i = j
+			}
+		}
+		if i < 0 {
+			containers = append(containers, c)
+			continue
+		}
+		merged := &containers[i]
+		path := system + "." + c.Name
+		if !sameText(&merged.Role, c.Role) || !sameText(&merged.Desc, c.Desc) || !sameText(&merged.Runtime, c.Runtime) ||
+			!sameText(&merged.Technology, c.Technology) || !sameText(&merged.Kind, c.Kind) || !sameText(&merged.Icon, c.Icon) ||
+			!sameTags(&merged.Tags, c.Tags) {
+			return nil, &ConflictError{Kind: "container", Path: path}
+		}
+		for _, com := range c.Components {
+			found := false
+			for _, existing := range merged.Components {
+				if existing.Name != com.Name {
+					continue
+				}
+				if !reflect.DeepEqual(existing, com) {
+					return nil, &ConflictError{Kind: "component", Path: path + "." + com.Name}
+				}
+				found = true
+			}
+			if !found {
+				merged.Components = append(merged.Components, com)
+			}
+		}
+	}
+	return containers, nil
+}
+
+//sameText check that two definitions of a text agree regardless of surrounding spaces, eg the trailing newline of
+//a yaml block scalar, an empty one taking the other value
+func sameText(text *string, other string) bool {
+	if strings.TrimSpace(*text) == "" {
+		*text = other
+	}
+	return strings.TrimSpace(other) == "" || strings.TrimSpace(*text) == strings.TrimSpace(other)
+}
+
+//sameTags check that two definitions of tags agree, an empty one taking the other value
+func sameTags(tags *[]string, other []string) bool {
+	if len(*tags) == 0 {
+		*tags = other
+	}
+	return len(other) == 0 || reflect.DeepEqual(*tags, other)
+}
+
+func findUser(users []User, name string) int {
+	for i, u := range users {
+		if u.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func findInternalSystem(systems []InternalSystem, name string) int {
+	for i, s := range systems {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func findExternalSystem(systems []ExternalSystem, name string) int {
+	for i, s := range systems {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
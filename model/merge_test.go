@@ -0,0 +1,198 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := func() ArcType {
+		return ArcType{
+			App:   "shop",
+			Users: []User{{Name: "customer", Role: "buyer"}},
+			InternalSystems: []InternalSystem{{Name: "web", Role: "storefront", Containers: []Container{
+				{Name: "api", Technology: "go", Components: []Component{{Name: "cart", Desc: "the cart"}}},
+			}}},
+			ExternalSystems: []ExternalSystem{{Name: "payments", Desc: "card payments"}},
+			Relations:       []Relation{{Subject: "customer", Pointer: "buy", Object: "web"}},
+			Styles:          Styles{Layout: "left-right", Elements: []ElementStyle{{Tag: "db", Background: "#fff"}}},
+		}
+	}
+	tests := []struct {
+		name     string
+		other    ArcType
+		conflict *ConflictError
+		check    func(a ArcType) bool
+	}{
+		{
+			name:     "conflicting user",
+			other:    ArcType{Users: []User{{Name: "customer", Role: "seller"}}},
+			conflict: &ConflictError{Kind: "user", Path: "customer"},
+		},
+		{
+			name:     "conflicting internal system",
+			other:    ArcType{InternalSystems: []InternalSystem{{Name: "web", Role: "backoffice"}}},
+			conflict: &ConflictError{Kind: "internal system", Path: "web"},
+		},
+		{
+			name:     "conflicting external system",
+			other:    ArcType{ExternalSystems: []ExternalSystem{{Name: "payments", Desc: "bank transfers"}}},
+			conflict: &ConflictError{Kind: "external system", Path: "payments"},
+		},
+		{
+			name:     "conflicting container",
+			other:    ArcType{InternalSystems: []InternalSystem{{Name: "web", Containers: []Container{{Name: "api", Technology: "java"}}}}},
+			conflict: &ConflictError{Kind: "container", Path: "web.api"},
+		},
+		{
+			name: "conflicting component",
+			other: ArcType{InternalSystems: []InternalSystem{{Name: "web", Containers: []Container{
+				{Name: "api", Components: []Component{{Name: "cart", Desc: "the basket"}}},
+			}}}},
+			conflict: &ConflictError{Kind: "component", Path: "web.api.cart"},
+		},
+		{
+			name:     "user and internal system of the same name",
+			other:    ArcType{InternalSystems: []InternalSystem{{Name: "customer"}}},
+			conflict: &ConflictError{Kind: "user and internal system", Path: "customer"},
+		},
+		{
+			name: "empty fields completed from the other arc",
+			other: ArcType{
+				App:             "other",
+				Desc:            "an online shop",
+				InternalSystems: []InternalSystem{{Name: "web", Desc: "sell things", Containers: []Container{{Name: "api", Runtime: "docker"}}}},
+				ExternalSystems: []ExternalSystem{{Name: "payments", Role: "psp"}},
+			},
+			check: func(a ArcType) bool {
+				sys, cont := a.InternalSystems[0], a.InternalSystems[0].Containers[0]
+				return a.App == "shop" && a.Desc == "an online shop" && sys.Role == "storefront" && sys.Desc == "sell things" &&
+					cont.Technology == "go" && cont.Runtime == "docker" && a.ExternalSystems[0].Role == "psp"
+			},
+		},
+		{
+			name: "texts agreeing regardless of surrounding spaces",
+			other: ArcType{InternalSystems: []InternalSystem{{Name: "web", Role: "storefront \n", Containers: []Container{
+				{Name: "api", Technology: " go"},
+			}}}},
+			check: func(a ArcType) bool { return a.InternalSystems[0].Role == "storefront" },
+		},
+		{
+			name: "union of containers and components",
+			other: ArcType{InternalSystems: []InternalSystem{{Name: "web", Containers: []Container{
+				{Name: "api", Components: []Component{{Name: "cart", Desc: "the cart"}, {Name: "checkout"}}},
+				{Name: "db"},
+			}}}},
+			check: func(a ArcType) bool {
+				conts := a.InternalSystems[0].Containers
+				return len(conts) == 2 && conts[1].Name == "db" && len(conts[0].Components) == 2 && conts[0].Components[1].Name == "checkout"
+			},
+		},
+		{
+			name: "deduplicated relations",
+			other: ArcType{Relations: []Relation{
+				{Subject: "customer", Pointer: "buy", Object: "web"},
+				{Subject: "web", Pointer: "charge", Object: "payments"},
+			}},
+			check: func(a ArcType) bool {
+				return reflect.DeepEqual(a.Relations, []Relation{
+					{Subject: "customer", Pointer: "buy", Object: "web"},
+					{Subject: "web", Pointer: "charge", Object: "payments"},
+				})
+			},
+		},
+		{
+			name:  "styles of the arc taking precedence",
+			other: ArcType{Styles: Styles{Layout: "top-down", Legend: true, Elements: []ElementStyle{{Tag: "db", Background: "#000"}}}},
+			check: func(a ArcType) bool {
+				return a.Styles.Layout == "left-right" && a.Styles.Legend && reflect.DeepEqual(a.Styles.Elements,
+					[]ElementStyle{{Tag: "db", Background: "#000"}, {Tag: "db", Background: "#fff"}})
+			},
+		},
+	}
+	for _, test := range tests {
+		a := base()
+		err := a.Merge(test.other)
+		if test.conflict != nil {
+			if !reflect.DeepEqual(err, test.conflict) {
+				t.Errorf("%s: expect %v, get %v", test.name, test.conflict, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !test.check(a) {
+			t.Errorf("%s: unexpected merged arc %+v", test.name, a)
+		}
+	}
+}
+
+func TestMergeContainers(t *testing.T) {
+	tests := []struct {
+		containers, others []Container
+		expect             []Container
+		conflict           string
+	}{
+		{
+			containers: []Container{{Name: "api", Desc: "the api"}},
+			others:     []Container{{Name: "db", Technology: "postgres"}},
+			expect:     []Container{{Name: "api", Desc: "the api"}, {Name: "db", Technology: "postgres"}},
+		},
+		{
+			containers: []Container{{Name: "api", Tags: []string{"go"}}},
+			others:     []Container{{Name: "api", Kind: "queue", Icon: "go"}},
+			expect:     []Container{{Name: "api", Kind: "queue", Icon: "go", Tags: []string{"go"}}},
+		},
+		{
+			containers: []Container{{Name: "api", Tags: []string{"go"}}},
+			others:     []Container{{Name: "api", Tags: []string{"java"}}},
+			conflict:   "web.api",
+		},
+		{
+			containers: []Container{{Name: "api", Kind: "queue"}},
+			others:     []Container{{Name: "api", Kind: "database"}},
+			conflict:   "web.api",
+		},
+		{
+			containers: []Container{{Name: "api", Components: []Component{{Name: "cart", Code: "cart/"}}}},
+			others:     []Container{{Name: "api", Components: []Component{{Name: "cart", Code: "basket/"}}}},
+			conflict:   "web.api.cart",
+		},
+	}
+	for i, test := range tests {
+		merged, err := mergeContainers("web", test.containers, test.others)
+		if test.conflict != "" {
+			if conflict, ok := err.(*ConflictError); !ok || conflict.Path != test.conflict {
+				t.Errorf("Test %d: expect a conflict on %s, get %v", i, test.conflict, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: %v", i, err)
+		} else if !reflect.DeepEqual(merged, test.expect) {
+			t.Errorf("Test %d: expect %+v, get %+v", i, test.expect, merged)
+		}
+	}
+}
+
+func TestCheckKinds(t *testing.T) {
+	tests := []struct {
+		a, o     ArcType
+		conflict *ConflictError
+	}{
+		{ArcType{Users: []User{{Name: "ops"}}}, ArcType{Users: []User{{Name: "ops"}}}, nil},
+		{ArcType{Users: []User{{Name: "ops"}}}, ArcType{InternalSystems: []InternalSystem{{Name: "ops"}}},
+			&ConflictError{Kind: "user and internal system", Path: "ops"}},
+		{ArcType{ExternalSystems: []ExternalSystem{{Name: "git"}}}, ArcType{InternalSystems: []InternalSystem{{Name: "git"}}},
+			&ConflictError{Kind: "external system and internal system", Path: "git"}},
+		{ArcType{InternalSystems: []InternalSystem{{Name: "web"}}}, ArcType{ExternalSystems: []ExternalSystem{{Name: "payments"}}}, nil},
+	}
+	for i, test := range tests {
+		err := checkKinds(test.a, test.o)
+		if test.conflict == nil && err != nil || test.conflict != nil && !reflect.DeepEqual(err, test.conflict) {
+			t.Errorf("Test %d: expect %v, get %v", i, test.conflict, err)
+		}
+	}
+}
@@ -8,11 +8,13 @@ import (
 
 //go:generate protoc -I . --go_out=plugins=grpc:./ ./model.proto
 
-//User represent a person who use some software
+//User represent a person who use some software, External users are outside of the organisation
 type User struct {
-	Name string `yaml:"name"`
-	Role string `yaml:"role"`
-	Desc string `yaml:"desc"`
+	Name     string   `yaml:"name"`
+	Role     string   `yaml:"role"`
+	Desc     string   `yaml:"desc"`
+	External bool     `yaml:"external"`
+	Tags     []string `yaml:"tags"`
 }
 
 //InternalSystem represent a software system in the application
@@ -20,6 +22,7 @@ type InternalSystem struct {
 	Name       string      `yaml:"name"`
 	Role       string      `yaml:"role"`
 	Desc       string      `yaml:"desc"`
+	Tags       []string    `yaml:"tags"`
 	Containers []Container `yaml:"containers"`
 }
 
@@ -30,32 +33,71 @@ type Container struct {
 	Desc       string      `yaml:"desc"`
 	Runtime    string      `yaml:"runtime"`
 	Technology string      `yaml:"technology"`
+	Kind       string      `yaml:"kind"`
+	Icon       string      `yaml:"icon"`
+	Tags       []string    `yaml:"tags"`
 	Components []Component `yaml:"components"`
 }
 
 //Component represent a Component that make up the implementation of a software running in a Container
 type Component struct {
-	Name string `yaml:"name"`
-	Role string `yaml:"role"`
-	Desc string `yaml:"desc"`
-	Code string `yaml:"code"`
+	Name       string   `yaml:"name"`
+	Role       string   `yaml:"role"`
+	Desc       string   `yaml:"desc"`
+	Technology string   `yaml:"technology"`
+	Icon       string   `yaml:"icon"`
+	Code       string   `yaml:"code"`
+	Tags       []string `yaml:"tags"`
 }
 
-//ExternalSystem represent an external software system
+//ExternalSystem represent an external software system, with the containers of it we integrate with
 type ExternalSystem struct {
-	Name string `yaml:"name"`
-	Role string `yaml:"role"`
-	Desc string `yaml:"desc"`
+	Name       string      `yaml:"name"`
+	Role       string      `yaml:"role"`
+	Desc       string      `yaml:"desc"`
+	Tags       []string    `yaml:"tags"`
+	Containers []Container `yaml:"containers"`
 }
 
+//Version is the version of the arc file format described by these types
+const Version = 2
+
 //ArcType is the core data structure of a software architecture
 type ArcType struct {
+	Version         int              `yaml:"version"`
 	App             string           `yaml:"app"`
 	Desc            string           `yaml:"desc"`
+	Include         []string         `yaml:"include"`
 	Users           []User           `yaml:"users"`
 	InternalSystems []InternalSystem `yaml:"internal-systems"`
+	Groups          []Group          `yaml:"groups"`
 	ExternalSystems []ExternalSystem `yaml:"external-systems"`
 	Relations       []Relation       `yaml:"relations"`
+	Theme           string           `yaml:"theme"`
+	Styles          Styles           `yaml:"styles"`
+}
+
+//Fragment is an arc file describing a single system, or some of its containers, eg in the directory of a service.
+//Without system, the containers complete the containers of the same name defined elsewhere.
+type Fragment struct {
+	Version    int         `yaml:"version"`
+	System     string      `yaml:"system"`
+	Role       string      `yaml:"role"`
+	Desc       string      `yaml:"desc"`
+	Tags       []string    `yaml:"tags"`
+	Container  *Container  `yaml:"container"`
+	Containers []Container `yaml:"containers"`
+	Relations  []Relation  `yaml:"relations"`
+	Include    []string    `yaml:"include"`
+}
+
+//Group gather internal systems and nested groups under a named boundary, such as a business unit or a platform
+type Group struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Desc    string   `yaml:"desc"`
+	Systems []string `yaml:"systems"`
+	Groups  []Group  `yaml:"groups"`
 }
 
 //Relation represent a relationship path between different elements
@@ -63,6 +105,60 @@ type Relation struct {
 	Subject string `yaml:"s"`
 	Pointer string `yaml:"p"`
 	Object  string `yaml:"o"`
+	//Technology of the relation, eg a protocol
+	Technology string   `yaml:"tech"`
+	Tags       []string `yaml:"tags"`
+}
+
+//Styles describe the look of the diagrams, either inline in the arc file or from a theme file
+type Styles struct {
+	Layout    string          `yaml:"layout"`
+	Legend    bool            `yaml:"legend"`
+	Sketch    bool            `yaml:"sketch"`
+	Elements  []ElementStyle  `yaml:"elements"`
+	Relations []RelationStyle `yaml:"relations"`
+	Icons     []IconStyle     `yaml:"icons"`
+}
+
+//ElementStyle restyle all elements of a kind (person, system, external_system, container, component) or carrying a tag
+type ElementStyle struct {
+	Kind       string `yaml:"kind"`
+	Tag        string `yaml:"tag"`
+	Background string `yaml:"background"`
+	Font       string `yaml:"font"`
+	Border     string `yaml:"border"`
+	Line       string `yaml:"line"`
+	Shape      string `yaml:"shape"`
+	Legend     string `yaml:"legend"`
+}
+
+//RelationStyle restyle all relations carrying a tag
+type RelationStyle struct {
+	Tag    string `yaml:"tag"`
+	Color  string `yaml:"color"`
+	Line   string `yaml:"line"`
+	Legend string `yaml:"legend"`
+}
+
+//IconStyle register an icon by name, attached to the elements whose technology is one of Technologies.
+//The icon is either a PlantUML sprite definition, eg "[16x16/16] {...}", or the name of a PlantUML OpenIconic glyph
+type IconStyle struct {
+	Name         string   `yaml:"name"`
+	Technologies []string `yaml:"technologies"`
+	Sprite       string   `yaml:"sprite"`
+	Openiconic   string   `yaml:"openiconic"`
+}
+
+//Merge apply the given styles on top of these ones, later element and relation styles take precedence
+func (s *Styles) Merge(o Styles) {
+	if o.Layout != "" {
+		s.Layout = o.Layout
+	}
+	s.Legend = s.Legend || o.Legend
+	s.Sketch = s.Sketch || o.Sketch
+	s.Elements = append(s.Elements, o.Elements...)
+	s.Relations = append(s.Relations, o.Relations...)
+	s.Icons = append(s.Icons, o.Icons...)
 }
 
 //Decode struct to byte
@@ -112,6 +112,78 @@ func (x ArcVisualFormat) String() string {
 }
 func (ArcVisualFormat) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+type FocusDirection int32
+
+const (
+	FocusDirection_BOTH     FocusDirection = 0
+	FocusDirection_OUTGOING FocusDirection = 1
+	FocusDirection_INCOMING FocusDirection = 2
+)
+
+var FocusDirection_name = map[int32]string{
+	0: "BOTH",
+	1: "OUTGOING",
+	2: "INCOMING",
+}
+var FocusDirection_value = map[string]int32{
+	"BOTH":     0,
+	"OUTGOING": 1,
+	"INCOMING": 2,
+}
+
+func (x FocusDirection) String() string {
+	return proto.EnumName(FocusDirection_name, int32(x))
+}
+func (FocusDirection) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type RelationLevel int32
+
+const (
+	RelationLevel_ALL_LEVELS      RelationLevel = 0
+	RelationLevel_SYSTEM_LEVEL    RelationLevel = 1
+	RelationLevel_CONTAINER_LEVEL RelationLevel = 2
+)
+
+var RelationLevel_name = map[int32]string{
+	0: "ALL_LEVELS",
+	1: "SYSTEM_LEVEL",
+	2: "CONTAINER_LEVEL",
+}
+var RelationLevel_value = map[string]int32{
+	"ALL_LEVELS":      0,
+	"SYSTEM_LEVEL":    1,
+	"CONTAINER_LEVEL": 2,
+}
+
+func (x RelationLevel) String() string {
+	return proto.EnumName(RelationLevel_name, int32(x))
+}
+func (RelationLevel) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+type RollUp int32
+
+const (
+	RollUp_FULL_ROLL_UP      RollUp = 0
+	RollUp_CONTAINER_ROLL_UP RollUp = 1
+	RollUp_NO_ROLL_UP        RollUp = 2
+)
+
+var RollUp_name = map[int32]string{
+	0: "FULL_ROLL_UP",
+	1: "CONTAINER_ROLL_UP",
+	2: "NO_ROLL_UP",
+}
+var RollUp_value = map[string]int32{
+	"FULL_ROLL_UP":      0,
+	"CONTAINER_ROLL_UP": 1,
+	"NO_ROLL_UP":        2,
+}
+
+func (x RollUp) String() string {
+	return proto.EnumName(RollUp_name, int32(x))
+}
+func (RollUp) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
 type RenderRequest struct {
 	// Type of the data
 	DataFormat ArcDataFormat `protobuf:"varint,1,opt,name=dataFormat,enum=model.ArcDataFormat" json:"dataFormat,omitempty"`
@@ -123,6 +195,22 @@ type RenderRequest struct {
 	Perspective PresentationPerspective `protobuf:"varint,4,opt,name=perspective,enum=model.PresentationPerspective" json:"perspective,omitempty"`
 	// target specify the specific element to render
 	Target []string `protobuf:"bytes,5,rep,name=target" json:"target,omitempty"`
+	// focus specify an element to render with its neighbours instead of the perspective
+	Focus string `protobuf:"bytes,6,opt,name=focus" json:"focus,omitempty"`
+	// depth is the number of hops the neighbours of the focus are from it, 1 when unset
+	Depth int32 `protobuf:"varint,7,opt,name=depth" json:"depth,omitempty"`
+	// direction of the relations followed from the focus to its neighbours
+	Direction FocusDirection `protobuf:"varint,8,opt,name=direction,enum=model.FocusDirection" json:"direction,omitempty"`
+	// exclude specify the paths or globs of the elements to leave out of the view with their children
+	Exclude []string `protobuf:"bytes,9,rep,name=exclude" json:"exclude,omitempty"`
+	// hideUsers leave all users out of the view
+	HideUsers bool `protobuf:"varint,10,opt,name=hideUsers" json:"hideUsers,omitempty"`
+	// hideExternalSystems leave all external systems out of the view
+	HideExternalSystems bool `protobuf:"varint,11,opt,name=hideExternalSystems" json:"hideExternalSystems,omitempty"`
+	// relationLevel hide the relations of the elements more detailed than this level
+	RelationLevel RelationLevel `protobuf:"varint,12,opt,name=relationLevel,enum=model.RelationLevel" json:"relationLevel,omitempty"`
+	// rollUp specify how the relations of containers and components are summarised between their parents
+	RollUp RollUp `protobuf:"varint,13,opt,name=rollUp,enum=model.RollUp" json:"rollUp,omitempty"`
 }
 
 func (m *RenderRequest) Reset()                    { *m = RenderRequest{} }
@@ -165,6 +253,62 @@ func (m *RenderRequest) GetTarget() []string {
 	return nil
 }
 
+func (m *RenderRequest) GetFocus() string {
+	if m != nil {
+		return m.Focus
+	}
+	return ""
+}
+
+func (m *RenderRequest) GetDepth() int32 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+func (m *RenderRequest) GetDirection() FocusDirection {
+	if m != nil {
+		return m.Direction
+	}
+	return FocusDirection_BOTH
+}
+
+func (m *RenderRequest) GetExclude() []string {
+	if m != nil {
+		return m.Exclude
+	}
+	return nil
+}
+
+func (m *RenderRequest) GetHideUsers() bool {
+	if m != nil {
+		return m.HideUsers
+	}
+	return false
+}
+
+func (m *RenderRequest) GetHideExternalSystems() bool {
+	if m != nil {
+		return m.HideExternalSystems
+	}
+	return false
+}
+
+func (m *RenderRequest) GetRelationLevel() RelationLevel {
+	if m != nil {
+		return m.RelationLevel
+	}
+	return RelationLevel_ALL_LEVELS
+}
+
+func (m *RenderRequest) GetRollUp() RollUp {
+	if m != nil {
+		return m.RollUp
+	}
+	return RollUp_FULL_ROLL_UP
+}
+
 type ArcPresentation struct {
 	// Format of the presentation
 	Format ArcVisualFormat `protobuf:"varint,1,opt,name=format,enum=model.ArcVisualFormat" json:"format,omitempty"`
@@ -197,6 +341,9 @@ func init() {
 	proto.RegisterEnum("model.ArcDataFormat", ArcDataFormat_name, ArcDataFormat_value)
 	proto.RegisterEnum("model.PresentationPerspective", PresentationPerspective_name, PresentationPerspective_value)
 	proto.RegisterEnum("model.ArcVisualFormat", ArcVisualFormat_name, ArcVisualFormat_value)
+	proto.RegisterEnum("model.FocusDirection", FocusDirection_name, FocusDirection_value)
+	proto.RegisterEnum("model.RelationLevel", RelationLevel_name, RelationLevel_value)
+	proto.RegisterEnum("model.RollUp", RollUp_name, RollUp_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -276,28 +423,43 @@ var _ArcViz_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("model.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 359 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0x3d, 0x6f, 0xe2, 0x40,
-	0x10, 0xc5, 0xc6, 0x98, 0x63, 0x80, 0xbb, 0xd5, 0xe8, 0xc4, 0x59, 0x57, 0x44, 0x88, 0x0a, 0x21,
-	0x44, 0x41, 0x52, 0x44, 0xa9, 0xe2, 0xd8, 0x06, 0x25, 0x02, 0xdb, 0x5a, 0x3e, 0x94, 0x26, 0x85,
-	0x03, 0x9b, 0x08, 0x09, 0x30, 0x59, 0x2f, 0x14, 0xf9, 0xd3, 0xf9, 0x0b, 0x91, 0xd7, 0x0e, 0x2c,
-	0x05, 0xdd, 0xbe, 0x99, 0xf7, 0x9e, 0xde, 0xcc, 0x0e, 0x54, 0x37, 0xf1, 0x92, 0xad, 0x7b, 0x3b,
-	0x1e, 0x8b, 0x18, 0x4b, 0x12, 0xb4, 0xbe, 0x34, 0xa8, 0x53, 0xb6, 0x5d, 0x32, 0x4e, 0xd9, 0xc7,
-	0x9e, 0x25, 0x02, 0x6f, 0x00, 0x96, 0x91, 0x88, 0x06, 0x31, 0xdf, 0x44, 0xc2, 0xd2, 0x9a, 0x5a,
-	0xfb, 0x77, 0xff, 0x6f, 0x2f, 0x93, 0xda, 0x7c, 0xe1, 0x1e, 0x7b, 0x54, 0xe1, 0xe1, 0x1d, 0xd4,
-	0x0e, 0xab, 0x64, 0x1f, 0xad, 0x73, 0x9d, 0x2e, 0x75, 0x8d, 0x93, 0x6e, 0xae, 0x74, 0xe9, 0x19,
-	0x17, 0x11, 0x8c, 0xd4, 0xc9, 0x2a, 0x36, 0xb5, 0x76, 0x8d, 0xca, 0x37, 0xde, 0x43, 0x75, 0xc7,
-	0x78, 0xb2, 0x63, 0x0b, 0xb1, 0x3a, 0x30, 0xcb, 0x90, 0x76, 0x57, 0xb9, 0x5d, 0xc8, 0x59, 0xc2,
-	0xb6, 0x22, 0x12, 0xab, 0x78, 0x1b, 0x9e, 0x58, 0x54, 0x95, 0x60, 0x03, 0x4c, 0x11, 0xf1, 0x77,
-	0x26, 0xac, 0x52, 0xb3, 0xd8, 0xae, 0xd0, 0x1c, 0xb5, 0x66, 0xf0, 0xc7, 0xe6, 0x0b, 0xd5, 0x02,
-	0x7b, 0x60, 0xbe, 0xa9, 0xe3, 0x5e, 0x8a, 0x9d, 0xb3, 0x8e, 0x81, 0xf5, 0x53, 0xe0, 0x4e, 0x17,
-	0xea, 0x67, 0xdb, 0xc1, 0x5f, 0x60, 0x3c, 0x4d, 0x02, 0x9f, 0x14, 0xb0, 0x0c, 0x45, 0x9b, 0x3a,
-	0x44, 0x4b, 0x4b, 0xe1, 0x6c, 0x3c, 0x22, 0x7a, 0xe7, 0x05, 0xfe, 0x5d, 0x18, 0x02, 0xab, 0x50,
-	0x76, 0x02, 0x7f, 0xea, 0x3d, 0x4f, 0x49, 0x01, 0xeb, 0x50, 0x49, 0x81, 0xfd, 0xe8, 0x7b, 0x94,
-	0x68, 0x19, 0x1c, 0x87, 0x81, 0xef, 0xf9, 0x53, 0xa2, 0xa7, 0x7e, 0x4e, 0xe0, 0x7a, 0xc4, 0x48,
-	0x1b, 0x23, 0xdb, 0x77, 0x27, 0x8e, 0x1d, 0x7a, 0xa4, 0xd4, 0xe9, 0xca, 0x19, 0xd5, 0xec, 0x69,
-	0x88, 0xd0, 0x1f, 0x66, 0x69, 0x26, 0xf3, 0x21, 0xd1, 0x64, 0xc5, 0x1d, 0x10, 0xbd, 0xff, 0x00,
-	0xa6, 0x64, 0x7f, 0xe2, 0x2d, 0x98, 0xd9, 0x31, 0xe0, 0xcf, 0x8f, 0x9f, 0xdd, 0xc6, 0x7f, 0x65,
-	0x31, 0x6a, 0xfc, 0x56, 0xe1, 0xd5, 0x94, 0x57, 0x75, 0xfd, 0x1d, 0x00, 0x00, 0xff, 0xff, 0x68,
-	0x89, 0x95, 0x0b, 0x64, 0x02, 0x00, 0x00,
+	// 603 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0x5f, 0x6b, 0xdb, 0x3e,
+	0x14, 0x8d, 0xdd, 0xc4, 0x49, 0x6e, 0x92, 0x56, 0xbf, 0xdb, 0x3f, 0x3f, 0x31, 0xc6, 0x08, 0x85,
+	0x41, 0x08, 0xa5, 0x8c, 0x76, 0x8c, 0xd1, 0xa7, 0xb9, 0x89, 0xd3, 0x75, 0x38, 0xb6, 0x91, 0xe3,
+	0xb2, 0x3d, 0x8c, 0xe2, 0xc5, 0xea, 0x1a, 0x70, 0xe3, 0x4c, 0x76, 0x4a, 0xb7, 0xef, 0xb2, 0xef,
+	0x3a, 0x24, 0x27, 0xb1, 0x33, 0xd6, 0x37, 0x9f, 0x73, 0xee, 0xb9, 0xbe, 0xd2, 0x91, 0x04, 0xad,
+	0x87, 0x24, 0xe2, 0xf1, 0xe9, 0x42, 0x24, 0x59, 0x82, 0x35, 0x05, 0x8e, 0x7f, 0x57, 0xa1, 0xc3,
+	0xf8, 0x3c, 0xe2, 0x82, 0xf1, 0x1f, 0x4b, 0x9e, 0x66, 0xf8, 0x16, 0x20, 0x0a, 0xb3, 0x70, 0x94,
+	0x88, 0x87, 0x30, 0xa3, 0x5a, 0x57, 0xeb, 0xed, 0x9e, 0x1d, 0x9c, 0xe6, 0x56, 0x53, 0x4c, 0x87,
+	0x1b, 0x8d, 0x95, 0xea, 0xf0, 0x02, 0xda, 0x8f, 0xb3, 0x74, 0x19, 0xc6, 0x2b, 0x9f, 0xae, 0x7c,
+	0x47, 0x85, 0xef, 0xa6, 0xa4, 0xb2, 0xad, 0x5a, 0x44, 0xa8, 0xca, 0x4e, 0x74, 0xa7, 0xab, 0xf5,
+	0xda, 0x4c, 0x7d, 0xe3, 0x07, 0x68, 0x2d, 0xb8, 0x48, 0x17, 0x7c, 0x9a, 0xcd, 0x1e, 0x39, 0xad,
+	0xaa, 0x76, 0xaf, 0x56, 0xed, 0x3c, 0xc1, 0x53, 0x3e, 0xcf, 0xc2, 0x6c, 0x96, 0xcc, 0xbd, 0xa2,
+	0x8a, 0x95, 0x2d, 0x78, 0x04, 0x46, 0x16, 0x8a, 0xef, 0x3c, 0xa3, 0xb5, 0xee, 0x4e, 0xaf, 0xc9,
+	0x56, 0x08, 0x0f, 0xa0, 0x76, 0x97, 0x4c, 0x97, 0x29, 0x35, 0xba, 0x5a, 0xaf, 0xc9, 0x72, 0x20,
+	0xd9, 0x88, 0x2f, 0xb2, 0x7b, 0x5a, 0xef, 0x6a, 0xbd, 0x1a, 0xcb, 0x01, 0x9e, 0x43, 0x33, 0x9a,
+	0x09, 0xd9, 0x30, 0x99, 0xd3, 0x86, 0x9a, 0xe1, 0x70, 0x35, 0xc3, 0x48, 0xda, 0x86, 0x6b, 0x91,
+	0x15, 0x75, 0x48, 0xa1, 0xce, 0x9f, 0xa6, 0xf1, 0x32, 0xe2, 0xb4, 0xa9, 0xfe, 0xbc, 0x86, 0xf8,
+	0x12, 0x9a, 0xf7, 0xb3, 0x88, 0x07, 0x29, 0x17, 0x29, 0x85, 0xae, 0xd6, 0x6b, 0xb0, 0x82, 0xc0,
+	0x37, 0xb0, 0x2f, 0x81, 0xf5, 0x94, 0x71, 0x31, 0x0f, 0x63, 0xff, 0x67, 0x9a, 0xf1, 0x87, 0x94,
+	0xb6, 0x54, 0xdd, 0xbf, 0x24, 0xbc, 0x80, 0x8e, 0xe0, 0xb1, 0xda, 0x06, 0x9b, 0x3f, 0xf2, 0x98,
+	0xb6, 0xb7, 0xd2, 0x62, 0x65, 0x8d, 0x6d, 0x97, 0xe2, 0x6b, 0x30, 0x44, 0x12, 0xc7, 0xc1, 0x82,
+	0x76, 0x94, 0xa9, 0xb3, 0x36, 0x29, 0x92, 0xad, 0xc4, 0xe3, 0x00, 0xf6, 0x4c, 0x31, 0x2d, 0x6f,
+	0x38, 0x9e, 0x82, 0x71, 0x57, 0x3e, 0x1c, 0xcf, 0x85, 0x6c, 0xdc, 0x6d, 0xc7, 0xab, 0x17, 0xf1,
+	0xf6, 0x4f, 0xa0, 0xb3, 0x75, 0x96, 0xb0, 0x01, 0xd5, 0x4f, 0xbe, 0xeb, 0x90, 0x0a, 0xd6, 0x61,
+	0xc7, 0x64, 0x03, 0xa2, 0x49, 0xca, 0x0b, 0xc6, 0x36, 0xd1, 0xfb, 0x5f, 0xe1, 0xff, 0x67, 0x22,
+	0xc7, 0x16, 0xd4, 0x07, 0xae, 0x33, 0xb1, 0x3e, 0x4f, 0x48, 0x05, 0x3b, 0xd0, 0x94, 0xc0, 0xbc,
+	0x76, 0x2c, 0x46, 0xb4, 0x1c, 0x8e, 0x3d, 0xd7, 0xb1, 0x9c, 0x09, 0xd1, 0x65, 0xbf, 0x81, 0x3b,
+	0xb4, 0x48, 0x55, 0x0a, 0xb6, 0xe9, 0x0c, 0xfd, 0x81, 0xe9, 0x59, 0xa4, 0xd6, 0x3f, 0x81, 0xbd,
+	0xbf, 0x66, 0x97, 0x43, 0x78, 0xce, 0x55, 0x3e, 0x8d, 0x7f, 0x73, 0x45, 0x34, 0xc5, 0x0c, 0x47,
+	0x44, 0xef, 0xbf, 0x83, 0xdd, 0xed, 0xec, 0x65, 0xe3, 0x4b, 0x77, 0xf2, 0x91, 0x54, 0xb0, 0x0d,
+	0x0d, 0x37, 0x98, 0x5c, 0xb9, 0xd7, 0x8e, 0xb4, 0xb4, 0xa1, 0x71, 0xed, 0x0c, 0xdc, 0xb1, 0x44,
+	0x7a, 0x7f, 0x24, 0x2f, 0x5a, 0x39, 0x81, 0x5d, 0x00, 0xd3, 0xb6, 0x6f, 0x6d, 0xeb, 0xc6, 0xb2,
+	0x7d, 0x52, 0x41, 0x02, 0x6d, 0xff, 0x8b, 0x3f, 0xb1, 0xc6, 0x39, 0x45, 0x34, 0xdc, 0x87, 0xbd,
+	0xcd, 0x7a, 0x56, 0xa4, 0xde, 0x37, 0xc1, 0xc8, 0x33, 0x92, 0x86, 0x51, 0x60, 0xdb, 0xb7, 0xcc,
+	0xb5, 0xed, 0xdb, 0xc0, 0x23, 0x15, 0x3c, 0x84, 0xff, 0x0a, 0xc3, 0x9a, 0xd6, 0xe4, 0x9f, 0x1c,
+	0x77, 0x83, 0xf5, 0xb3, 0x4b, 0x30, 0xd4, 0x82, 0x7f, 0xe1, 0x7b, 0x30, 0xf2, 0xdb, 0x8f, 0xc5,
+	0xa1, 0x29, 0x3d, 0x06, 0x2f, 0x4a, 0xd9, 0x96, 0x13, 0x38, 0xae, 0x7c, 0x33, 0xd4, 0x33, 0x72,
+	0xfe, 0x67, 0x00, 0x22, 0x1a, 0x0a, 0x8b, 0x55, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,266 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//SchemaDraft is the JSON Schema draft the arc schema is written in
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+//ElementKinds are the kinds of element that styles can apply to
+var ElementKinds = []string{"person", "external_person", "system", "external_system", "container", "external_container", "component"}
+
+//typeDocs describe the types of arc files in the JSON Schema
+var typeDocs = map[string]string{
+	"ArcType":        "An arc file describing the software architecture of an application",
+	"Fragment":       "An arc file describing a single system, or some of its containers, eg in the directory of a service",
+	"User":           "A person who use the software",
+	"InternalSystem": "A software system of the application",
+	"Container":      "A runtime of a software system, eg a service, a web application or a database",
+	"Component":      "A component of the implementation of a container",
+	"ExternalSystem": "A software system outside of the application",
+	"Group":          "A named group of internal systems, drawn as a boundary",
+	"Relation":       "A relationship from a subject element to an object element, elements being given by path, eg system.container.component",
+	"Styles":         "The look of the diagrams",
+	"ElementStyle":   "A style applying to all elements of a kind or carrying a tag",
+	"RelationStyle":  "A style applying to all relations carrying a tag",
+	"IconStyle":      "An icon, registered by name and attached to the elements of the given technologies",
+	"Perspective":    "A perspective to view the architecture from, as given to arcli inspect or to view markers",
+}
+
+//fieldDocs describe each field of the types of arc files in the JSON Schema, keyed by type and field name
+var fieldDocs = map[string]string{
+	"ArcType.Version":         "Version of the arc format the file is written in, files without version are of version 1",
+	"ArcType.App":             "Name of the application",
+	"ArcType.Desc":            "Description of the application",
+	"ArcType.Include":         "Other arc files to merge in, as paths relative to this file or git::<repository>//<path>[?ref=<ref>]",
+	"ArcType.Users":           "People who use the application",
+	"ArcType.InternalSystems": "Software systems of the application",
+	"ArcType.Groups":          "Named groups of internal systems, such as business units, which can nest",
+	"ArcType.ExternalSystems": "Software systems outside of the application that it interacts with",
+	"ArcType.Relations":       "Relationships between users, systems, containers and components",
+	"ArcType.Theme":           "Path of a theme file holding styles, relative to this file",
+	"ArcType.Styles":          "Styles of the diagrams, taking precedence over the theme",
+
+	"Fragment.Version":    "Version of the arc format the file is written in, files without version are of version 1",
+	"Fragment.System":     "Name of the internal system described, without system the containers complete the containers of the same name defined elsewhere",
+	"Fragment.Role":       "Role of the system",
+	"Fragment.Desc":       "Description of the system",
+	"Fragment.Tags":       "Tags of the system, to style it",
+	"Fragment.Container":  "A single container of the system",
+	"Fragment.Containers": "Containers of the system",
+	"Fragment.Relations":  "Relationships between elements",
+	"Fragment.Include":    "Other arc files to merge in, as paths relative to this file or git::<repository>//<path>[?ref=<ref>]",
+
+	"User.Name":     "Unique name of the user",
+	"User.Role":     "Role of the user",
+	"User.Desc":     "Description of the user",
+	"User.External": "Whether the user is outside of the organisation",
+	"User.Tags":     "Tags of the user, to style it",
+
+	"InternalSystem.Name":       "Unique name of the system",
+	"InternalSystem.Role":       "Role of the system",
+	"InternalSystem.Desc":       "Description of the system",
+	"InternalSystem.Tags":       "Tags of the system, to style it",
+	"InternalSystem.Containers": "Containers of the system",
+
+	"Container.Name":       "Name of the container, unique in its system",
+	"Container.Role":       "Role of the container",
+	"Container.Desc":       "Description of the container",
+	"Container.Runtime":    "Runtime of the container, eg docker or browser",
+	"Container.Technology": "Technology of the container, eg golang",
+	"Container.Kind":       "Kind of the container, setting its shape, inferred from its runtime then technology when absent",
+	"Container.Icon":       "Name of a registered icon or of an OpenIconic glyph, none to remove the icon inferred from the technology",
+	"Container.Tags":       "Tags of the container, to style it",
+	"Container.Components": "Components of the container",
+
+	"Component.Name":       "Name of the component, unique in its container",
+	"Component.Role":       "Role of the component",
+	"Component.Desc":       "Description of the component",
+	"Component.Technology": "Technology of the component",
+	"Component.Icon":       "Name of a registered icon or of an OpenIconic glyph, none to remove the icon inferred from the technology",
+	"Component.Code":       "Location of the code of the component",
+	"Component.Tags":       "Tags of the component, to style it",
+
+	"ExternalSystem.Name":       "Unique name of the system",
+	"ExternalSystem.Role":       "Role of the system",
+	"ExternalSystem.Desc":       "Description of the system",
+	"ExternalSystem.Tags":       "Tags of the system, to style it",
+	"ExternalSystem.Containers": "Containers of the system that the application interacts with",
+
+	"Group.Name":    "Unique name of the group, usable as a target",
+	"Group.Type":    "Type of the group shown on its boundary, eg Business Unit, Group by default",
+	"Group.Desc":    "Description of the group",
+	"Group.Systems": "Names of the internal systems of the group",
+	"Group.Groups":  "Nested groups",
+
+	"Relation.Subject":    "Path of the subject element",
+	"Relation.Pointer":    "Description of the relationship",
+	"Relation.Object":     "Path of the object element",
+	"Relation.Technology": "Technology of the relationship, eg a protocol",
+	"Relation.Tags":       "Tags of the relation, to style it",
+
+	"Styles.Layout":    "Direction of the diagrams",
+	"Styles.Legend":    "Whether to draw a legend",
+	"Styles.Sketch":    "Whether to draw in a handwritten style",
+	"Styles.Elements":  "Styles of elements by kind or tag, later ones taking precedence",
+	"Styles.Relations": "Styles of relations by tag, later ones taking precedence",
+	"Styles.Icons":     "Icons to register",
+
+	"ElementStyle.Kind":       "Kind of the elements to style",
+	"ElementStyle.Tag":        "Tag of the elements to style",
+	"ElementStyle.Background": "Background color, eg #1168bd",
+	"ElementStyle.Font":       "Font color",
+	"ElementStyle.Border":     "Border color",
+	"ElementStyle.Line":       "Border line",
+	"ElementStyle.Shape":      "Corners of the shape",
+	"ElementStyle.Legend":     "Text of the legend row of the style",
+
+	"RelationStyle.Tag":    "Tag of the relations to style",
+	"RelationStyle.Color":  "Color of the arrow",
+	"RelationStyle.Line":   "Line of the arrow",
+	"RelationStyle.Legend": "Text of the legend row of the style",
+
+	"IconStyle.Name":         "Name of the icon, to use as the icon of elements",
+	"IconStyle.Technologies": "Technologies whose elements get the icon",
+	"IconStyle.Sprite":       "PlantUML sprite definition, eg [16x16/16] {...}",
+	"IconStyle.Openiconic":   "Name of a PlantUML OpenIconic glyph",
+}
+
+//fieldEnums are the values accepted by the fields of the types of arc files, keyed by type and field name
+var fieldEnums = map[string][]string{
+	"Container.Kind":     ContainerKindNames(),
+	"Styles.Layout":      {"top-down", "left-right"},
+	"ElementStyle.Kind":  ElementKinds,
+	"ElementStyle.Line":  {"dashed", "dotted", "bold"},
+	"ElementStyle.Shape": {"rounded", "rectangle"},
+	"RelationStyle.Line": {"dashed", "dotted", "bold"},
+}
+
+//ContainerKindNames return the accepted spellings of container kinds, sorted
+func ContainerKindNames() []string {
+	names := make([]string, 0, len(containerKinds))
+	for name := range containerKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Perspectives return the names of the perspectives, sorted
+func Perspectives() []string {
+	names := make([]string, 0, len(PresentationPerspective_name))
+	for _, name := range PresentationPerspective_name {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+//JSONSchema return the JSON Schema of arc yaml files, either full arcs or fragments, generated from the model types
+func JSONSchema() ([]byte, error) {
+	definitions := make(map[string]interface{})
+	for _, t := range []reflect.Type{reflect.TypeOf(ArcType{}), reflect.TypeOf(Fragment{})} {
+		if err := defineType(t, definitions); err != nil {
+			return nil, err
+		}
+	}
+	definitions["Perspective"] = map[string]interface{}{
+		"description": typeDocs["Perspective"],
+		"type":        "string",
+		"enum":        Perspectives(),
+	}
+	schema := map[string]interface{}{
+		"$schema":     SchemaDraft,
+		"title":       "arc",
+		"description": fmt.Sprintf("Software architecture described in an arc yaml file, version %d", Version),
+		"anyOf": []interface{}{
+			map[string]string{"$ref": "#/definitions/ArcType"},
+			map[string]string{"$ref": "#/definitions/Fragment"},
+		},
+		"definitions": definitions,
+	}
+	out := &bytes.Buffer{}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+//defineType add the definition of a struct type, and of the struct types of its fields, to the definitions
+func defineType(t reflect.Type, definitions map[string]interface{}) error {
+	if _, ok := definitions[t.Name()]; ok {
+		return nil
+	}
+	doc, ok := typeDocs[t.Name()]
+	if !ok {
+		return fmt.Errorf("missing description of type %s", t.Name())
+	}
+	properties := make(map[string]interface{})
+	definitions[t.Name()] = map[string]interface{}{
+		"description": doc,
+		"type":        "object",
+		"properties":  properties,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := t.Name() + "." + field.Name
+		doc, ok := fieldDocs[key]
+		if !ok {
+			return fmt.Errorf("missing description of field %s", key)
+		}
+		property, err := fieldSchema(field.Type, definitions)
+		if err != nil {
+			return err
+		}
+		if ref, ok := property["$ref"]; ok {
+			//keywords next to a $ref are ignored, so the reference is wrapped to keep the description
+			property = map[string]interface{}{"allOf": []interface{}{map[string]interface{}{"$ref": ref}}}
+		}
+		property["description"] = doc
+		if enum := fieldEnums[key]; len(enum) > 0 {
+			property["enum"] = enum
+		}
+		properties[name] = property
+	}
+	return nil
+}
+
+//fieldSchema return the schema of a field of the given type
+func fieldSchema(t reflect.Type, definitions map[string]interface{}) (map[string]interface{}, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return fieldSchema(t.Elem(), definitions)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Slice:
+		items, err := fieldSchema(t.Elem(), definitions)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		if err := defineType(t, definitions); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s in schema", t)
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published, err := ioutil.ReadFile("../arc.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(schema, published) {
+		t.Error("arc.schema.json is out of sync with the model types, run: arcli schema -o arc.schema.json")
+	}
+}
+
+func TestSchemaDocs(t *testing.T) {
+	types := map[string]reflect.Type{}
+	for _, v := range []interface{}{ArcType{}, Fragment{}, User{}, InternalSystem{}, Container{}, Component{}, ExternalSystem{},
+		Group{}, Relation{}, Styles{}, ElementStyle{}, RelationStyle{}, IconStyle{}} {
+		types[reflect.TypeOf(v).Name()] = reflect.TypeOf(v)
+	}
+	for key := range fieldDocs {
+		parts := strings.SplitN(key, ".", 2)
+		typ, ok := types[parts[0]]
+		if !ok {
+			t.Errorf("Description of field %s of an unknown type", key)
+			continue
+		}
+		if _, ok := typ.FieldByName(parts[1]); !ok {
+			t.Errorf("Description of unknown field %s", key)
+		}
+	}
+	for key := range fieldEnums {
+		if _, ok := fieldDocs[key]; !ok {
+			t.Errorf("Values of unknown field %s", key)
+		}
+	}
+	for _, kind := range []string{ContainerService, ContainerWebApp, ContainerDatabase, ContainerQueue, ContainerFileStore, ContainerBatch} {
+		if _, ok := containerKinds[kind]; !ok {
+			t.Errorf("Container kind %s is not accepted", kind)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/koderizer/arc/model"
@@ -16,12 +17,12 @@ import (
 
 //Perspective type
 const (
-	Landscape                = 0
-	Context                  = 1
-	Container                = 2
-	Component                = 3
-	Code                     = 4
-	DefaultDependencyPointer = "Use:"
+	Landscape = 0
+	Context   = 1
+	Container = 2
+	Component = 3
+	Code      = 4
+	Focus     = 5
 )
 
 //Perspective are type supported by viz
@@ -39,15 +40,30 @@ type Graph struct {
 	eids     map[string]int64
 	edges    map[int64]edge
 	vertices map[int]Vertice
+
+	//Focus is the element a Focus view is drawn around, with its neighbours up to Depth hops in the given Direction
+	Focus     string
+	Depth     int
+	Direction FocusDirection
+	focus     focus
+
+	//Exclude are the paths or globs of the elements left out of the view with their children, prefixed or not with !
+	Exclude             []string
+	HideUsers           bool
+	HideExternalSystems bool
+	RelationLevel       RelationLevel
+	//RollUp tell how far the relations of containers and components are rolled up to their parents
+	RollUp RollUp
 }
 
 //VerticeType constants
 const (
-	VerticeTypeUser           = 0
-	VerticeTypeInternalSystem = 1
-	VerticeTypeExternalSystem = 2
-	VerticeTypeContainer      = 3
-	VerticeTypeComponent      = 4
+	VerticeTypeUser              = 0
+	VerticeTypeInternalSystem    = 1
+	VerticeTypeExternalSystem    = 2
+	VerticeTypeContainer         = 3
+	VerticeTypeComponent         = 4
+	VerticeTypeExternalContainer = 5
 )
 
 //VerticeType map to the model abstraction
@@ -57,11 +73,14 @@ type VerticeType int
 type Vertice struct {
 	Entity interface{}
 	Kind   VerticeType
+	Path   string
 }
 
 type edge struct {
 	relation model.Relation
 	views    map[Perspective]bool
+	//rolled are the declared relations a rolled up edge summarise, none for a declared relation
+	rolled []model.Relation
 }
 
 // GetUsers return relevant internal systems
@@ -71,7 +90,8 @@ func (g *Graph) GetUsers() ([]model.User, error) {
 	}
 	users := make([]model.User, 0)
 
-	for tar, tid := range g.tarMap {
+	for _, tar := range g.targets() {
+		tid := g.tarMap[tar]
 		for _, vid := range g.walkTarget(tid, VerticeTypeUser) {
 			users = append(users, g.vertices[vid].Entity.(model.User))
 		}
@@ -90,29 +110,61 @@ func (g *Graph) GetInternalSystems() ([]model.InternalSystem, error) {
 		return nil, errors.New("Empty graph")
 	}
 	systems := make([]model.InternalSystem, 0)
-	for _, tid := range g.tarMap {
+	for _, tar := range g.targets() {
+		tid := g.tarMap[tar]
 		systems = append(systems, g.vertices[tid].Entity.(model.InternalSystem))
 	}
 	return systems, nil
 }
 
-//GetExternalSystems return relevant external systems or internal systems if the view is targeted
+//GetExternalSystems return relevant external systems or internal systems if the view is targeted.
+//The containers of an external system are the ones related to the targets, so they are only given in Container view.
 func (g *Graph) GetExternalSystems() ([]model.ExternalSystem, error) {
 	if g.Arc == nil {
 		return nil, errors.New("Empty graph")
 	}
 	systems := make([]model.ExternalSystem, 0)
-	for tar, tid := range g.tarMap {
+	found := make(map[string]int, 0)
+	addSystem := func(sys model.ExternalSystem) int {
+		if i, ok := found[sys.Name]; ok {
+			return i
+		}
+		sys.Containers = nil
+		found[sys.Name] = len(systems)
+		systems = append(systems, sys)
+		return found[sys.Name]
+	}
+	addContainer := func(vid int) {
+		v := g.vertices[vid]
+		sys := g.vertices[g.vids[strings.SplitN(v.Path, ".", 2)[0]]].Entity.(model.ExternalSystem)
+		i := addSystem(sys)
+		container := v.Entity.(model.Container)
+		for _, c := range systems[i].Containers {
+			if c.Name == container.Name {
+				return
+			}
+		}
+		systems[i].Containers = append(systems[i].Containers, container)
+	}
+	for _, tar := range g.targets() {
+		tid := g.tarMap[tar]
 		for _, vid := range g.walkTarget(tid, VerticeTypeExternalSystem) {
-			systems = append(systems, g.vertices[vid].Entity.(model.ExternalSystem))
+			addSystem(g.vertices[vid].Entity.(model.ExternalSystem))
+		}
+		for _, vid := range g.walkTarget(tid, VerticeTypeExternalContainer) {
+			addContainer(vid)
 		}
 		for _, container := range g.vertices[tid].Entity.(model.InternalSystem).Containers {
-			for _, vid := range g.walkTarget(g.vids[tar+"."+container.Name], VerticeTypeExternalSystem) {
-				systems = append(systems, g.vertices[vid].Entity.(model.ExternalSystem))
+			cid := g.vids[tar+"."+container.Name]
+			for _, vid := range g.walkTarget(cid, VerticeTypeExternalSystem) {
+				addSystem(g.vertices[vid].Entity.(model.ExternalSystem))
+			}
+			for _, vid := range g.walkTarget(cid, VerticeTypeExternalContainer) {
+				addContainer(vid)
 			}
-			for _, vid := range g.walkTarget(g.vids[tar+"."+container.Name], VerticeTypeInternalSystem) {
+			for _, vid := range g.walkTarget(cid, VerticeTypeInternalSystem) {
 				internalExtern := g.vertices[vid].Entity.(model.InternalSystem)
-				systems = append(systems, model.ExternalSystem{
+				addSystem(model.ExternalSystem{
 					Name: internalExtern.Name,
 					Desc: internalExtern.Desc,
 				})
@@ -122,6 +174,88 @@ func (g *Graph) GetExternalSystems() ([]model.ExternalSystem, error) {
 	return systems, nil
 }
 
+//View return the subset of the architecture relevant to the analysed Landscape, Context or Container perspective
+func (g *Graph) View() (model.ArcType, error) {
+	var err error
+	if g.Arc == nil {
+		return model.ArcType{}, errors.New("Empty graph")
+	}
+	arc := model.ArcType{
+		App:    g.Arc.App,
+		Desc:   g.Arc.Desc,
+		Styles: g.Arc.Styles,
+	}
+	arc.InternalSystems, err = g.GetInternalSystems()
+	if err != nil {
+		return arc, err
+	}
+	shown := make(map[string]bool, len(arc.InternalSystems))
+	for _, iSys := range arc.InternalSystems {
+		shown[iSys.Name] = true
+	}
+	arc.Groups = model.PruneGroups(g.Arc.Groups, shown)
+	arc.ExternalSystems, err = g.GetExternalSystems()
+	if err != nil {
+		return arc, err
+	}
+	arc.Relations, err = g.GetRelations()
+	if err != nil {
+		return arc, err
+	}
+	arc.Users, err = g.GetUsers()
+	if err != nil {
+		return arc, err
+	}
+	return arc, nil
+}
+
+//GetContainers return the targeted containers of a Component view keyed by their path
+func (g *Graph) GetContainers() (map[string]model.Container, error) {
+	if g.Arc == nil {
+		return nil, errors.New("Empty graph")
+	}
+	if g.Pers == Focus {
+		return g.focusContainers(), nil
+	}
+	if g.Pers != Component {
+		return nil, errors.New("Containers are only targeted in Component and Focus views")
+	}
+	containers := make(map[string]model.Container, 0)
+	for _, tar := range g.targets() {
+		tid := g.tarMap[tar]
+		containers[tar] = g.vertices[tid].Entity.(model.Container)
+	}
+	return containers, nil
+}
+
+//GetNeighbors return all elements directly related to the components of the targeted containers keyed by their path
+func (g *Graph) GetNeighbors() (map[string]Vertice, error) {
+	if g.Arc == nil {
+		return nil, errors.New("Empty graph")
+	}
+	if g.Pers == Focus {
+		return g.focusNeighbors(), nil
+	}
+	if g.Pers != Component {
+		return nil, errors.New("Neighbors are only available in Component and Focus views")
+	}
+	neighbors := make(map[string]Vertice, 0)
+	for _, tar := range g.targets() {
+		tid := g.tarMap[tar]
+		for _, component := range g.vertices[tid].Entity.(model.Container).Components {
+			g.graph.Visit(g.vids[tar+"."+component.Name], func(w int, c int64) bool {
+				if show, found := g.edges[c].views[g.Pers]; show && found {
+					if v := g.vertices[w]; !strings.HasPrefix(v.Path, tar+".") {
+						neighbors[v.Path] = v
+					}
+				}
+				return false
+			})
+		}
+	}
+	return neighbors, nil
+}
+
 func (g *Graph) walkTarget(vid int, kind VerticeType) []int {
 	results := make([]int, 0)
 	g.graph.Visit(vid, func(w int, c int64) bool {
@@ -132,6 +266,7 @@ func (g *Graph) walkTarget(vid int, kind VerticeType) []int {
 		}
 		return false
 	})
+	sort.Ints(results)
 	return results
 }
 
@@ -140,10 +275,29 @@ func (g *Graph) GetRelations() ([]model.Relation, error) {
 	if g.Arc == nil {
 		return nil, errors.New("Empty graph")
 	}
+	if g.Pers == Focus {
+		return append([]model.Relation{}, g.focus.relations...), nil
+	}
 	relations := make([]model.Relation, 0)
 	relationIDs := make(map[int64]int, 0)
-	if len(g.tarMap) > 0 {
-		for _, vid := range g.tarMap {
+	if g.Pers == Component {
+		for _, tar := range g.targets() {
+			tid := g.tarMap[tar]
+			for _, component := range g.vertices[tid].Entity.(model.Container).Components {
+				g.graph.Visit(g.vids[tar+"."+component.Name], func(w int, c int64) bool {
+					relationIDs[c] = w
+					return false
+				})
+			}
+		}
+		for _, eid := range sortedEdges(relationIDs) {
+			if show, ok := g.edges[eid].views[g.Pers]; ok && show {
+				relations = append(relations, g.edges[eid].relation)
+			}
+		}
+	} else if len(g.tarMap) > 0 {
+		for _, tar := range g.targets() {
+			vid := g.tarMap[tar]
 			g.graph.Visit(vid, func(w int, c int64) bool {
 				relationIDs[c] = w
 				return false
@@ -157,21 +311,128 @@ func (g *Graph) GetRelations() ([]model.Relation, error) {
 				})
 			}
 		}
-		for eid := range relationIDs {
+		for _, eid := range sortedEdges(relationIDs) {
 			if show, ok := g.edges[eid].views[g.Pers]; ok && show {
 				relations = append(relations, g.edges[eid].relation)
 			}
 		}
 	} else {
-		for _, edge := range g.edges {
-			if show, ok := edge.views[g.Pers]; ok && show {
-				relations = append(relations, edge.relation)
+		for eid := int64(1); eid <= int64(len(g.edges)); eid++ {
+			if show, ok := g.edges[eid].views[g.Pers]; ok && show {
+				relations = append(relations, g.edges[eid].relation)
 			}
 		}
 	}
 	return relations, nil
 }
 
+//Element return the vertice of the element at the given path
+func (g *Graph) Element(path string) (Vertice, bool) {
+	vid, ok := g.vids[path]
+	if !ok {
+		return Vertice{}, false
+	}
+	return g.vertices[vid], true
+}
+
+//Children return the elements directly within the element at the given path, or the top level elements
+//given an empty path, in the order of the architecture
+func (g *Graph) Children(path string) []Vertice {
+	children := make([]Vertice, 0)
+	for vid := 1; vid <= len(g.vertices); vid++ {
+		v := g.vertices[vid]
+		parent := ""
+		if i := strings.LastIndex(v.Path, "."); i >= 0 {
+			parent = v.Path[:i]
+		}
+		if parent == path {
+			children = append(children, v)
+		}
+	}
+	return children
+}
+
+//ElementRelations return the relations leaving and coming to the element at the given path, in the order of the architecture,
+//including the relations of its children rolled up to it
+func (g *Graph) ElementRelations(path string) (out []model.Relation, in []model.Relation) {
+	vid, ok := g.vids[path]
+	if !ok || g.graph == nil {
+		return nil, nil
+	}
+	relationIDs := make(map[int64]int, 0)
+	g.graph.Visit(vid, func(w int, c int64) bool {
+		relationIDs[c] = w
+		return false
+	})
+	for _, eid := range sortedEdges(relationIDs) {
+		rel := g.edges[eid].relation
+		if rel.Subject == path {
+			out = append(out, rel)
+		}
+		if rel.Object == path {
+			in = append(in, rel)
+		}
+	}
+	return out, in
+}
+
+//targets return the names of the targeted elements in a stable order
+func (g *Graph) targets() []string {
+	tars := make([]string, 0, len(g.tarMap))
+	for tar := range g.tarMap {
+		tars = append(tars, tar)
+	}
+	sort.Strings(tars)
+	return tars
+}
+
+//sortedEdges return the edge ids in the order their relations are declared
+func sortedEdges(ids map[int64]int) []int64 {
+	eids := make([]int64, 0, len(ids))
+	for eid := range ids {
+		eids = append(eids, eid)
+	}
+	sort.Slice(eids, func(i, j int) bool { return eids[i] < eids[j] })
+	return eids
+}
+
+//expandGroup return the targets a group target stand for: its systems, or their containers with components
+//in the Component perspective. Other targets, and groups named after a system, are returned as is.
+func (g *Graph) expandGroup(tar string) []string {
+	for _, iSys := range g.Arc.InternalSystems {
+		if iSys.Name == tar {
+			return []string{tar}
+		}
+	}
+	group, ok := g.Arc.FindGroup(tar)
+	if !ok {
+		return []string{tar}
+	}
+	systems := make([]string, 0)
+	for _, name := range group.AllSystems() {
+		if !g.excluded(name) {
+			systems = append(systems, name)
+		}
+	}
+	if g.Pers != Component {
+		return systems
+	}
+	tars := make([]string, 0)
+	for _, name := range systems {
+		for _, iSys := range g.Arc.InternalSystems {
+			if iSys.Name != name {
+				continue
+			}
+			for _, container := range iSys.Containers {
+				if len(container.Components) > 0 {
+					tars = append(tars, iSys.Name+"."+container.Name)
+				}
+			}
+		}
+	}
+	return tars
+}
+
 //Init the graph will generate a list of local ids and return total number of nodes
 func (g *Graph) Init() int {
 	if g.Arc == nil {
@@ -183,7 +444,17 @@ func (g *Graph) Init() int {
 	g.vertices = make(map[int]Vertice, 0)
 	if len(g.tars) > 0 {
 		for _, tar := range g.tars {
-			g.tarMap[tar] = 0
+			for _, t := range g.expandGroup(tar) {
+				g.tarMap[t] = 0
+			}
+		}
+	} else if g.Pers == Component {
+		for _, iSys := range g.Arc.InternalSystems {
+			for _, container := range iSys.Containers {
+				if len(container.Components) > 0 {
+					g.tarMap[iSys.Name+"."+container.Name] = 0
+				}
+			}
 		}
 	} else {
 		for _, iSys := range g.Arc.InternalSystems {
@@ -198,6 +469,7 @@ func (g *Graph) Init() int {
 			g.vertices[vid] = Vertice{
 				Entity: user,
 				Kind:   VerticeTypeUser,
+				Path:   user.Name,
 			}
 		}
 	}
@@ -208,8 +480,9 @@ func (g *Graph) Init() int {
 			g.vertices[vid] = Vertice{
 				Entity: isys,
 				Kind:   VerticeTypeInternalSystem,
+				Path:   isys.Name,
 			}
-			if _, found := g.tarMap[isys.Name]; found {
+			if _, found := g.tarMap[isys.Name]; found && g.Pers != Component {
 				g.tarMap[isys.Name] = vid
 			}
 		}
@@ -221,6 +494,10 @@ func (g *Graph) Init() int {
 				g.vertices[vid] = Vertice{
 					Entity: container,
 					Kind:   VerticeTypeContainer,
+					Path:   cname,
+				}
+				if _, found := g.tarMap[cname]; found && g.Pers == Component {
+					g.tarMap[cname] = vid
 				}
 			}
 			for _, component := range container.Components {
@@ -231,6 +508,7 @@ func (g *Graph) Init() int {
 					g.vertices[vid] = Vertice{
 						Entity: component,
 						Kind:   VerticeTypeComponent,
+						Path:   comName,
 					}
 				}
 			}
@@ -243,6 +521,19 @@ func (g *Graph) Init() int {
 			g.vertices[vid] = Vertice{
 				Entity: esys,
 				Kind:   VerticeTypeExternalSystem,
+				Path:   esys.Name,
+			}
+		}
+		for _, container := range esys.Containers {
+			cname := fmt.Sprintf("%s.%s", esys.Name, container.Name)
+			if _, ok := g.vids[cname]; !ok {
+				vid := len(g.vids) + 1
+				g.vids[cname] = vid
+				g.vertices[vid] = Vertice{
+					Entity: container,
+					Kind:   VerticeTypeExternalContainer,
+					Path:   cname,
+				}
 			}
 		}
 	}
@@ -252,80 +543,54 @@ func (g *Graph) Init() int {
 	return len(g.vids)
 }
 
-//Analyse attempt to form a graph that is relevant to the render targets
+//Analyse attempt to form a graph that is relevant to the render targets, with the declared relations
+//and the relations rolled up between the parents of their elements
 func (g *Graph) Analyse() error {
 	if g.graph == nil {
 		return errors.New("Empty or un-initialized graph")
 	}
 	for _, relation := range g.Arc.Relations {
-		subjectChain := strings.Split(relation.Subject, ".")
-		objectChain := strings.Split(relation.Object, ".")
-		sid, ok := g.vids[relation.Subject]
-		if !ok {
-			return errors.New("Invalid Subject id found in relation")
-		}
-		oid, ok := g.vids[relation.Object]
-		if !ok {
-			return errors.New("Invalid Object id found in relation")
-		}
-		ename := fmt.Sprintf("%s&%s", relation.Subject, relation.Object)
-
-		//Decide which views this path should be shown
-		views := make(map[Perspective]bool, 0)
-		switch len(subjectChain) + len(objectChain) {
-		case 2:
-			views[Landscape] = true
-			views[Context] = true
-		case 3:
-			views[Container] = true
-		case 4:
-			views[Container] = true
-		default:
-			views[Component] = true
-		}
-
-		id, ok := g.eids[ename]
-		if !ok {
-			edgeID := int64(len(g.eids) + 1)
-			g.eids[ename] = edgeID
-			g.edges[edgeID] = edge{relation, views}
-			g.graph.AddBothCost(sid, oid, g.eids[ename])
-		} else {
-			if strings.Contains(g.edges[id].relation.Pointer, DefaultDependencyPointer) {
-				g.edges[id] = edge{relation, views}
-			}
+		if _, ok := g.vids[relation.Subject]; !ok {
+			return fmt.Errorf("Invalid Subject id %s found in relation", relation.Subject)
 		}
-
-		//Add parent dependency if not exists
-
-		if len(subjectChain) > 1 || len(objectChain) > 1 {
-			parentSubjectID := subjectChain[0]
-			parentObjectID := objectChain[0]
-			if parentObjectID == parentSubjectID {
-				continue
-			}
-			parentEname := fmt.Sprintf("%s&%s", parentSubjectID, parentObjectID)
-			_, ok := g.eids[parentEname]
-			if !ok {
-				pEdgeID := int64(len(g.eids) + 1)
-				g.eids[parentEname] = pEdgeID
-				v := make(map[Perspective]bool, 2)
-				v[Context] = true
-				v[Landscape] = true
-				g.edges[pEdgeID] = edge{
-					relation: model.Relation{
-						Subject: parentSubjectID,
-						Object:  parentObjectID,
-						Pointer: fmt.Sprintf("%s:%s", DefaultDependencyPointer, relation.Pointer)},
-					views: v,
-				}
-				g.graph.AddBothCost(g.vids[parentSubjectID], g.vids[parentObjectID], pEdgeID)
-			}
+		if _, ok := g.vids[relation.Object]; !ok {
+			return fmt.Errorf("Invalid Object id %s found in relation", relation.Object)
+		}
+		g.addEdge(relation, nil)
+		for _, rolled := range g.rollUp(relation) {
+			g.addEdge(rolled, &relation)
+		}
+	}
+	for eid, e := range g.edges {
+		if len(e.rolled) > 0 {
+			e.relation = summarise(e.relation, e.rolled)
+			g.edges[eid] = e
 		}
 	}
 	return nil
 }
 
+//addEdge add the edge of a declared relation, or of a relation rolled up from a declared child relation.
+//The first declared relation between two elements is drawn, in place of the relations rolled up between them.
+func (g *Graph) addEdge(relation model.Relation, child *model.Relation) {
+	ename := fmt.Sprintf("%s&%s", relation.Subject, relation.Object)
+	id, ok := g.eids[ename]
+	if !ok {
+		id = int64(len(g.eids) + 1)
+		g.eids[ename] = id
+		g.edges[id] = edge{relation: relation, views: g.views(relation)}
+		g.graph.AddBothCost(g.vids[relation.Subject], g.vids[relation.Object], id)
+	}
+	e := g.edges[id]
+	switch {
+	case child != nil && (!ok || len(e.rolled) > 0):
+		e.rolled = append(e.rolled, *child)
+	case child == nil && len(e.rolled) > 0:
+		e = edge{relation: relation, views: e.views}
+	}
+	g.edges[id] = e
+}
+
 //Process the render request to build a Graph to visualize
 func Process(ctx context.Context, req *model.RenderRequest) (*Graph, error) {
 	res := &Graph{}
@@ -350,6 +615,9 @@ func Process(ctx context.Context, req *model.RenderRequest) (*Graph, error) {
 			log.Printf("Fail to decode data: %v", err)
 			return nil, err
 		}
+		if len(res.Arc.Include) > 0 {
+			return nil, fmt.Errorf("Unresolved includes %s, the included arc files must be merged before rendering", strings.Join(res.Arc.Include, ", "))
+		}
 	case model.ArcDataFormat_JSON:
 	case model.ArcDataFormat_PUML:
 		return nil, errors.New("Json and Puml direct render request are not supported for now")
@@ -357,7 +625,41 @@ func Process(ctx context.Context, req *model.RenderRequest) (*Graph, error) {
 		return nil, errors.New("Unsupported data format")
 	}
 
-	res.tars = req.GetTarget()
+	//targets prefixed with ! are exclusions
+	for _, tar := range req.GetTarget() {
+		if strings.HasPrefix(tar, "!") {
+			res.Exclude = append(res.Exclude, tar)
+			continue
+		}
+		res.tars = append(res.tars, tar)
+	}
+	res.Exclude = append(res.Exclude, req.GetExclude()...)
+	if err := res.checkExclusions(); err != nil {
+		return nil, err
+	}
+	res.HideUsers, res.HideExternalSystems = req.GetHideUsers(), req.GetHideExternalSystems()
+	switch req.GetRollUp() {
+	case model.RollUp_CONTAINER_ROLL_UP:
+		res.RollUp = ContainerRollUp
+	case model.RollUp_NO_ROLL_UP:
+		res.RollUp = NoRollUp
+	}
+	switch req.GetRelationLevel() {
+	case model.RelationLevel_SYSTEM_LEVEL:
+		res.RelationLevel = SystemLevel
+	case model.RelationLevel_CONTAINER_LEVEL:
+		res.RelationLevel = ContainerLevel
+	}
+	if res.Focus = req.GetFocus(); res.Focus != "" {
+		res.Pers, res.tars = Focus, nil
+		res.Depth = int(req.GetDepth())
+		switch req.GetDirection() {
+		case model.FocusDirection_OUTGOING:
+			res.Direction = FocusOutgoing
+		case model.FocusDirection_INCOMING:
+			res.Direction = FocusIncoming
+		}
+	}
 
 	switch req.GetVisualFormat() {
 	case model.ArcVisualFormat_SVG:
@@ -370,13 +672,41 @@ func Process(ctx context.Context, req *model.RenderRequest) (*Graph, error) {
 		return nil, errors.New("Unsupported visual output type")
 	}
 
+	res.filter()
 	if res.Init() == 0 {
 		return res, errors.New("Empty element")
 	}
+	for tar, tid := range res.tarMap {
+		if tid == 0 {
+			return res, fmt.Errorf("Unknown target %s", tar)
+		}
+	}
 
 	if err := res.Analyse(); err != nil {
 		return res, err
 	}
+	res.filterRelations()
+	if res.Pers == Focus {
+		if err := res.analyseFocus(); err != nil {
+			return res, err
+		}
+	}
 
 	return res, nil
 }
+
+//EncodeID turn an element path into an identifier valid in the diagram languages, PlantUML aliases and mermaid node ids.
+//Letters and digits are kept and any other byte is hex encoded after an underscore,
+//so distinct paths always give distinct identifiers, and "__" never appear in an identifier.
+func EncodeID(path string) string {
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "_%02x", c)
+	}
+	return sb.String()
+}
@@ -3,6 +3,7 @@ package analyzer
 import (
 	"context"
 	"log"
+	"reflect"
 	"testing"
 
 	"github.com/koderizer/arc/model"
@@ -299,3 +300,313 @@ func TestGetRelations(t *testing.T) {
 		}
 	}
 }
+
+func TestGetComponentView(t *testing.T) {
+	componentArc := model.ArcType{
+		App:  "test",
+		Desc: "Test component",
+		Users: []model.User{
+			{Name: "u1"},
+		},
+		InternalSystems: []model.InternalSystem{
+			{
+				Name: "s1",
+				Containers: []model.Container{
+					{
+						Name: "c1",
+						Components: []model.Component{
+							{Name: "k1"},
+							{Name: "k2"},
+						},
+					},
+					{Name: "c2"},
+				},
+			},
+		},
+		ExternalSystems: []model.ExternalSystem{
+			{Name: "e1"},
+		},
+		Relations: []model.Relation{
+			{Subject: "u1", Object: "s1.c1.k1", Pointer: "use"},
+			{Subject: "s1.c1.k1", Object: "s1.c1.k2", Pointer: "call"},
+			{Subject: "s1.c1.k2", Object: "e1", Pointer: "call"},
+			{Subject: "s1.c2", Object: "s1.c1.k2", Pointer: "call"},
+			{Subject: "s1.c2", Object: "e1", Pointer: "call"},
+		},
+	}
+	data, err := componentArc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_COMPONENT,
+		Data:         data,
+		Target:       []string{"s1.c1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers, err := g.GetContainers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := containers["s1.c1"]; !ok || len(containers) != 1 {
+		t.Errorf("Expect only target container s1.c1, get %+v", containers)
+	}
+	neighbors, err := g.GetNeighbors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"u1", "e1", "s1.c2"} {
+		if _, ok := neighbors[path]; !ok {
+			t.Errorf("Expect neighbor %s", path)
+		}
+	}
+	if len(neighbors) != 3 {
+		t.Errorf("Expect 3 neighbors, get %d", len(neighbors))
+	}
+	relations, err := g.GetRelations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relations) != 4 {
+		t.Fatalf("Expect 4 relations, get %d", len(relations))
+	}
+	for i, r := range relations {
+		if e := componentArc.Relations[i]; r.Subject != e.Subject || r.Object != e.Object || r.Pointer != e.Pointer {
+			t.Errorf("Relation %d mismatch, expect %+v get %+v", i, componentArc.Relations[i], r)
+		}
+	}
+
+	if _, err := Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_COMPONENT,
+		Data:         data,
+		Target:       []string{"s1.c3"},
+	}); err == nil {
+		t.Error("Expect error on unknown target")
+	}
+}
+
+func TestGetExternalContainers(t *testing.T) {
+	externalArc := model.ArcType{
+		App:   "test",
+		Desc:  "Test external containers",
+		Users: []model.User{{Name: "partner-ops", External: true}},
+		InternalSystems: []model.InternalSystem{
+			{Name: "s1", Containers: []model.Container{{Name: "api"}, {Name: "hook", Components: []model.Component{{Name: "k1"}}}}},
+		},
+		ExternalSystems: []model.ExternalSystem{
+			{Name: "partner", Containers: []model.Container{{Name: "gateway"}, {Name: "sender"}, {Name: "portal"}}},
+			{Name: "e1"},
+		},
+		Relations: []model.Relation{
+			{Subject: "s1.api", Object: "partner.gateway", Pointer: "call"},
+			{Subject: "partner.sender", Object: "s1.hook", Pointer: "notify"},
+			{Subject: "partner.sender", Object: "s1.hook.k1", Pointer: "notify"},
+			{Subject: "s1.api", Object: "e1", Pointer: "call"},
+			{Subject: "partner-ops", Object: "partner.portal", Pointer: "use"},
+		},
+	}
+	data, err := externalArc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	process := func(pers model.PresentationPerspective, targets ...string) *Graph {
+		g, err := Process(context.Background(), &model.RenderRequest{
+			DataFormat:   model.ArcDataFormat_ARC,
+			VisualFormat: model.ArcVisualFormat_SVG,
+			Perspective:  pers,
+			Data:         data,
+			Target:       targets,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	systems, err := process(model.PresentationPerspective_CONTAINER, "s1").GetExternalSystems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(systems) != 2 || systems[0].Name != "e1" || systems[1].Name != "partner" {
+		t.Fatalf("Expect external systems e1 and partner once each, get %+v", systems)
+	}
+	if c := systems[1].Containers; len(c) != 2 || c[0].Name != "gateway" || c[1].Name != "sender" {
+		t.Errorf("Expect only the related partner containers gateway and sender, get %+v", c)
+	}
+
+	systems, err = process(model.PresentationPerspective_CONTEXT, "s1").GetExternalSystems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(systems) != 2 || len(systems[0].Containers)+len(systems[1].Containers) != 0 {
+		t.Errorf("Expect external systems without containers in context view, get %+v", systems)
+	}
+
+	neighbors, err := process(model.PresentationPerspective_COMPONENT, "s1.hook").GetNeighbors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := neighbors["partner.sender"]; !ok || v.Kind != VerticeTypeExternalContainer {
+		t.Errorf("Expect external container neighbor partner.sender, get %+v", neighbors)
+	}
+}
+
+func TestGroupTargets(t *testing.T) {
+	groupArc := model.ArcType{
+		App:  "test",
+		Desc: "Test groups",
+		InternalSystems: []model.InternalSystem{
+			{Name: "ledger", Containers: []model.Container{{Name: "api", Components: []model.Component{{Name: "k1"}}}}},
+			{Name: "checkout"},
+			{Name: "search"},
+		},
+		Groups: []model.Group{
+			{Name: "commerce", Systems: []string{"checkout"}, Groups: []model.Group{
+				{Name: "payments", Systems: []string{"ledger"}},
+			}},
+			{Name: "discovery", Systems: []string{"search"}},
+		},
+		Relations: []model.Relation{
+			{Subject: "checkout", Object: "ledger", Pointer: "pay"},
+			{Subject: "search", Object: "checkout", Pointer: "link"},
+		},
+	}
+	data, err := groupArc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	process := func(pers model.PresentationPerspective, targets ...string) *Graph {
+		g, err := Process(context.Background(), &model.RenderRequest{
+			DataFormat:   model.ArcDataFormat_ARC,
+			VisualFormat: model.ArcVisualFormat_SVG,
+			Perspective:  pers,
+			Data:         data,
+			Target:       targets,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	view, err := process(model.PresentationPerspective_CONTEXT, "payments").View()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.InternalSystems) != 1 || view.InternalSystems[0].Name != "ledger" {
+		t.Errorf("Expect the payments system ledger, get %+v", view.InternalSystems)
+	}
+	if len(view.Groups) != 1 || view.Groups[0].Name != "commerce" || len(view.Groups[0].Systems) != 0 || len(view.Groups[0].Groups) != 1 {
+		t.Errorf("Expect only the commerce group holding the payments group, get %+v", view.Groups)
+	}
+
+	view, err = process(model.PresentationPerspective_CONTEXT, "commerce").View()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.InternalSystems) != 2 || len(view.Groups) != 1 {
+		t.Errorf("Expect the commerce systems in the commerce group only, get %+v in %+v", view.InternalSystems, view.Groups)
+	}
+
+	containers, err := process(model.PresentationPerspective_COMPONENT, "payments").GetContainers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := containers["ledger.api"]; !ok || len(containers) != 1 {
+		t.Errorf("Expect the payments container ledger.api with components, get %+v", containers)
+	}
+
+	if _, err := Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_CONTEXT,
+		Data:         data,
+		Target:       []string{"unknown"},
+	}); err == nil {
+		t.Error("Expect an error for an unknown target")
+	}
+}
+
+func TestUnresolvedIncludes(t *testing.T) {
+	included := model.ArcType{App: "test", Include: []string{"./other.yaml"}}
+	data, err := included.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_LANDSCAPE,
+		Data:         data,
+	}); err == nil {
+		t.Error("Expect an error for an arc with unresolved includes")
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	g, err := Process(context.Background(), prepData(model.PresentationPerspective_LANDSCAPE, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := func(vertices []Vertice) []string {
+		res := make([]string, 0, len(vertices))
+		for _, v := range vertices {
+			res = append(res, v.Path)
+		}
+		return res
+	}
+	if top := paths(g.Children("")); !reflect.DeepEqual(top, []string{"u1", "u2", "s1", "s2", "s3", "e1", "e2"}) {
+		t.Errorf("Expect the top level elements in order, get %v", top)
+	}
+	if containers := paths(g.Children("s2")); !reflect.DeepEqual(containers, []string{"s2.c1", "s2.c2"}) {
+		t.Errorf("Expect the containers of s2, get %v", containers)
+	}
+	if v, ok := g.Element("s1.c1"); !ok || v.Kind != VerticeTypeContainer {
+		t.Errorf("Expect s1.c1 to be a container, get %+v", v)
+	}
+	if _, ok := g.Element("s1.c3"); ok {
+		t.Error("Expect no element s1.c3")
+	}
+
+	out, in := g.ElementRelations("s2")
+	if len(out) != 2 || out[0].Object != "e2" || out[1].Object != "e1" {
+		t.Errorf("Expect s2 to point to e2 and e1, get %+v", out)
+	}
+	//the call of s1.c1 to s2.c1 is already rolled up in the relation of s1 to s2
+	if len(in) != 2 || in[0].Subject != "u2" || in[1].Subject != "s1" {
+		t.Errorf("Expect s2 to be used by u2 and s1, get %+v", in)
+	}
+	if out, in := g.ElementRelations("s2.c1"); len(out) != 0 || len(in) != 1 || in[0].Subject != "s1.c1" {
+		t.Errorf("Expect s2.c1 to be called by s1.c1, get %+v %+v", out, in)
+	}
+}
+
+func TestEncodeID(t *testing.T) {
+	var idTests = []struct {
+		in  string
+		out string
+	}{
+		{"ab", "ab"},
+		{"a-b", "a_2db"},
+		{"a_b", "a_5fb"},
+		{"a.b", "a_2eb"},
+		{"a b\")", "a_20b_22_29"},
+	}
+	ids := make(map[string]string)
+	for _, tt := range idTests {
+		actual := EncodeID(tt.in)
+		if actual != tt.out {
+			t.Errorf("EncodeID(%q) expect %s, actual %s", tt.in, tt.out, actual)
+		}
+		if other, found := ids[actual]; found {
+			t.Errorf("EncodeID collision between %q and %q", tt.in, other)
+		}
+		ids[actual] = tt.in
+	}
+}
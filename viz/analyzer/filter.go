@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+)
+
+//RelationLevel is the most detailed level of the elements whose relations are shown
+type RelationLevel int
+
+//RelationLevel constants, users and systems being at the system level
+const (
+	AllLevels      RelationLevel = 0
+	SystemLevel    RelationLevel = 1
+	ContainerLevel RelationLevel = 2
+	ComponentLevel RelationLevel = 3
+)
+
+//level return the level of an element kind
+func level(kind VerticeType) RelationLevel {
+	switch kind {
+	case VerticeTypeContainer, VerticeTypeExternalContainer:
+		return ContainerLevel
+	case VerticeTypeComponent:
+		return ComponentLevel
+	}
+	return SystemLevel
+}
+
+//checkExclusions return an error for the first malformed exclusion pattern, which would otherwise exclude nothing
+func (g *Graph) checkExclusions() error {
+	for _, pattern := range g.Exclude {
+		if _, err := path.Match(strings.TrimPrefix(pattern, "!"), ""); err != nil {
+			return fmt.Errorf("Invalid exclusion %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+//excluded tell whether the element at the given path, or an element it is part of, match one of the exclusion patterns,
+//checked beforehand
+func (g *Graph) excluded(p string) bool {
+	for _, pattern := range g.Exclude {
+		pattern = strings.TrimPrefix(pattern, "!")
+		for parent := p; ; parent = parent[:strings.LastIndex(parent, ".")] {
+			if matched, _ := path.Match(pattern, parent); matched {
+				return true
+			}
+			if !strings.Contains(parent, ".") {
+				break
+			}
+		}
+	}
+	return false
+}
+
+//filter leave out of the architecture the excluded elements with their children, the hidden users and external systems,
+//and the relations they take part in, before the graph is built
+func (g *Graph) filter() {
+	if len(g.Exclude) == 0 && !g.HideUsers && !g.HideExternalSystems {
+		return
+	}
+	arc := *g.Arc
+	hidden := make(map[string]bool)
+	containers := func(parent string, conts []model.Container) []model.Container {
+		kept := make([]model.Container, 0, len(conts))
+		for _, cont := range conts {
+			cpath := parent + "." + cont.Name
+			if g.excluded(cpath) {
+				hidden[cpath] = true
+				continue
+			}
+			comps := make([]model.Component, 0, len(cont.Components))
+			for _, comp := range cont.Components {
+				if g.excluded(cpath + "." + comp.Name) {
+					hidden[cpath+"."+comp.Name] = true
+					continue
+				}
+				comps = append(comps, comp)
+			}
+			if len(cont.Components) > 0 {
+				cont.Components = comps
+			}
+			kept = append(kept, cont)
+		}
+		return kept
+	}
+	arc.Users = make([]model.User, 0, len(g.Arc.Users))
+	for _, user := range g.Arc.Users {
+		if g.HideUsers || g.excluded(user.Name) {
+			hidden[user.Name] = true
+			continue
+		}
+		arc.Users = append(arc.Users, user)
+	}
+	arc.InternalSystems = make([]model.InternalSystem, 0, len(g.Arc.InternalSystems))
+	for _, iSys := range g.Arc.InternalSystems {
+		if g.excluded(iSys.Name) {
+			hidden[iSys.Name] = true
+			continue
+		}
+		iSys.Containers = containers(iSys.Name, iSys.Containers)
+		arc.InternalSystems = append(arc.InternalSystems, iSys)
+	}
+	arc.ExternalSystems = make([]model.ExternalSystem, 0, len(g.Arc.ExternalSystems))
+	for _, eSys := range g.Arc.ExternalSystems {
+		if g.HideExternalSystems || g.excluded(eSys.Name) {
+			hidden[eSys.Name] = true
+			continue
+		}
+		eSys.Containers = containers(eSys.Name, eSys.Containers)
+		arc.ExternalSystems = append(arc.ExternalSystems, eSys)
+	}
+	isHidden := func(p string) bool {
+		for parent := range hidden {
+			if within(p, parent) {
+				return true
+			}
+		}
+		return false
+	}
+	arc.Relations = make([]model.Relation, 0, len(g.Arc.Relations))
+	for _, rel := range g.Arc.Relations {
+		if isHidden(rel.Subject) || isHidden(rel.Object) {
+			continue
+		}
+		arc.Relations = append(arc.Relations, rel)
+	}
+	g.Arc = &arc
+}
+
+//filterRelations hide the edges of the elements more detailed than the requested level from all views, once the declared
+//relations are rolled up, so that the relations of containers and components still show between their systems
+func (g *Graph) filterRelations() {
+	if g.RelationLevel == AllLevels {
+		return
+	}
+	for eid, e := range g.edges {
+		if !g.shownLevel(e.relation) {
+			e.views = map[Perspective]bool{}
+			g.edges[eid] = e
+		}
+	}
+}
+
+//shownLevel tell whether both elements of a relation are at most as detailed as the requested level
+func (g *Graph) shownLevel(rel model.Relation) bool {
+	if g.RelationLevel == AllLevels {
+		return true
+	}
+	for _, p := range []string{rel.Subject, rel.Object} {
+		if vid, ok := g.vids[p]; ok && level(g.vertices[vid].Kind) > g.RelationLevel {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+)
+
+func TestFilter(t *testing.T) {
+	data, err := queried.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		req       model.RenderRequest
+		hidden    []string
+		relations []string
+	}{
+		{
+			req:       model.RenderRequest{Exclude: []string{"arc-intel"}},
+			hidden:    []string{"arc-intel", "arc-intel.api.inspector"},
+			relations: []string{"use", "call", "pull"},
+		},
+		//targets prefixed with ! are exclusions, globs match the children of any element
+		{
+			req:       model.RenderRequest{Target: []string{"!arc.w*"}, Exclude: []string{"!*.update"}},
+			hidden:    []string{"arc.web", "arc-intel.api.update"},
+			relations: []string{"inspect", "read", "pull"},
+		},
+		{
+			req:       model.RenderRequest{HideUsers: true, HideExternalSystems: true},
+			hidden:    []string{"customer", "git"},
+			relations: []string{"call", "inspect", "write", "read"},
+		},
+	}
+	for i, test := range tests {
+		req := test.req
+		req.DataFormat, req.VisualFormat, req.Data = model.ArcDataFormat_ARC, model.ArcVisualFormat_SVG, data
+		req.Perspective = model.PresentationPerspective_LANDSCAPE
+		g, err := Process(context.Background(), &req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range test.hidden {
+			if _, ok := g.Element(path); ok {
+				t.Errorf("Test %d: expect %s to be left out", i, path)
+			}
+		}
+		pointers := make([]string, 0)
+		for _, rel := range g.Arc.Relations {
+			pointers = append(pointers, rel.Pointer)
+		}
+		if !reflect.DeepEqual(pointers, test.relations) {
+			t.Errorf("Test %d: expect relations %v, get %v", i, test.relations, pointers)
+		}
+	}
+	if len(queried.Relations) != 6 || len(queried.InternalSystems) != 2 {
+		t.Error("expect the filters to leave the given architecture untouched")
+	}
+	for _, req := range []model.RenderRequest{{Target: []string{"!arc.["}}, {Exclude: []string{"arc-intel", "*.api\\"}}} {
+		req.DataFormat, req.VisualFormat, req.Data = model.ArcDataFormat_ARC, model.ArcVisualFormat_SVG, data
+		req.Perspective = model.PresentationPerspective_LANDSCAPE
+		if _, err := Process(context.Background(), &req); err == nil {
+			t.Errorf("expect an error for the malformed exclusions %v %v", req.Target, req.Exclude)
+		}
+	}
+}
+
+func TestRelationLevel(t *testing.T) {
+	levelArc := model.ArcType{
+		App:             "test",
+		Users:           []model.User{{Name: "u1"}},
+		InternalSystems: []model.InternalSystem{{Name: "s1", Containers: []model.Container{{Name: "c1", Components: []model.Component{{Name: "k1"}}}}}},
+		ExternalSystems: []model.ExternalSystem{{Name: "s2", Containers: []model.Container{{Name: "c2"}}}},
+		Relations: []model.Relation{
+			{Subject: "u1", Pointer: "use", Object: "s1.c1"},
+			{Subject: "s1.c1", Pointer: "calls", Object: "s2.c2"},
+			{Subject: "s1.c1.k1", Pointer: "query", Object: "s2.c2"},
+		},
+	}
+	data, err := levelArc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pers      model.PresentationPerspective
+		target    string
+		level     model.RelationLevel
+		relations []string
+	}{
+		{model.PresentationPerspective_LANDSCAPE, "", model.RelationLevel_ALL_LEVELS, []string{"u1 use s1", "s1 2 relations: calls, query s2"}},
+		//the relations rolled up between systems are kept when the relations of their children are hidden
+		{model.PresentationPerspective_LANDSCAPE, "", model.RelationLevel_SYSTEM_LEVEL, []string{"u1 use s1", "s1 2 relations: calls, query s2"}},
+		{model.PresentationPerspective_CONTAINER, "s1", model.RelationLevel_ALL_LEVELS, []string{"u1 use s1.c1", "s1.c1 calls s2.c2"}},
+		{model.PresentationPerspective_CONTAINER, "s1", model.RelationLevel_SYSTEM_LEVEL, []string{}},
+		{model.PresentationPerspective_COMPONENT, "s1.c1", model.RelationLevel_ALL_LEVELS, []string{"s1.c1.k1 query s2.c2"}},
+		{model.PresentationPerspective_COMPONENT, "s1.c1", model.RelationLevel_CONTAINER_LEVEL, []string{}},
+	}
+	for i, test := range tests {
+		req := &model.RenderRequest{
+			DataFormat:    model.ArcDataFormat_ARC,
+			VisualFormat:  model.ArcVisualFormat_SVG,
+			Perspective:   test.pers,
+			Data:          data,
+			RelationLevel: test.level,
+		}
+		if test.target != "" {
+			req.Target = []string{test.target}
+		}
+		g, err := Process(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels, err := g.GetRelations()
+		if err != nil {
+			t.Fatal(err)
+		}
+		relations := make([]string, 0, len(rels))
+		for _, rel := range rels {
+			relations = append(relations, rel.Subject+" "+rel.Pointer+" "+rel.Object)
+		}
+		if !reflect.DeepEqual(relations, test.relations) {
+			t.Errorf("Test %d: expect relations %v, get %v", i, test.relations, relations)
+		}
+	}
+}
+
+func TestFilterGroup(t *testing.T) {
+	arc := queried
+	arc.Groups = []model.Group{{Name: "tools", Systems: []string{"arc", "arc-intel"}}}
+	data, err := arc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_CONTEXT,
+		Data:         data,
+		Target:       []string{"tools", "!arc-intel"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if targets := g.targets(); !reflect.DeepEqual(targets, []string{"arc"}) {
+		t.Errorf("expect the excluded systems of a group not to be targeted, get %v", targets)
+	}
+}
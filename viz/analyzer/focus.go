@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+)
+
+//FocusDirection of the relations followed from the focus of a view to its neighbours
+type FocusDirection int
+
+//FocusDirection constants
+const (
+	FocusBoth     FocusDirection = 0
+	FocusOutgoing FocusDirection = 1
+	FocusIncoming FocusDirection = 2
+)
+
+//focus hold the elements of a Focus view: the focused element and its neighbours within the requested hops,
+//each shown at the level the relations reaching it refer to, unless an element it is part of is shown as well
+type focus struct {
+	//shown are the paths of the drawn elements
+	shown map[string]bool
+	//relations between the drawn elements, rolled up to them
+	relations []model.Relation
+}
+
+//within tell whether the element at path is the element at parent or one of its children
+func within(path, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".")
+}
+
+//analyseFocus collect the neighbours of the focused element up to the requested number of hops,
+//following the declared relations in the requested direction
+func (g *Graph) analyseFocus() error {
+	if _, ok := g.vids[g.Focus]; !ok {
+		return errors.New("Unknown focus " + g.Focus)
+	}
+	depth := g.Depth
+	if depth <= 0 {
+		depth = 1
+	}
+	reached := map[string]bool{g.Focus: true}
+	frontier := []string{g.Focus}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		next := make([]string, 0)
+		reach := func(path string) {
+			if !reached[path] {
+				reached[path] = true
+				next = append(next, path)
+			}
+		}
+		for _, path := range frontier {
+			for _, rel := range g.Arc.Relations {
+				s, o := within(rel.Subject, path), within(rel.Object, path)
+				if s && !o && g.Direction != FocusIncoming {
+					reach(rel.Object)
+				}
+				if o && !s && g.Direction != FocusOutgoing {
+					reach(rel.Subject)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	//an element is drawn as part of the outermost element reached it is within, the focus being drawn with its children
+	g.focus = focus{shown: make(map[string]bool)}
+	shownAs := func(path string) string {
+		if within(path, g.Focus) {
+			if g.focusedChildren() {
+				return path
+			}
+			return g.Focus
+		}
+		shown := path
+		for parent := range reached {
+			if within(path, parent) && len(parent) < len(shown) {
+				shown = parent
+			}
+		}
+		return shown
+	}
+	for path := range reached {
+		g.focus.shown[shownAs(path)] = true
+	}
+	if g.focusedChildren() {
+		for path := range g.vids {
+			if within(path, g.Focus) {
+				g.focus.shown[path] = true
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, rel := range g.Arc.Relations {
+		rel.Subject, rel.Object = shownAs(rel.Subject), shownAs(rel.Object)
+		if rel.Subject == rel.Object || !g.focus.shown[rel.Subject] || !g.focus.shown[rel.Object] || !g.shownLevel(rel) {
+			continue
+		}
+		key := strings.Join([]string{rel.Subject, rel.Pointer, rel.Technology, rel.Object}, "&")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		g.focus.relations = append(g.focus.relations, rel)
+	}
+	return nil
+}
+
+//focusedChildren tell whether the focus is drawn with the components it holds, or within its container when it is a component
+func (g *Graph) focusedChildren() bool {
+	v := g.vertices[g.vids[g.Focus]]
+	switch v.Kind {
+	case VerticeTypeComponent:
+		return true
+	case VerticeTypeContainer:
+		return len(v.Entity.(model.Container).Components) > 0
+	}
+	return false
+}
+
+//focusContainers return the container drawn as a boundary in a Focus view: the focused container with its components,
+//or the container of the focused component with only that component
+func (g *Graph) focusContainers() map[string]model.Container {
+	containers := make(map[string]model.Container, 1)
+	if !g.focusedChildren() {
+		return containers
+	}
+	v := g.vertices[g.vids[g.Focus]]
+	if v.Kind == VerticeTypeContainer {
+		containers[v.Path] = v.Entity.(model.Container)
+		return containers
+	}
+	parent := v.Path[:strings.LastIndex(v.Path, ".")]
+	container := g.vertices[g.vids[parent]].Entity.(model.Container)
+	container.Components = []model.Component{v.Entity.(model.Component)}
+	containers[parent] = container
+	return containers
+}
+
+//focusNeighbors return the elements drawn in a Focus view outside of the boundary of the focus
+func (g *Graph) focusNeighbors() map[string]Vertice {
+	neighbors := make(map[string]Vertice, len(g.focus.shown))
+	containers := g.focusContainers()
+	for path := range g.focus.shown {
+		if _, boundary := containers[path]; boundary || (g.focusedChildren() && within(path, g.Focus)) {
+			continue
+		}
+		neighbors[path] = g.vertices[g.vids[path]]
+	}
+	return neighbors
+}
+
+//FocusElements return the paths of the elements drawn in a Focus view in the order of the architecture
+func (g *Graph) FocusElements() []string {
+	paths := make([]string, 0, len(g.focus.shown))
+	for path := range g.focus.shown {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return g.vids[paths[i]] < g.vids[paths[j]] })
+	return paths
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+)
+
+func focusGraph(t *testing.T, focus string, depth int32, direction model.FocusDirection) (*Graph, error) {
+	data, err := queried.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_CONTAINER,
+		Data:         data,
+		Focus:        focus,
+		Depth:        depth,
+		Direction:    direction,
+	})
+}
+
+func TestFocus(t *testing.T) {
+	tests := []struct {
+		focus     string
+		depth     int32
+		direction model.FocusDirection
+		elements  []string
+		relations []string
+	}{
+		{"arc.cli", 0, model.FocusDirection_BOTH,
+			[]string{"arc.cli", "arc.web", "arc-intel.api.inspector", "git"},
+			[]string{"arc.web call arc.cli", "arc.cli inspect arc-intel.api.inspector", "arc.cli pull git"}},
+		{"customer", 2, model.FocusDirection_OUTGOING,
+			[]string{"customer", "arc.cli", "arc.web"},
+			[]string{"customer use arc.web", "arc.web call arc.cli"}},
+		//the relations between the children of a system are drawn with the system
+		{"arc", 1, model.FocusDirection_INCOMING,
+			[]string{"customer", "arc"},
+			[]string{"customer use arc"}},
+		//a container is drawn with all its components
+		{"arc-intel.api", 1, model.FocusDirection_BOTH,
+			[]string{"arc.cli", "arc-intel.api", "arc-intel.api.inspector", "arc-intel.api.update", "arc-intel.api.unused", "arc-intel.db"},
+			[]string{"arc.cli inspect arc-intel.api.inspector", "arc-intel.api.update write arc-intel.db", "arc-intel.api.inspector read arc-intel.db"}},
+		{"arc-intel.db", 2, model.FocusDirection_INCOMING,
+			[]string{"arc.cli", "arc-intel.api.inspector", "arc-intel.api.update", "arc-intel.db"},
+			[]string{"arc.cli inspect arc-intel.api.inspector", "arc-intel.api.update write arc-intel.db", "arc-intel.api.inspector read arc-intel.db"}},
+	}
+	for _, test := range tests {
+		g, err := focusGraph(t, test.focus, test.depth, test.direction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Pers != Focus {
+			t.Errorf("expect a Focus view, get %v", g.Pers)
+		}
+		if elements := g.FocusElements(); !reflect.DeepEqual(elements, test.elements) {
+			t.Errorf("focus on %s: expect elements %v, get %v", test.focus, test.elements, elements)
+		}
+		rels, err := g.GetRelations()
+		if err != nil {
+			t.Fatal(err)
+		}
+		relations := make([]string, 0, len(rels))
+		for _, rel := range rels {
+			relations = append(relations, rel.Subject+" "+rel.Pointer+" "+rel.Object)
+		}
+		if !reflect.DeepEqual(relations, test.relations) {
+			t.Errorf("focus on %s: expect relations %v, get %v", test.focus, test.relations, relations)
+		}
+	}
+}
+
+func TestFocusBoundary(t *testing.T) {
+	g, err := focusGraph(t, "arc-intel.api.update", 1, model.FocusDirection_BOTH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers, err := g.GetContainers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api, ok := containers["arc-intel.api"]; len(containers) != 1 || !ok || len(api.Components) != 1 || api.Components[0].Name != "update" {
+		t.Errorf("expect a focused component drawn alone in its container, get %+v", containers)
+	}
+	neighbors, err := g.GetNeighbors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, len(neighbors))
+	for path := range neighbors {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if !reflect.DeepEqual(paths, []string{"arc-intel.db"}) {
+		t.Errorf("expect arc-intel.db as the only neighbor, get %v", paths)
+	}
+
+	if _, err := focusGraph(t, "arc.nowhere", 1, model.FocusDirection_BOTH); err == nil {
+		t.Error("expect an unknown focus to be rejected")
+	}
+}
@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"github.com/yourbasic/graph"
+)
+
+//Hop is a step of a path, from an element to the object of the relations leading to it
+type Hop struct {
+	From      string
+	To        string
+	Relations []model.Relation
+}
+
+//Path is the sequence of hops leading from an element to another
+type Path []Hop
+
+//paths hold the directed graph of the declared relations the paths between elements go through.
+//An element reached by a relation is left through its own relations, the relations of the elements it is part of,
+//and the relations of its children, eg a call to a component carries on with the relations of its container.
+type paths struct {
+	g       *Graph
+	graph   *graph.Immutable
+	hops    map[[2]int][]model.Relation
+	source  int
+	targets map[int]bool
+	//sink is a vertex every target leads to, to search a path to any of them
+	sink int
+}
+
+//newPaths build the graph of the relations between the elements, to search paths from an element to another or its children
+func (g *Graph) newPaths(from, to string) (*paths, error) {
+	if g.Arc == nil {
+		return nil, fmt.Errorf("Empty graph")
+	}
+	for _, p := range []string{from, to} {
+		if _, ok := g.vids[p]; !ok {
+			return nil, fmt.Errorf("unknown element %s", p)
+		}
+	}
+	p := &paths{g: g, hops: make(map[[2]int][]model.Relation), targets: make(map[int]bool), sink: len(g.vertices) + 1}
+	m := graph.New(len(g.vertices) + 2)
+	related := func(a, b string) bool {
+		return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+	}
+	for vid := 1; vid <= len(g.vertices); vid++ {
+		path := g.vertices[vid].Path
+		//the relations of the element itself take precedence over the relations of its parents and children
+		own := make(map[int][]model.Relation)
+		carried := make(map[int][]model.Relation)
+		for _, rel := range g.Arc.Relations {
+			oid := g.vids[rel.Object]
+			switch {
+			case oid == vid || !related(path, rel.Subject):
+				continue
+			case rel.Subject == path:
+				own[oid] = append(own[oid], rel)
+			default:
+				carried[oid] = append(carried[oid], rel)
+			}
+			m.AddCost(vid, oid, 1)
+		}
+		for oid, rels := range carried {
+			p.hops[[2]int{vid, oid}] = rels
+		}
+		for oid, rels := range own {
+			p.hops[[2]int{vid, oid}] = rels
+		}
+		if path == to || strings.HasPrefix(path, to+".") {
+			p.targets[vid] = true
+			m.AddCost(vid, p.sink, 0)
+		}
+	}
+	p.graph = graph.Sort(m)
+	p.source = g.vids[from]
+	return p, nil
+}
+
+//path return the hops along a sequence of vertices
+func (p *paths) path(vids []int) Path {
+	res := make(Path, 0, len(vids)-1)
+	for i := 1; i < len(vids); i++ {
+		res = append(res, Hop{
+			From:      p.g.vertices[vids[i-1]].Path,
+			To:        p.g.vertices[vids[i]].Path,
+			Relations: p.hops[[2]int{vids[i-1], vids[i]}],
+		})
+	}
+	return res
+}
+
+//ShortestPath return a path of the fewest hops through declared relations from an element to another or one of its children,
+//nil if there is none
+func (g *Graph) ShortestPath(from, to string) (Path, error) {
+	p, err := g.newPaths(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if p.targets[p.source] {
+		return Path{}, nil
+	}
+	vids, dist := graph.ShortestPath(p.graph, p.source, p.sink)
+	if dist < 0 {
+		return nil, nil
+	}
+	return p.path(vids[:len(vids)-1]), nil
+}
+
+//AllPaths return the paths through declared relations from an element to another or one of its children, visiting
+//no element twice, of at most maxHops hops, fewest hops first, up to limit paths
+func (g *Graph) AllPaths(from, to string, maxHops, limit int) ([]Path, error) {
+	p, err := g.newPaths(from, to)
+	if err != nil {
+		return nil, err
+	}
+	found := make([][]int, 0)
+	//searching with an increasing number of hops lists the shortest paths first
+	for hops := 1; hops <= maxHops && len(found) < limit; hops++ {
+		visited := map[int]bool{p.source: true}
+		var walk func(vids []int)
+		walk = func(vids []int) {
+			last := vids[len(vids)-1]
+			if len(vids)-1 == hops {
+				if p.targets[last] && len(found) < limit {
+					found = append(found, append([]int{}, vids...))
+				}
+				return
+			}
+			if p.targets[last] {
+				return
+			}
+			p.graph.Visit(last, func(w int, c int64) bool {
+				if w == p.sink || visited[w] {
+					return false
+				}
+				visited[w] = true
+				walk(append(vids, w))
+				visited[w] = false
+				return len(found) >= limit
+			})
+		}
+		if !p.targets[p.source] {
+			walk([]int{p.source})
+		}
+	}
+	res := make([]Path, 0, len(found))
+	for _, vids := range found {
+		res = append(res, p.path(vids))
+	}
+	return res, nil
+}
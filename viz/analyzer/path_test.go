@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//hops format a path as the elements it goes through with the pointers of each hop
+func hops(p Path) string {
+	parts := make([]string, 0, len(p))
+	for _, hop := range p {
+		pointers := make([]string, 0, len(hop.Relations))
+		for _, rel := range hop.Relations {
+			pointers = append(pointers, rel.Pointer)
+		}
+		parts = append(parts, hop.From+" -"+strings.Join(pointers, ",")+"-> "+hop.To)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestShortestPath(t *testing.T) {
+	g := queryGraph(t)
+	tests := []struct {
+		from, to string
+		expect   string
+	}{
+		{"customer", "arc-intel.db", "customer -use-> arc.web | arc.web -call-> arc.cli | arc.cli -inspect-> arc-intel.api.inspector | arc-intel.api.inspector -read-> arc-intel.db"},
+		//a system is left through the relations of its containers, and reached through any of its children
+		{"arc", "arc-intel", "arc -inspect-> arc-intel.api.inspector"},
+		{"customer", "git", "customer -use-> arc.web | arc.web -call-> arc.cli | arc.cli -pull-> git"},
+		{"arc-intel.db", "customer", ""},
+	}
+	for _, test := range tests {
+		p, err := g.ShortestPath(test.from, test.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := hops(p); actual != test.expect {
+			t.Errorf("%s to %s: expect %s, get %s", test.from, test.to, test.expect, actual)
+		}
+	}
+	if _, err := g.ShortestPath("customer", "unknown"); err == nil {
+		t.Error("expect an error for an unknown element")
+	}
+}
+
+func TestAllPaths(t *testing.T) {
+	g := queryGraph(t)
+	paths, err := g.AllPaths("arc", "arc-intel.db", 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := make([]string, 0)
+	for _, p := range paths {
+		actual = append(actual, hops(p))
+	}
+	expect := []string{
+		"arc -inspect-> arc-intel.api.inspector | arc-intel.api.inspector -read-> arc-intel.db",
+		"arc -call-> arc.cli | arc.cli -inspect-> arc-intel.api.inspector | arc-intel.api.inspector -read-> arc-intel.db",
+	}
+	if !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expect the shortest paths first\n%s\nget\n%s", strings.Join(expect, "\n"), strings.Join(actual, "\n"))
+	}
+	if paths, _ := g.AllPaths("arc", "arc-intel.db", 5, 1); len(paths) != 1 {
+		t.Errorf("expect the paths to be limited to 1, get %d", len(paths))
+	}
+	if paths, _ := g.AllPaths("arc", "arc-intel.db", 1, 10); len(paths) != 0 {
+		t.Errorf("expect no path of a single hop, get %d", len(paths))
+	}
+}
@@ -0,0 +1,510 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/koderizer/arc/model"
+)
+
+//QueryResult hold the elements or the relations matching a query
+type QueryResult struct {
+	Elements []Vertice
+	//Depths are the numbers of hops from the queried element to each element, for dependents and dependencies queries
+	Depths    []int
+	Relations []model.Relation
+}
+
+//Table return the result as columns and rows of text
+func (r *QueryResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0)
+	if r.Relations != nil {
+		for _, rel := range r.Relations {
+			rows = append(rows, []string{rel.Subject, rel.Pointer, rel.Technology, rel.Object})
+		}
+		return []string{"subject", "pointer", "technology", "object"}, rows
+	}
+	columns := []string{"path", "kind", "technology", "desc"}
+	if r.Depths != nil {
+		columns = append(columns, "depth")
+	}
+	for i, v := range r.Elements {
+		row := []string{v.Path, KindName(v), field(v, "technology"), field(v, "desc")}
+		if r.Depths != nil {
+			row = append(row, strconv.Itoa(r.Depths[i]))
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+//elementKinds map the element sets a query can start from to the kinds of their elements
+var elementKinds = map[string][]VerticeType{
+	"users":               {VerticeTypeUser},
+	"systems":             {VerticeTypeInternalSystem},
+	"external-systems":    {VerticeTypeExternalSystem},
+	"containers":          {VerticeTypeContainer},
+	"external-containers": {VerticeTypeExternalContainer},
+	"components":          {VerticeTypeComponent},
+	"elements": {VerticeTypeUser, VerticeTypeInternalSystem, VerticeTypeExternalSystem,
+		VerticeTypeContainer, VerticeTypeExternalContainer, VerticeTypeComponent},
+}
+
+//kindNames name the kinds of elements, as compared by the kind field of queries
+var kindNames = map[VerticeType]string{
+	VerticeTypeUser:              "user",
+	VerticeTypeInternalSystem:    "system",
+	VerticeTypeExternalSystem:    "external-system",
+	VerticeTypeContainer:         "container",
+	VerticeTypeExternalContainer: "external-container",
+	VerticeTypeComponent:         "component",
+}
+
+//KindName return the name of the kind of an element, eg external-system
+func KindName(v Vertice) string {
+	return kindNames[v.Kind]
+}
+
+//condition compare a field of an element or a relation to a value
+type condition struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+//Query evaluate a query over the elements and the declared relations of the graph, eg
+//
+//	containers where technology ~ "golang"
+//	relations from arc.* to arc-intel.*
+//	dependents(arc-intel.db, depth=2)
+//	components without relations
+func (g *Graph) Query(expr string) (*QueryResult, error) {
+	if g.Arc == nil {
+		return nil, fmt.Errorf("Empty graph")
+	}
+	p := &queryParser{tokens: tokenize(expr)}
+	subject := p.next()
+	var result *QueryResult
+	var err error
+	switch subject {
+	case "relations":
+		result, err = g.queryRelations(p)
+	case "dependents", "dependencies":
+		result, err = g.queryDependencies(p, subject == "dependents")
+	case "":
+		return nil, fmt.Errorf("empty query")
+	default:
+		kinds, ok := elementKinds[subject]
+		if !ok {
+			return nil, fmt.Errorf("unknown query %s, expect one of elements, users, systems, external-systems, containers, external-containers, components, relations, dependents or dependencies", subject)
+		}
+		result, err = g.queryElements(p, kinds)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.next(); tok != "" {
+		return nil, fmt.Errorf("unexpected %s at the end of the query", tok)
+	}
+	return result, nil
+}
+
+//queryElements select the elements of the given kinds, optionally without or with relations, matching the conditions
+func (g *Graph) queryElements(p *queryParser, kinds []VerticeType) (*QueryResult, error) {
+	related := ""
+	if tok := p.peek(); tok == "without" || tok == "with" {
+		related = p.next()
+		if err := p.expect("relations"); err != nil {
+			return nil, err
+		}
+	}
+	conds, err := p.where(elementFields)
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Elements: make([]Vertice, 0)}
+	for vid := 1; vid <= len(g.vertices); vid++ {
+		v := g.vertices[vid]
+		if !hasKind(v, kinds) {
+			continue
+		}
+		if related != "" && g.related(v.Path) != (related == "with") {
+			continue
+		}
+		if matchAll(conds, func(name string) []string { return fieldValues(v, name) }) {
+			result.Elements = append(result.Elements, v)
+		}
+	}
+	return result, nil
+}
+
+//queryRelations select the declared relations from and to elements matching path patterns, and matching the conditions
+func (g *Graph) queryRelations(p *queryParser) (*QueryResult, error) {
+	from, to := "*", "*"
+	for _, clause := range []string{"from", "to"} {
+		if p.peek() != clause {
+			continue
+		}
+		p.next()
+		pattern := p.next()
+		if pattern == "" {
+			return nil, fmt.Errorf("missing element pattern after %s", clause)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+		if clause == "from" {
+			from = pattern
+		} else {
+			to = pattern
+		}
+	}
+	conds, err := p.where(relationFields)
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Relations: make([]model.Relation, 0)}
+	for _, rel := range g.Arc.Relations {
+		if matchPath(from, rel.Subject) && matchPath(to, rel.Object) && matchAll(conds, func(name string) []string { return relationValues(rel, name) }) {
+			result.Relations = append(result.Relations, rel)
+		}
+	}
+	return result, nil
+}
+
+//queryDependencies select the elements depending on an element, or the elements it depends on, through declared relations up to a depth
+func (g *Graph) queryDependencies(p *queryParser, dependents bool) (*QueryResult, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	target := p.next()
+	if _, ok := g.vids[target]; !ok {
+		return nil, fmt.Errorf("unknown element %s", target)
+	}
+	depth := 1
+	if p.peek() == "," {
+		p.next()
+		if err := p.expect("depth"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(p.next())
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("depth must be a positive number")
+		}
+		depth = n
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	conds, err := p.where(elementFields)
+	if err != nil {
+		return nil, err
+	}
+	//the relations of the children of an element are its own
+	within := func(p, element string) bool { return p == element || strings.HasPrefix(p, element+".") }
+	found := map[string]int{target: 0}
+	frontier := []string{target}
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		next := make([]string, 0)
+		for _, element := range frontier {
+			for _, rel := range g.Arc.Relations {
+				near, far := rel.Object, rel.Subject
+				if !dependents {
+					near, far = far, near
+				}
+				if !within(near, element) || within(far, target) {
+					continue
+				}
+				if _, ok := found[far]; !ok {
+					found[far] = hop
+					next = append(next, far)
+				}
+			}
+		}
+		frontier = next
+	}
+	result := &QueryResult{Elements: make([]Vertice, 0), Depths: make([]int, 0)}
+	for hop := 1; hop <= depth; hop++ {
+		for vid := 1; vid <= len(g.vertices); vid++ {
+			v := g.vertices[vid]
+			if d, ok := found[v.Path]; ok && d == hop && matchAll(conds, func(name string) []string { return fieldValues(v, name) }) {
+				result.Elements = append(result.Elements, v)
+				result.Depths = append(result.Depths, hop)
+			}
+		}
+	}
+	return result, nil
+}
+
+//related tell whether a declared relation starts or ends at the element or one of its children
+func (g *Graph) related(element string) bool {
+	for _, rel := range g.Arc.Relations {
+		for _, end := range []string{rel.Subject, rel.Object} {
+			if end == element || strings.HasPrefix(end, element+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasKind(v Vertice, kinds []VerticeType) bool {
+	for _, k := range kinds {
+		if v.Kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+//matchPath match an element path against a pattern, where * stands for any characters including dots
+func matchPath(pattern, element string) bool {
+	ok, _ := path.Match(pattern, element)
+	return ok
+}
+
+var elementFields = []string{"name", "path", "kind", "technology", "desc", "role", "tag"}
+var relationFields = []string{"subject", "pointer", "technology", "object", "tag"}
+
+//fieldAliases map the short names of fields to their names
+var fieldAliases = map[string]string{"tech": "technology", "tags": "tag", "s": "subject", "p": "pointer", "o": "object"}
+
+//fieldValues return the values of a field of an element, a list for tags
+func fieldValues(v Vertice, name string) []string {
+	switch name {
+	case "path":
+		return []string{v.Path}
+	case "name":
+		return []string{v.Path[strings.LastIndex(v.Path, ".")+1:]}
+	case "kind":
+		return []string{KindName(v)}
+	case "tag":
+		switch e := v.Entity.(type) {
+		case model.User:
+			return e.Tags
+		case model.InternalSystem:
+			return e.Tags
+		case model.ExternalSystem:
+			return e.Tags
+		case model.Container:
+			return e.Tags
+		case model.Component:
+			return e.Tags
+		}
+		return nil
+	}
+	return []string{field(v, name)}
+}
+
+//field return the value of a text field of an element, empty when the element has no such field
+func field(v Vertice, name string) string {
+	switch e := v.Entity.(type) {
+	case model.User:
+		switch name {
+		case "desc":
+			return e.Desc
+		case "role":
+			return e.Role
+		}
+	case model.InternalSystem:
+		switch name {
+		case "desc":
+			return e.Desc
+		case "role":
+			return e.Role
+		}
+	case model.ExternalSystem:
+		switch name {
+		case "desc":
+			return e.Desc
+		case "role":
+			return e.Role
+		}
+	case model.Container:
+		switch name {
+		case "desc":
+			return e.Desc
+		case "role":
+			return e.Role
+		case "technology":
+			return e.Technology
+		}
+	case model.Component:
+		switch name {
+		case "desc":
+			return e.Desc
+		case "role":
+			return e.Role
+		case "technology":
+			return e.Technology
+		}
+	}
+	return ""
+}
+
+//relationValues return the values of a field of a relation, a list for tags
+func relationValues(rel model.Relation, name string) []string {
+	switch name {
+	case "subject":
+		return []string{rel.Subject}
+	case "pointer":
+		return []string{rel.Pointer}
+	case "technology":
+		return []string{rel.Technology}
+	case "object":
+		return []string{rel.Object}
+	case "tag":
+		return rel.Tags
+	}
+	return nil
+}
+
+//matchAll tell whether the values of the fields satisfy all conditions. A field with several values, the tags,
+//satisfy = and ~ when one of the values does, and != and !~ when none of the values would satisfy = and ~.
+func matchAll(conds []condition, values func(name string) []string) bool {
+	for _, c := range conds {
+		matched := false
+		for _, value := range values(c.field) {
+			if (c.re != nil && c.re.MatchString(value)) || (c.re == nil && value == c.value) {
+				matched = true
+				break
+			}
+		}
+		if matched == strings.HasPrefix(c.op, "!") {
+			return false
+		}
+	}
+	return true
+}
+
+//queryParser read the tokens of a query
+type queryParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *queryParser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		if got == "" {
+			got = "the end of the query"
+		}
+		return fmt.Errorf("expect %s, got %s", tok, got)
+	}
+	return nil
+}
+
+//where parse the optional conditions of a query, joined by and, on the given fields
+func (p *queryParser) where(fields []string) ([]condition, error) {
+	conds := make([]condition, 0)
+	if p.peek() != "where" {
+		return conds, nil
+	}
+	p.next()
+	for {
+		c := condition{field: p.next()}
+		if alias, ok := fieldAliases[c.field]; ok {
+			c.field = alias
+		}
+		known := false
+		for _, f := range fields {
+			known = known || f == c.field
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown field %s, expect one of %s", c.field, strings.Join(fields, ", "))
+		}
+		c.op = p.next()
+		switch c.op {
+		case "=", "!=", "~", "!~":
+		default:
+			return nil, fmt.Errorf("expect an operator =, !=, ~ or !~ after %s, got %s", c.field, c.op)
+		}
+		value := p.next()
+		if value == "" {
+			return nil, fmt.Errorf("missing value after %s %s", c.field, c.op)
+		}
+		c.value = unquote(value)
+		if strings.HasSuffix(c.op, "~") {
+			re, err := regexp.Compile(c.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %s: %v", c.value, err)
+			}
+			c.re = re
+		}
+		conds = append(conds, c)
+		if p.peek() != "and" {
+			return conds, nil
+		}
+		p.next()
+	}
+}
+
+//tokenize split a query in words, quoted strings, operators and punctuation
+func tokenize(expr string) []string {
+	tokens := make([]string, 0)
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			tokens = append(tokens, string(runes[i:j+1]))
+			i = j + 1
+		case r == '!' && i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '~'):
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case strings.ContainsRune("=~(),", r):
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("=~(),\"'", runes[j]) && !(runes[j] == '!' && j+1 < len(runes) && (runes[j+1] == '=' || runes[j+1] == '~')) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens
+}
+
+//unquote return the content of a quoted string token, other tokens as is
+func unquote(tok string) string {
+	if len(tok) >= 2 && (tok[0] == '"' || tok[0] == '\'') && tok[len(tok)-1] == tok[0] {
+		return strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\'`, `'`).Replace(tok[1 : len(tok)-1])
+	}
+	return tok
+}
@@ -0,0 +1,178 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+)
+
+var queried = model.ArcType{
+	App:   "shop",
+	Users: []model.User{{Name: "customer", Role: "Buy things"}},
+	InternalSystems: []model.InternalSystem{
+		{
+			Name: "arc",
+			Containers: []model.Container{
+				{Name: "cli", Technology: "golang", Tags: []string{"tool"}},
+				{Name: "web", Technology: "react"},
+			},
+		},
+		{
+			Name: "arc-intel",
+			Containers: []model.Container{
+				{
+					Name:       "api",
+					Technology: "golang-graphql",
+					Components: []model.Component{{Name: "inspector"}, {Name: "update"}, {Name: "unused"}},
+				},
+				{Name: "db", Technology: "dgraph"},
+			},
+		},
+	},
+	ExternalSystems: []model.ExternalSystem{{Name: "git", Desc: "Source control"}},
+	Relations: []model.Relation{
+		{Subject: "customer", Pointer: "use", Object: "arc.web"},
+		{Subject: "arc.web", Pointer: "call", Technology: "https", Object: "arc.cli"},
+		{Subject: "arc.cli", Pointer: "inspect", Technology: "graphql", Object: "arc-intel.api.inspector", Tags: []string{"sync"}},
+		{Subject: "arc-intel.api.update", Pointer: "write", Object: "arc-intel.db"},
+		{Subject: "arc-intel.api.inspector", Pointer: "read", Object: "arc-intel.db"},
+		{Subject: "arc.cli", Pointer: "pull", Object: "git"},
+	},
+}
+
+func queryGraph(t *testing.T) *Graph {
+	data, err := queried.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  model.PresentationPerspective_LANDSCAPE,
+		Data:         data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestQueryElements(t *testing.T) {
+	g := queryGraph(t)
+	tests := []struct {
+		query  string
+		expect []string
+	}{
+		{`containers where technology ~ "golang"`, []string{"arc.cli", "arc-intel.api"}},
+		{`containers where tech ~ "^golang$"`, []string{"arc.cli"}},
+		{`containers where tag = tool`, []string{"arc.cli"}},
+		{`containers where tag != tool and technology !~ golang`, []string{"arc.web", "arc-intel.db"}},
+		{`components without relations`, []string{"arc-intel.api.unused"}},
+		{`systems with relations`, []string{"arc", "arc-intel"}},
+		{`elements where kind = external-system`, []string{"git"}},
+		{`elements where name = 'db'`, []string{"arc-intel.db"}},
+		{`users where role ~ "(?i)buy"`, []string{"customer"}},
+	}
+	for _, test := range tests {
+		result, err := g.Query(test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		paths := make([]string, 0)
+		for _, v := range result.Elements {
+			paths = append(paths, v.Path)
+		}
+		if !reflect.DeepEqual(paths, test.expect) {
+			t.Errorf("%s: expect %v, get %v", test.query, test.expect, paths)
+		}
+	}
+}
+
+func TestQueryRelations(t *testing.T) {
+	g := queryGraph(t)
+	tests := []struct {
+		query  string
+		expect []string
+	}{
+		{`relations from arc.* to arc-intel.*`, []string{"inspect"}},
+		{`relations to arc-intel.db`, []string{"write", "read"}},
+		{`relations from arc.cli where tech != ""`, []string{"inspect"}},
+		{`relations where tag = sync or`, nil},
+		{`relations where p ~ "^(use|pull)$"`, []string{"use", "pull"}},
+	}
+	for _, test := range tests {
+		result, err := g.Query(test.query)
+		if test.expect == nil {
+			if err == nil {
+				t.Errorf("%s: expect an error", test.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		pointers := make([]string, 0)
+		for _, rel := range result.Relations {
+			pointers = append(pointers, rel.Pointer)
+		}
+		if !reflect.DeepEqual(pointers, test.expect) {
+			t.Errorf("%s: expect %v, get %v", test.query, test.expect, pointers)
+		}
+	}
+}
+
+func TestQueryDependencies(t *testing.T) {
+	g := queryGraph(t)
+	result, err := g.Query("dependents(arc-intel.db, depth=2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns, rows := result.Table()
+	expect := [][]string{
+		{"arc-intel.api.inspector", "component", "", "", "1"},
+		{"arc-intel.api.update", "component", "", "", "1"},
+		{"arc.cli", "container", "golang", "", "2"},
+	}
+	if columns[len(columns)-1] != "depth" || !reflect.DeepEqual(rows, expect) {
+		t.Errorf("expect %v, get %v %v", expect, columns, rows)
+	}
+
+	//the relations of the containers of a system are its own, and the elements within it are not listed
+	if result, err = g.Query("dependencies(arc) where kind = container"); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Elements) != 0 {
+		t.Errorf("expect no container arc depends on directly, get %+v", result.Elements)
+	}
+	if result, err = g.Query("dependencies(arc, depth=3)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, rows := result.Table(); len(rows) != 3 || rows[0][0] != "arc-intel.api.inspector" || rows[1][0] != "git" || rows[2][0] != "arc-intel.db" {
+		t.Errorf("expect the dependencies of arc in order of depth, get %v", rows)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	g := queryGraph(t)
+	for _, query := range []string{
+		"",
+		"things",
+		"containers where color = red",
+		"containers where technology",
+		"containers where technology ~ \"(\"",
+		"dependents(unknown)",
+		"dependents(arc, depth=0)",
+		"dependents(arc",
+		"components without",
+		"relations from",
+		"users extra",
+	} {
+		if _, err := g.Query(query); err == nil {
+			t.Errorf("expect an error for %q", query)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+)
+
+//RollUp tell how far the relations of containers and components are rolled up to their parents
+type RollUp int
+
+//RollUp constants
+const (
+	//FullRollUp roll the relations of components up to their containers, and the relations of both up to their systems
+	FullRollUp RollUp = 0
+	//ContainerRollUp only roll the relations of components up to their containers
+	ContainerRollUp RollUp = 1
+	//NoRollUp draw the declared relations only
+	NoRollUp RollUp = 2
+)
+
+//views return the perspectives a relation is drawn in, by the most detailed kind of element it relates
+func (g *Graph) views(rel model.Relation) map[Perspective]bool {
+	l := level(g.vertices[g.vids[rel.Subject]].Kind)
+	if o := level(g.vertices[g.vids[rel.Object]].Kind); o > l {
+		l = o
+	}
+	switch l {
+	case SystemLevel:
+		return map[Perspective]bool{Landscape: true, Context: true}
+	case ContainerLevel:
+		return map[Perspective]bool{Container: true}
+	default:
+		return map[Perspective]bool{Component: true}
+	}
+}
+
+//rollUp return the relations between the parents of the elements of a declared relation: between their containers
+//when any is a component, and between their systems when any is part of a system, unless they have the same parent
+func (g *Graph) rollUp(rel model.Relation) []model.Relation {
+	rolled := make([]model.Relation, 0, 2)
+	add := func(subject, object string) {
+		if subject == object || (subject == rel.Subject && object == rel.Object) {
+			return
+		}
+		for _, r := range rolled {
+			if r.Subject == subject && r.Object == object {
+				return
+			}
+		}
+		rolled = append(rolled, model.Relation{Subject: subject, Object: object})
+	}
+	if g.RollUp == NoRollUp {
+		return rolled
+	}
+	container := func(path string) string {
+		if g.vertices[g.vids[path]].Kind == VerticeTypeComponent {
+			return path[:strings.LastIndex(path, ".")]
+		}
+		return path
+	}
+	add(container(rel.Subject), container(rel.Object))
+	if g.RollUp == FullRollUp {
+		system := func(path string) string { return strings.SplitN(path, ".", 2)[0] }
+		add(system(rel.Subject), system(rel.Object))
+	}
+	return rolled
+}
+
+//summarise label a rolled up relation with the relations it rolls up: their number, pointers, technologies and tags
+func summarise(rel model.Relation, children []model.Relation) model.Relation {
+	distinct := func(values []string, seen map[string]bool, add ...string) []string {
+		for _, v := range add {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	var pointers, technologies, tags []string
+	seenPointers, seenTechnologies, seenTags := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	for _, child := range children {
+		pointers = distinct(pointers, seenPointers, child.Pointer)
+		technologies = distinct(technologies, seenTechnologies, child.Technology)
+		tags = distinct(tags, seenTags, child.Tags...)
+	}
+	rel.Pointer = strings.Join(pointers, ", ")
+	if len(children) > 1 {
+		rel.Pointer = fmt.Sprintf("%d relations: %s", len(children), rel.Pointer)
+	}
+	rel.Technology, rel.Tags = strings.Join(technologies, ", "), tags
+	return rel
+}
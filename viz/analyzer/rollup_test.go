@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+)
+
+func TestRollUp(t *testing.T) {
+	data, err := queried.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pers      model.PresentationPerspective
+		target    string
+		rollUp    model.RollUp
+		relations []string
+	}{
+		{model.PresentationPerspective_LANDSCAPE, "", model.RollUp_FULL_ROLL_UP,
+			[]string{"customer use [] arc", "arc inspect [graphql] arc-intel", "arc pull [] git"}},
+		//the declared relations of components are only drawn in component views
+		{model.PresentationPerspective_CONTAINER, "arc-intel", model.RollUp_FULL_ROLL_UP,
+			[]string{"arc.cli inspect [graphql] arc-intel.api", "arc-intel.api 2 relations: write, read [] arc-intel.db"}},
+		{model.PresentationPerspective_COMPONENT, "arc-intel.api", model.RollUp_FULL_ROLL_UP,
+			[]string{"arc.cli inspect [graphql] arc-intel.api.inspector", "arc-intel.api.update write [] arc-intel.db", "arc-intel.api.inspector read [] arc-intel.db"}},
+		{model.PresentationPerspective_LANDSCAPE, "", model.RollUp_CONTAINER_ROLL_UP, []string{}},
+		{model.PresentationPerspective_CONTAINER, "arc-intel", model.RollUp_CONTAINER_ROLL_UP,
+			[]string{"arc.cli inspect [graphql] arc-intel.api", "arc-intel.api 2 relations: write, read [] arc-intel.db"}},
+		{model.PresentationPerspective_CONTAINER, "arc-intel", model.RollUp_NO_ROLL_UP, []string{}},
+	}
+	for i, test := range tests {
+		req := &model.RenderRequest{
+			DataFormat:   model.ArcDataFormat_ARC,
+			VisualFormat: model.ArcVisualFormat_SVG,
+			Perspective:  test.pers,
+			Data:         data,
+			RollUp:       test.rollUp,
+		}
+		if test.target != "" {
+			req.Target = []string{test.target}
+		}
+		g, err := Process(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels, err := g.GetRelations()
+		if err != nil {
+			t.Fatal(err)
+		}
+		relations := make([]string, 0, len(rels))
+		for _, rel := range rels {
+			relations = append(relations, rel.Subject+" "+rel.Pointer+" ["+rel.Technology+"] "+rel.Object)
+		}
+		if !reflect.DeepEqual(relations, test.relations) {
+			t.Errorf("Test %d: expect relations %v, get %v", i, test.relations, relations)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	rel := summarise(model.Relation{Subject: "a", Object: "b"}, []model.Relation{
+		{Subject: "a.x", Pointer: "call", Technology: "grpc", Object: "b", Tags: []string{"sync"}},
+		{Subject: "a.y", Pointer: "call", Technology: "https", Object: "b.z"},
+		{Subject: "a.y", Pointer: "publish", Technology: "grpc", Object: "b", Tags: []string{"async", "sync"}},
+	})
+	expect := model.Relation{Subject: "a", Pointer: "3 relations: call, publish", Object: "b", Technology: "grpc, https", Tags: []string{"sync", "async"}}
+	if !reflect.DeepEqual(rel, expect) {
+		t.Errorf("expect %+v, get %+v", expect, rel)
+	}
+	if rel := summarise(model.Relation{Subject: "a", Object: "b"}, []model.Relation{{Pointer: "call"}}); rel.Pointer != "call" {
+		t.Errorf("expect a single relation rolled up with its own pointer, get %s", rel.Pointer)
+	}
+}
@@ -0,0 +1,448 @@
+//Package ascii provide utilities to draw an analysed architecture as boxes and arrows in a terminal
+package ascii
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+//Charset hold the characters a diagram is drawn with
+type Charset struct {
+	Horizontal  rune
+	Vertical    rune
+	TopLeft     rune
+	TopRight    rune
+	BottomLeft  rune
+	BottomRight rune
+	Tee         rune
+	Cross       rune
+	Arrow       rune
+	Pointer     rune
+}
+
+//ASCII draw diagrams with plain ascii characters, for terminals without unicode support
+var ASCII = Charset{
+	Horizontal:  '-',
+	Vertical:    '|',
+	TopLeft:     '+',
+	TopRight:    '+',
+	BottomLeft:  '+',
+	BottomRight: '+',
+	Tee:         '+',
+	Cross:       '+',
+	Arrow:       '<',
+	Pointer:     '>',
+}
+
+//Unicode draw diagrams with box drawing characters
+var Unicode = Charset{
+	Horizontal:  '─',
+	Vertical:    '│',
+	TopLeft:     '┌',
+	TopRight:    '┐',
+	BottomLeft:  '└',
+	BottomRight: '┘',
+	Tee:         '├',
+	Cross:       '┼',
+	Arrow:       '◀',
+	Pointer:     '▶',
+}
+
+//descWidth is the width descriptions are wrapped at within boxes
+const descWidth = 36
+
+//node is an element drawn as a box
+type node struct {
+	path  string
+	lines []string
+	//rank is the layer of the node, elements being drawn below the elements related to them
+	rank int
+	top  int
+	//ports are the rows of the right border of the box where relations start or end, in drawing order
+	ports []*port
+}
+
+//port is the end of a relation on the border of a box
+type port struct {
+	other *node
+	row   int
+}
+
+//edge is a relation drawn as an arrow along a vertical lane on the right of the boxes
+type edge struct {
+	relation model.Relation
+	from, to *node
+	out, in  *port
+	lane     int
+}
+
+//Generate draw the perspective analysed in the given graph as boxes stacked from top to bottom, related by arrows
+func Generate(g *analyzer.Graph, charset Charset) (string, error) {
+	d, err := collect(g)
+	if err != nil {
+		return "", err
+	}
+	if g.Pers == analyzer.Focus {
+		return d.draw(fmt.Sprintf("%s - focus on %s", g.Arc.App, g.Focus), charset), nil
+	}
+	return d.draw(fmt.Sprintf("%s - %s", g.Arc.App, perspectives[g.Pers]), charset), nil
+}
+
+//GeneratePath draw the elements along a path of the graph, related by the relations of each hop
+func GeneratePath(g *analyzer.Graph, path analyzer.Path, charset Charset) (string, error) {
+	if len(path) == 0 {
+		return "", errors.New("Empty path")
+	}
+	d := newDiagram()
+	relations := make([]model.Relation, 0, len(path))
+	for _, hop := range path {
+		for _, p := range []string{hop.From, hop.To} {
+			v, ok := g.Element(p)
+			if !ok {
+				return "", fmt.Errorf("Unknown element %s", p)
+			}
+			d.addVertice(v)
+		}
+		labels := make([]string, 0, len(hop.Relations))
+		for _, rel := range hop.Relations {
+			if rel.Subject != hop.From {
+				labels = append(labels, fmt.Sprintf("%s: %s", rel.Subject, label(rel)))
+				continue
+			}
+			labels = append(labels, label(rel))
+		}
+		relations = append(relations, model.Relation{Subject: hop.From, Pointer: strings.Join(labels, ", "), Object: hop.To})
+	}
+	d.relate(relations)
+	title := fmt.Sprintf("%s - path from %s to %s", g.Arc.App, path[0].From, path[len(path)-1].To)
+	return d.draw(title, charset), nil
+}
+
+//draw lay out the boxes and arrows of the diagram under a title
+func (d *diagram) draw(title string, charset Charset) string {
+	nodes, edges := d.nodes, d.edges
+	if len(nodes) == 0 {
+		return title + "\n"
+	}
+	rank(nodes, edges)
+
+	//boxes are as wide as the widest content, and high enough for their content and their ports
+	inner := 0
+	for _, n := range nodes {
+		for _, l := range n.lines {
+			if w := utf8.RuneCountInString(l); w > inner {
+				inner = w
+			}
+		}
+	}
+	width := inner + 4
+	height := 0
+	for _, n := range nodes {
+		n.top = height
+		rows := len(n.lines)
+		if len(n.ports) > rows {
+			rows = len(n.ports)
+		}
+		height += rows + 3
+	}
+	//ports are sorted by the position of the other end, now known, so arrows to the boxes above leave from the top rows
+	for _, n := range nodes {
+		sort.SliceStable(n.ports, func(i, j int) bool { return n.ports[i].other.top < n.ports[j].other.top })
+		for i, p := range n.ports {
+			p.row = n.top + 1 + i
+		}
+	}
+	lanes := assignLanes(edges)
+
+	c := newCanvas(height-1, width+2*lanes+3)
+	for _, n := range nodes {
+		c.box(n, width, charset)
+	}
+	labelColumn := width + 2*lanes + 2
+	for _, e := range edges {
+		c.arrow(e, width, charset)
+		text := fmt.Sprintf("%s %c %s", label(e.relation), charset.Pointer, e.to.path)
+		c.text(e.out.row, labelColumn, text)
+	}
+	return title + "\n\n" + c.String()
+}
+
+var perspectives = map[analyzer.Perspective]string{
+	analyzer.Landscape: "landscape",
+	analyzer.Context:   "context",
+	analyzer.Container: "container",
+	analyzer.Component: "component",
+}
+
+//diagram hold the boxes and arrows to draw
+type diagram struct {
+	nodes  []*node
+	edges  []*edge
+	byPath map[string]*node
+}
+
+func newDiagram() *diagram {
+	return &diagram{nodes: make([]*node, 0), edges: make([]*edge, 0), byPath: make(map[string]*node)}
+}
+
+//add a box for an element, once
+func (d *diagram) add(path, kind, desc string) {
+	if _, ok := d.byPath[path]; ok {
+		return
+	}
+	n := &node{path: path, lines: append([]string{path, "[" + kind + "]"}, wrap(desc, descWidth)...)}
+	d.nodes = append(d.nodes, n)
+	d.byPath[path] = n
+}
+
+//addVertice add a box for an element of the graph, described according to its kind
+func (d *diagram) addVertice(v analyzer.Vertice) {
+	switch e := v.Entity.(type) {
+	case model.User:
+		d.add(v.Path, userKind(e), e.Role)
+	case model.InternalSystem:
+		d.add(v.Path, "system", e.Desc)
+	case model.ExternalSystem:
+		d.add(v.Path, "external system", e.Desc)
+	case model.Container:
+		d.add(v.Path, withTech(containerKind(e, v.Kind == analyzer.VerticeTypeExternalContainer), e.Technology), e.Desc)
+	case model.Component:
+		d.add(v.Path, withTech("component", e.Technology), e.Desc)
+	}
+}
+
+//relate add an arrow for each relation between two boxes of the diagram
+func (d *diagram) relate(relations []model.Relation) {
+	for _, rel := range relations {
+		from, to := d.byPath[rel.Subject], d.byPath[rel.Object]
+		if from == nil || to == nil {
+			continue
+		}
+		e := &edge{relation: rel, from: from, to: to}
+		e.out = &port{other: to}
+		e.in = &port{other: from}
+		from.ports = append(from.ports, e.out)
+		to.ports = append(to.ports, e.in)
+		d.edges = append(d.edges, e)
+	}
+}
+
+//collect return the boxes and arrows of the perspective analysed in the graph
+func collect(g *analyzer.Graph) (*diagram, error) {
+	d := newDiagram()
+	var relations []model.Relation
+	switch g.Pers {
+	case analyzer.Landscape, analyzer.Context, analyzer.Container:
+		arc, err := g.View()
+		if err != nil {
+			return nil, err
+		}
+		relations = arc.Relations
+		referenced := make(map[string]bool)
+		for _, rel := range relations {
+			referenced[rel.Subject], referenced[rel.Object] = true, true
+		}
+		for _, u := range arc.Users {
+			d.add(u.Name, userKind(u), u.Role)
+		}
+		for _, iSys := range arc.InternalSystems {
+			if g.Pers != analyzer.Container || len(iSys.Containers) == 0 || referenced[iSys.Name] {
+				d.add(iSys.Name, "system", iSys.Desc)
+			}
+			if g.Pers == analyzer.Container {
+				for _, cont := range iSys.Containers {
+					d.add(iSys.Name+"."+cont.Name, withTech(containerKind(cont, false), cont.Technology), cont.Desc)
+				}
+			}
+		}
+		for _, eSys := range arc.ExternalSystems {
+			if g.Pers != analyzer.Container || len(eSys.Containers) == 0 || referenced[eSys.Name] {
+				d.add(eSys.Name, "external system", eSys.Desc)
+			}
+			if g.Pers == analyzer.Container {
+				for _, cont := range eSys.Containers {
+					if referenced[eSys.Name+"."+cont.Name] {
+						d.add(eSys.Name+"."+cont.Name, withTech(containerKind(cont, true), cont.Technology), cont.Desc)
+					}
+				}
+			}
+		}
+	case analyzer.Component:
+		containers, err := g.GetContainers()
+		if err != nil {
+			return nil, err
+		}
+		neighbors, err := g.GetNeighbors()
+		if err != nil {
+			return nil, err
+		}
+		if relations, err = g.GetRelations(); err != nil {
+			return nil, err
+		}
+		paths := make([]string, 0, len(containers))
+		for path := range containers {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			for _, comp := range containers[path].Components {
+				d.add(path+"."+comp.Name, withTech("component", comp.Technology), comp.Desc)
+			}
+		}
+		paths = paths[:0]
+		for path := range neighbors {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			d.addVertice(neighbors[path])
+		}
+	case analyzer.Focus:
+		for _, path := range g.FocusElements() {
+			v, _ := g.Element(path)
+			d.addVertice(v)
+		}
+		var err error
+		if relations, err = g.GetRelations(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("Not supported perspective")
+	}
+	d.relate(relations)
+	return d, nil
+}
+
+//rank order the nodes in layers so that most arrows point downward, keeping the order of the view within a layer
+func rank(nodes []*node, edges []*edge) {
+	//relaxing at most once per node bounds the ranks of cycles
+	for range nodes {
+		changed := false
+		for _, e := range edges {
+			if e.from != e.to && e.to.rank < e.from.rank+1 && e.from.rank+1 < len(nodes) {
+				e.to.rank = e.from.rank + 1
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].rank < nodes[j].rank })
+}
+
+//assignLanes give each arrow a vertical lane, shorter arrows closer to the boxes, and return the number of lanes
+func assignLanes(edges []*edge) int {
+	span := func(e *edge) (int, int) {
+		if e.out.row < e.in.row {
+			return e.out.row, e.in.row
+		}
+		return e.in.row, e.out.row
+	}
+	sorted := append([]*edge{}, edges...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, bi := span(sorted[i])
+		tj, bj := span(sorted[j])
+		return bi-ti < bj-tj
+	})
+	lanes := make([][]*edge, 0)
+	for _, e := range sorted {
+		top, bottom := span(e)
+		e.lane = len(lanes)
+		for i, lane := range lanes {
+			free := true
+			for _, other := range lane {
+				if t, b := span(other); top <= b && t <= bottom {
+					free = false
+					break
+				}
+			}
+			if free {
+				e.lane = i
+				break
+			}
+		}
+		if e.lane == len(lanes) {
+			lanes = append(lanes, nil)
+		}
+		lanes[e.lane] = append(lanes[e.lane], e)
+	}
+	return len(lanes)
+}
+
+var relationTech = regexp.MustCompile(`\((.*?)\)`)
+
+//label format the pointer of a relation with its technology in brackets, the technology being either given or embedded in the pointer
+func label(rel model.Relation) string {
+	technology := rel.Technology
+	if tech := relationTech.FindStringSubmatch(rel.Pointer); tech != nil && technology == "" {
+		technology = tech[1]
+	}
+	p := strings.Join(strings.Fields(relationTech.ReplaceAllString(rel.Pointer, "")), " ")
+	if technology == "" {
+		return p
+	}
+	return p + " [" + technology + "]"
+}
+
+func userKind(u model.User) string {
+	if u.External {
+		return "external person"
+	}
+	return "person"
+}
+
+//containerKindNames name the kinds of containers other than services in their marker
+var containerKindNames = map[string]string{
+	model.ContainerWebApp:    "web app",
+	model.ContainerDatabase:  "database",
+	model.ContainerQueue:     "queue",
+	model.ContainerFileStore: "file store",
+	model.ContainerBatch:     "batch",
+}
+
+//containerKind return the marker of a container, naming its kind unless a service, eg external queue container
+func containerKind(c model.Container, external bool) string {
+	kind := "container"
+	if name, ok := containerKindNames[c.ResolveKind()]; ok {
+		kind = name + " " + kind
+	}
+	if external {
+		return "external " + kind
+	}
+	return kind
+}
+
+func withTech(kind, technology string) string {
+	if technology == "" {
+		return kind
+	}
+	return kind + ": " + technology
+}
+
+//wrap split a text in lines of at most the given width, breaking between words
+func wrap(text string, width int) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,191 @@
+package ascii
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+var arc = model.ArcType{
+	App:   "test",
+	Users: []model.User{{Name: "u1", Role: "user"}, {Name: "u2", Role: "partner", External: true}},
+	InternalSystems: []model.InternalSystem{
+		{
+			Name: "s-1",
+			Desc: "System 1",
+			Containers: []model.Container{
+				{
+					Name:       "api",
+					Technology: "golang",
+					Components: []model.Component{{Name: "handler", Technology: "grpc"}},
+				},
+				{Name: "db", Technology: "dgraph"},
+			},
+		},
+	},
+	ExternalSystems: []model.ExternalSystem{
+		{Name: "e1", Desc: "Extern"},
+		{Name: "p", Containers: []model.Container{{Name: "bus", Technology: "kafka"}}},
+	},
+	Relations: []model.Relation{
+		{Subject: "u1", Pointer: "use", Object: "s-1"},
+		{Subject: "u2", Pointer: "use", Object: "s-1"},
+		{Subject: "s-1.api", Pointer: "publish", Technology: "kafka", Object: "p.bus"},
+		{Subject: "s-1", Pointer: "call (https)", Object: "e1"},
+		{Subject: "s-1.api", Pointer: "persist", Object: "s-1.db"},
+		{Subject: "s-1.api.handler", Pointer: "query", Object: "s-1.db"},
+	},
+}
+
+func process(t *testing.T, pers model.PresentationPerspective, targets ...string) *analyzer.Graph {
+	data, err := arc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := analyzer.Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  pers,
+		Data:         data,
+		Target:       targets,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		g      *analyzer.Graph
+		expect []string
+	}{
+		{
+			g: process(t, model.PresentationPerspective_LANDSCAPE),
+			expect: []string{
+				"test - landscape\n\n┌───",
+				"│ u1                ├────┐ use ▶ s-1",
+				"│ [external person] │  │ │",
+				"│ s-1               │◀─┼─┘",
+				"│ [system]          │◀─┘",
+				"│ System 1          ├──┐   call [https] ▶ e1",
+				"│ e1                │◀─┘ │",
+			},
+		},
+		{
+			g: process(t, model.PresentationPerspective_CONTAINER, "s-1"),
+			expect: []string{
+				"│ s-1.api                           ├──┐   persist ▶ s-1.db",
+				"│ [container: golang]               ├──┼─┐ publish [kafka] ▶ p.bus",
+				"│ s-1.db                            │◀─┘ │",
+				"│ [database container: dgraph]      │    │",
+				"│ [external queue container: kafka] │",
+			},
+		},
+		{
+			g: process(t, model.PresentationPerspective_COMPONENT, "s-1.api"),
+			expect: []string{
+				"│ s-1.api.handler              ├──┐ query ▶ s-1.db",
+				"│ [component: grpc]            │  │",
+				"│ s-1.db                       │◀─┘",
+				"│ [database container: dgraph] │",
+			},
+		},
+	}
+	for i, test := range tests {
+		actual, err := Generate(test.g, Unicode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range test.expect {
+			if !strings.Contains(actual, e) {
+				t.Errorf("Test %d fail: expect to contain %s, actual is\n%s", i, e, actual)
+			}
+		}
+		for _, line := range strings.Split(actual, "\n") {
+			if strings.HasSuffix(line, " ") {
+				t.Errorf("Test %d fail: trailing spaces in %q", i, line)
+			}
+		}
+	}
+}
+
+func TestGenerateASCII(t *testing.T) {
+	actual, err := Generate(process(t, model.PresentationPerspective_COMPONENT, "s-1.api"), ASCII)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `test - component
+
++------------------------------+
+| s-1.api.handler              +--+ query > s-1.db
+| [component: grpc]            |  |
++------------------------------+  |
+                                  |
++------------------------------+  |
+| s-1.db                       |<-+
+| [database container: dgraph] |
++------------------------------+
+`
+	if actual != expect {
+		t.Errorf("expect\n%s\nactual is\n%s", expect, actual)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	cyclic := model.ArcType{
+		App:             "cycle",
+		InternalSystems: []model.InternalSystem{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+		Relations: []model.Relation{
+			{Subject: "a", Pointer: "call", Object: "b"},
+			{Subject: "b", Pointer: "call", Object: "c"},
+			{Subject: "c", Pointer: "call", Object: "a"},
+			{Subject: "a", Pointer: "loop", Object: "a"},
+		},
+	}
+	data, err := cyclic.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := analyzer.Process(context.Background(), &model.RenderRequest{DataFormat: model.ArcDataFormat_ARC, Perspective: model.PresentationPerspective_LANDSCAPE, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := Generate(g, Unicode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []string{"call ▶ b", "call ▶ c", "call ▶ a", "loop ▶ a"} {
+		if !strings.Contains(actual, e) {
+			t.Errorf("expect to contain %s, actual is\n%s", e, actual)
+		}
+	}
+}
+
+func TestGeneratePath(t *testing.T) {
+	g := process(t, model.PresentationPerspective_LANDSCAPE)
+	path, err := g.ShortestPath("u1", "s-1.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := GeneratePath(g, path, Unicode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []string{
+		"test - path from u1 to s-1.db",
+		"│ u1                           ├──┐ use ▶ s-1",
+		"│ [system]                     ├──┐ s-1.api: persist, s-1.api.handler: query ▶ s-1.db",
+		"│ [database container: dgraph] │",
+	} {
+		if !strings.Contains(actual, e) {
+			t.Errorf("expect to contain %s, actual is\n%s", e, actual)
+		}
+	}
+	if _, err := GeneratePath(g, nil, Unicode); err == nil {
+		t.Error("expect an error for an empty path")
+	}
+}
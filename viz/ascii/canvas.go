@@ -0,0 +1,96 @@
+package ascii
+
+import (
+	"strings"
+)
+
+//canvas is a grid of characters diagrams are drawn on
+type canvas struct {
+	cells [][]rune
+}
+
+func newCanvas(height, width int) *canvas {
+	cells := make([][]rune, height)
+	for i := range cells {
+		cells[i] = []rune(strings.Repeat(" ", width))
+	}
+	return &canvas{cells: cells}
+}
+
+//set draw a character, lines drawn across each other turning into a crossing
+func (c *canvas) set(row, col int, r rune, charset Charset) {
+	for len(c.cells[row]) <= col {
+		c.cells[row] = append(c.cells[row], ' ')
+	}
+	switch current := c.cells[row][col]; {
+	case current == charset.Horizontal && r == charset.Vertical, current == charset.Vertical && r == charset.Horizontal:
+		r = charset.Cross
+	}
+	c.cells[row][col] = r
+}
+
+//text write a text from the given position
+func (c *canvas) text(row, col int, text string) {
+	for _, r := range text {
+		for len(c.cells[row]) <= col {
+			c.cells[row] = append(c.cells[row], ' ')
+		}
+		c.cells[row][col] = r
+		col++
+	}
+}
+
+//box draw the box of a node with its content
+func (c *canvas) box(n *node, width int, charset Charset) {
+	bottom := n.top + 1 + len(n.lines)
+	if len(n.ports) > len(n.lines) {
+		bottom = n.top + 1 + len(n.ports)
+	}
+	for col := 1; col < width-1; col++ {
+		c.set(n.top, col, charset.Horizontal, charset)
+		c.set(bottom, col, charset.Horizontal, charset)
+	}
+	for row := n.top + 1; row < bottom; row++ {
+		c.set(row, 0, charset.Vertical, charset)
+		c.set(row, width-1, charset.Vertical, charset)
+	}
+	c.set(n.top, 0, charset.TopLeft, charset)
+	c.set(n.top, width-1, charset.TopRight, charset)
+	c.set(bottom, 0, charset.BottomLeft, charset)
+	c.set(bottom, width-1, charset.BottomRight, charset)
+	for i, l := range n.lines {
+		c.text(n.top+1+i, 2, l)
+	}
+}
+
+//arrow draw a relation from the right border of its subject box, along its lane, to the right border of its object box
+func (c *canvas) arrow(e *edge, width int, charset Charset) {
+	lane := width + 2 + 2*e.lane
+	c.set(e.out.row, width-1, charset.Tee, charset)
+	for col := width; col < lane; col++ {
+		c.set(e.out.row, col, charset.Horizontal, charset)
+	}
+	for col := width + 1; col < lane; col++ {
+		c.set(e.in.row, col, charset.Horizontal, charset)
+	}
+	c.set(e.in.row, width, charset.Arrow, charset)
+	top, bottom := e.out.row, e.in.row
+	if top > bottom {
+		top, bottom = bottom, top
+	}
+	for row := top + 1; row < bottom; row++ {
+		c.set(row, lane, charset.Vertical, charset)
+	}
+	c.set(top, lane, charset.TopRight, charset)
+	c.set(bottom, lane, charset.BottomRight, charset)
+}
+
+//String return the drawing without trailing spaces
+func (c *canvas) String() string {
+	var sb strings.Builder
+	for _, row := range c.cells {
+		sb.WriteString(strings.TrimRight(string(row), " "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
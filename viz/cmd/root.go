@@ -22,6 +22,7 @@ import (
 	"os"
 
 	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/puml"
 	"github.com/koderizer/arc/viz/server"
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
@@ -34,6 +35,7 @@ import (
 type rootConfig struct {
 	Port         string `mapstructure:"port"`
 	PlantUmlAddr string `mapstructure:"PUML_ADDR"`
+	TemplateDir  string `mapstructure:"TEMPLATE_DIR"`
 }
 
 var cfgFile string
@@ -56,8 +58,12 @@ to quickly create a Cobra application.`,
 		if err != nil {
 			log.Fatalf("failed to listen: %v", err)
 		}
+		templates, err := puml.NewTemplates(config.TemplateDir)
+		if err != nil {
+			log.Fatalf("failed to load templates: %v", err)
+		}
 		grpcServer := grpc.NewServer()
-		model.RegisterArcVizServer(grpcServer, server.NewArcViz(config.PlantUmlAddr))
+		model.RegisterArcVizServer(grpcServer, server.NewArcViz(config.PlantUmlAddr, templates))
 		log.Printf("Start server listening on port: %s\n depending on Plantuml at %s", config.Port, config.PlantUmlAddr)
 		grpcServer.Serve(lis)
 	},
@@ -85,6 +91,8 @@ func init() {
 	viper.BindPFlag("port", rootCmd.PersistentFlags().Lookup("port"))
 	rootCmd.PersistentFlags().StringVar(&config.PlantUmlAddr, "pumladdr", "http://localhost:8080", "Address of the Plant UML server(default is on localhost)")
 	viper.BindPFlag("PUML_ADDR", rootCmd.PersistentFlags().Lookup("pumladdr"))
+	rootCmd.PersistentFlags().StringVar(&config.TemplateDir, "templates", "", "Directory of the templates overriding the built-in ones(default is none)")
+	viper.BindPFlag("TEMPLATE_DIR", rootCmd.PersistentFlags().Lookup("templates"))
 
 	viper.AutomaticEnv() // read in environment variables that match
 	err := viper.Unmarshal(&config)
@@ -0,0 +1,233 @@
+//Package mermaid provide utilities to generate mermaid flowchart code of an analysed architecture
+package mermaid
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+//Context hold the data structure to render Landscape, Context and Container flowcharts
+type Context struct {
+	Direction string
+	Arc       model.ArcType
+	//Groups and Ungrouped split the internal systems of a Landscape or Context flowchart
+	Groups    []Group
+	Ungrouped []model.InternalSystem
+}
+
+//Group is a group of internal systems drawn as a subgraph, holding its systems and nested groups
+type Group struct {
+	Name    string
+	Type    string
+	Systems []model.InternalSystem
+	Groups  []Group
+}
+
+//Component hold the data structure to render Component flowcharts
+type Component struct {
+	Direction  string
+	Containers map[string]model.Container
+	Neighbors  []Neighbor
+	Relations  []model.Relation
+}
+
+//Neighbor is the generic presentation of any element partnering with the components
+type Neighbor struct {
+	ID    string
+	Name  string
+	Desc  string
+	Class string
+	//Kind is the kind of a container neighbor, internal or external, see model.Container.ResolveKind
+	Kind string
+}
+
+var funcMap = template.FuncMap{
+	"ID":      analyzer.EncodeID,
+	"Label":   label,
+	"Pointer": pointer,
+	"Open":    shapeOpen,
+	"Close":   shapeClose,
+}
+
+//containerShapes map the kinds of container to the delimiters of their node shape
+var containerShapes = map[string][2]string{
+	model.ContainerService:   {"[", "]"},
+	model.ContainerWebApp:    {"(", ")"},
+	model.ContainerDatabase:  {"[(", ")]"},
+	model.ContainerQueue:     {">", "]"},
+	model.ContainerFileStore: {"[/", "/]"},
+	model.ContainerBatch:     {"[[", "]]"},
+}
+
+//Generate produce the mermaid flowchart code of the perspective analysed in the given graph
+func Generate(g *analyzer.Graph) (string, error) {
+	var tpl string
+	var data interface{}
+	switch g.Pers {
+	case analyzer.Landscape, analyzer.Context, analyzer.Container:
+		arc, err := g.View()
+		if err != nil {
+			return "", err
+		}
+		tpl = contextTemplate
+		if g.Pers == analyzer.Container {
+			tpl = containerTemplate
+		}
+		context := Context{Direction: direction(arc.Styles), Arc: arc}
+		context.Groups, context.Ungrouped = groups(arc)
+		data = context
+	case analyzer.Component, analyzer.Focus:
+		component, err := componentData(g)
+		if err != nil {
+			return "", err
+		}
+		tpl = componentTemplate
+		data = component
+	default:
+		return "", errors.New("Not supported perspective")
+	}
+	flowchart, err := template.New("mermaid").Funcs(funcMap).Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	wr := &bytes.Buffer{}
+	if err := flowchart.Execute(wr, data); err != nil {
+		return "", err
+	}
+	return wr.String(), nil
+}
+
+func componentData(g *analyzer.Graph) (Component, error) {
+	containers, err := g.GetContainers()
+	if err != nil {
+		return Component{}, err
+	}
+	vertices, err := g.GetNeighbors()
+	if err != nil {
+		return Component{}, err
+	}
+	relations, err := g.GetRelations()
+	if err != nil {
+		return Component{}, err
+	}
+	neighbors := make([]Neighbor, 0, len(vertices))
+	for path, v := range vertices {
+		n := Neighbor{ID: path, Name: path}
+		switch e := v.Entity.(type) {
+		case model.User:
+			n.Class, n.Desc = "person", e.Role
+			if e.External {
+				n.Class = "external_person"
+			}
+		case model.InternalSystem:
+			n.Class, n.Desc = "system", e.Desc
+		case model.ExternalSystem:
+			n.Class, n.Desc = "external", e.Desc
+		case model.Container:
+			n.Class, n.Desc, n.Kind = "container", e.Desc, e.ResolveKind()
+			if v.Kind == analyzer.VerticeTypeExternalContainer {
+				n.Class = "external"
+			}
+		case model.Component:
+			n.Class, n.Desc = "component", e.Desc
+		}
+		neighbors = append(neighbors, n)
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].ID < neighbors[j].ID })
+	return Component{
+		Direction:  direction(g.Arc.Styles),
+		Containers: containers,
+		Neighbors:  neighbors,
+		Relations:  relations,
+	}, nil
+}
+
+//groups split the internal systems of the view between their groups, each system being drawn in the first group listing it
+func groups(arc model.ArcType) ([]Group, []model.InternalSystem) {
+	systems := make(map[string]model.InternalSystem, len(arc.InternalSystems))
+	for _, iSys := range arc.InternalSystems {
+		systems[iSys.Name] = iSys
+	}
+	grouped := groupSystems(arc.Groups, systems)
+	ungrouped := make([]model.InternalSystem, 0)
+	for _, iSys := range arc.InternalSystems {
+		if _, ok := systems[iSys.Name]; ok {
+			ungrouped = append(ungrouped, iSys)
+		}
+	}
+	return grouped, ungrouped
+}
+
+func groupSystems(groups []model.Group, systems map[string]model.InternalSystem) []Group {
+	res := make([]Group, 0)
+	for _, g := range groups {
+		group := Group{Name: g.Name, Type: g.Type, Systems: make([]model.InternalSystem, 0)}
+		if group.Type == "" {
+			group.Type = "Group"
+		}
+		for _, name := range g.Systems {
+			if iSys, ok := systems[name]; ok {
+				group.Systems = append(group.Systems, iSys)
+				delete(systems, name)
+			}
+		}
+		group.Groups = groupSystems(g.Groups, systems)
+		if len(group.Systems) > 0 || len(group.Groups) > 0 {
+			res = append(res, group)
+		}
+	}
+	return res
+}
+
+//direction return the flowchart direction matching the layout of the arc styles
+func direction(styles model.Styles) string {
+	if styles.Layout == "left-right" {
+		return "LR"
+	}
+	return "TB"
+}
+
+var relationTech = regexp.MustCompile(`\((.*?)\)`)
+
+//labelEscaper replace the characters that would end a quoted label or inject markup
+var labelEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+//label escape text to be shown inside a quoted mermaid label
+func label(s string) string {
+	return labelEscaper.Replace(strings.Join(strings.Fields(s), " "))
+}
+
+//shapeOpen return the opening delimiter of the node shape of a container kind
+func shapeOpen(kind string) string {
+	if shape, ok := containerShapes[kind]; ok {
+		return shape[0]
+	}
+	return "["
+}
+
+//shapeClose return the closing delimiter of the node shape of a container kind
+func shapeClose(kind string) string {
+	if shape, ok := containerShapes[kind]; ok {
+		return shape[1]
+	}
+	return "]"
+}
+
+//pointer format a relation pointer with its technology in brackets, the technology being either given or embedded in the pointer
+func pointer(p, technology string) string {
+	if tech := relationTech.FindStringSubmatch(p); tech != nil && technology == "" {
+		technology = tech[1]
+	}
+	p = label(relationTech.ReplaceAllString(p, ""))
+	if technology == "" {
+		return p
+	}
+	return p + "<br/>[" + label(technology) + "]"
+}
@@ -0,0 +1,151 @@
+package mermaid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+var arc = model.ArcType{
+	App:   "test",
+	Desc:  "Test \"quoted\" app",
+	Users: []model.User{{Name: "u1", Role: "user"}, {Name: "u2", Role: "partner", External: true}},
+	InternalSystems: []model.InternalSystem{
+		{
+			Name: "s-1",
+			Desc: "System 1",
+			Containers: []model.Container{
+				{
+					Name:       "api",
+					Technology: "golang",
+					Components: []model.Component{{Name: "handler", Technology: "grpc"}},
+				},
+				{Name: "db", Technology: "dgraph"},
+			},
+		},
+	},
+	Groups: []model.Group{{Name: "core", Type: "Platform", Systems: []string{"s-1"}}},
+	ExternalSystems: []model.ExternalSystem{
+		{Name: "e1", Desc: "Extern"},
+		{Name: "e_1", Desc: "<script>"},
+		{Name: "p", Containers: []model.Container{{Name: "bus", Technology: "kafka"}}},
+	},
+	Relations: []model.Relation{
+		{Subject: "u1", Pointer: "use", Object: "s-1"},
+		{Subject: "u2", Pointer: "use", Object: "s-1"},
+		{Subject: "s-1.api", Pointer: "publish", Technology: "kafka", Object: "p.bus"},
+		{Subject: "s-1", Pointer: "call (https)", Object: "e1"},
+		{Subject: "s-1", Pointer: "call", Object: "e_1"},
+		{Subject: "s-1.api", Pointer: "persist", Object: "s-1.db"},
+		{Subject: "s-1.api.handler", Pointer: "query", Object: "s-1.db"},
+	},
+}
+
+func process(t *testing.T, pers model.PresentationPerspective, targets ...string) *analyzer.Graph {
+	data, err := arc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := analyzer.Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Perspective:  pers,
+		Data:         data,
+		Target:       targets,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func processFocus(t *testing.T, focus string, depth int32) *analyzer.Graph {
+	data, err := arc.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := analyzer.Process(context.Background(), &model.RenderRequest{
+		DataFormat:   model.ArcDataFormat_ARC,
+		VisualFormat: model.ArcVisualFormat_SVG,
+		Data:         data,
+		Focus:        focus,
+		Depth:        depth,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		g      *analyzer.Graph
+		expect []string
+	}{
+		{
+			g: process(t, model.PresentationPerspective_LANDSCAPE),
+			expect: []string{
+				"flowchart TB",
+				`u1(["u1<br/>user"]):::person`,
+				`u2(["u2<br/>partner"]):::external_person`,
+				`subgraph test_boundary["Test #quot;quoted#quot; app"]`,
+				`subgraph core_group["core [Platform]"]`,
+				`s_2d1["s-1<br/>System 1"]:::system`,
+				`e1["e1<br/>Extern"]:::external`,
+				`e_5f1["e_1<br/>#lt;script#gt;"]:::external`,
+				`u1 -->|"use"| s_2d1`,
+				`s_2d1 -->|"call<br/>[https]"| e1`,
+			},
+		},
+		{
+			g: process(t, model.PresentationPerspective_CONTAINER, "s-1"),
+			expect: []string{
+				`subgraph s_2d1["s-1"]`,
+				`s_2d1_2eapi["api<br/>[golang]<br/>"]:::container`,
+				`s_2d1_2edb[("db<br/>[dgraph]<br/>")]:::container`,
+				`s_2d1_2eapi -->|"persist"| s_2d1_2edb`,
+				`subgraph p["p [External System]"]`,
+				`p_2ebus>"bus<br/>[kafka]<br/>"]:::external`,
+				`s_2d1_2eapi -->|"publish<br/>[kafka]"| p_2ebus`,
+			},
+		},
+		{
+			g: process(t, model.PresentationPerspective_COMPONENT, "s-1.api"),
+			expect: []string{
+				`subgraph s_2d1_2eapi["s-1.api"]`,
+				`s_2d1_2eapi_2ehandler["handler<br/>[grpc]<br/>"]:::component`,
+				`s_2d1_2edb[("s-1.db<br/>")]:::container`,
+				`s_2d1_2eapi_2ehandler -->|"query"| s_2d1_2edb`,
+			},
+		},
+		{
+			g: processFocus(t, "s-1.db", 1),
+			expect: []string{
+				`s_2d1_2edb[("s-1.db<br/>")]:::container`,
+				`s_2d1_2eapi["s-1.api<br/>"]:::container`,
+				`s_2d1_2eapi -->|"persist"| s_2d1_2edb`,
+				`s_2d1_2eapi -->|"query"| s_2d1_2edb`,
+			},
+		},
+	}
+	for i, test := range tests {
+		actual, err := Generate(test.g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(actual, "<script>") {
+			t.Errorf("Test %d fail: label markup not escaped", i)
+		}
+		if !strings.HasPrefix(actual, "flowchart TB\n") {
+			t.Errorf("Test %d fail: missing flowchart header", i)
+		}
+		for _, e := range test.expect {
+			if !strings.Contains(actual, e) {
+				t.Errorf("Test %d fail: expect to contain %s, actual is\n%s", i, e, actual)
+			}
+		}
+	}
+}
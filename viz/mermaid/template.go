@@ -0,0 +1,86 @@
+package mermaid
+
+const mermaidStyles = `
+    classDef person fill:#08427B,stroke:#08427B,color:#fff
+    classDef external_person fill:#686868,stroke:#686868,color:#fff
+    classDef system fill:#268BD2,stroke:#268BD2,color:#fff
+    classDef external fill:#93a1a1,stroke:#93a1a1,color:#fff
+    classDef container fill:#268BD2,stroke:#268BD2,color:#fff
+    classDef component fill:#268BD2,stroke:#268BD2,color:#fff`
+
+const contextTemplate = `flowchart {{.Direction}}
+{{- range .Arc.Users}}
+    {{.Name | ID}}(["{{.Name | Label}}<br/>{{.Role | Label}}"]):::{{if .External}}external_person{{else}}person{{end}}
+{{- end}}
+    subgraph {{.Arc.App | ID}}_boundary["{{.Arc.Desc | Label}}"]
+{{- range .Groups}}
+{{- template "group" .}}
+{{- end}}
+{{- range .Ungrouped}}
+        {{.Name | ID}}["{{.Name | Label}}<br/>{{.Desc | Label}}"]:::system
+{{- end}}
+    end
+{{- range .Arc.ExternalSystems}}
+    {{.Name | ID}}["{{.Name | Label}}<br/>{{.Desc | Label}}"]:::external
+{{- end}}
+{{- range .Arc.Relations}}
+    {{.Subject | ID}} -->|"{{Pointer .Pointer .Technology}}"| {{.Object | ID}}
+{{- end}}
+` + mermaidStyles + "\n" + groupTemplate
+
+//groupTemplate draw a group of systems, and its nested groups, as a subgraph
+const groupTemplate = `{{- define "group"}}
+    subgraph {{.Name | ID}}_group["{{.Name | Label}} [{{.Type | Label}}]"]
+{{- range .Groups}}
+{{- template "group" .}}
+{{- end}}
+{{- range .Systems}}
+        {{.Name | ID}}["{{.Name | Label}}<br/>{{.Desc | Label}}"]:::system
+{{- end}}
+    end
+{{- end}}`
+
+const containerTemplate = `flowchart {{.Direction}}
+{{- range .Arc.Users}}
+    {{.Name | ID}}(["{{.Name | Label}}"]):::{{if .External}}external_person{{else}}person{{end}}
+{{- end}}
+{{- range .Arc.InternalSystems}}
+{{- $sys := .Name}}
+    subgraph {{$sys | ID}}["{{$sys | Label}}"]
+{{- range .Containers}}
+        {{(printf "%s.%s" $sys .Name) | ID}}{{Open .ResolveKind}}"{{.Name | Label}}<br/>[{{.Technology | Label}}]<br/>{{.Desc | Label}}"{{Close .ResolveKind}}:::container
+{{- end}}
+    end
+{{- end}}
+{{- range .Arc.ExternalSystems}}
+{{- if .Containers}}
+{{- $sys := .Name}}
+    subgraph {{$sys | ID}}["{{$sys | Label}} [External System]"]
+{{- range .Containers}}
+        {{(printf "%s.%s" $sys .Name) | ID}}{{Open .ResolveKind}}"{{.Name | Label}}<br/>[{{.Technology | Label}}]<br/>{{.Desc | Label}}"{{Close .ResolveKind}}:::external
+{{- end}}
+    end
+{{- else}}
+    {{.Name | ID}}["{{.Name | Label}}<br/>{{.Desc | Label}}"]:::external
+{{- end}}
+{{- end}}
+{{- range .Arc.Relations}}
+    {{.Subject | ID}} -->|"{{Pointer .Pointer .Technology}}"| {{.Object | ID}}
+{{- end}}
+` + mermaidStyles + "\n"
+
+const componentTemplate = `flowchart {{.Direction}}
+{{- range $k, $v := .Containers}}
+    subgraph {{$k | ID}}["{{$k | Label}}"]
+{{- range $v.Components}}
+        {{(printf "%s.%s" $k .Name) | ID}}["{{.Name | Label}}<br/>[{{.Technology | Label}}]<br/>{{.Desc | Label}}"]:::component
+{{- end}}
+    end
+{{- end}}
+{{- range .Neighbors}}
+    {{.ID | ID}}{{if (or (eq .Class "person") (eq .Class "external_person"))}}(["{{.Name | Label}}"]){{else if .Kind}}{{Open .Kind}}"{{.Name | Label}}<br/>{{.Desc | Label}}"{{Close .Kind}}{{else}}["{{.Name | Label}}<br/>{{.Desc | Label}}"]{{end}}:::{{.Class}}
+{{- end}}
+{{- range .Relations}}
+    {{.Subject | ID}} -->|"{{Pointer .Pointer .Technology}}"| {{.Object | ID}}
+{{- end}}
+` + mermaidStyles + "\n"
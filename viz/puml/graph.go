@@ -0,0 +1,114 @@
+package puml
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
+)
+
+//Generate produce the plantUml code of the perspective analysed in the given graph
+func Generate(g *analyzer.Graph, targets ...string) (string, error) {
+	return builtins.Generate(g, targets...)
+}
+
+//Generate produce the plantUml code of the perspective analysed in the given graph with these templates
+func (t *Templates) Generate(g *analyzer.Graph, targets ...string) (string, error) {
+	switch g.Pers {
+	case analyzer.Component:
+		return t.componentPuml(g)
+	case analyzer.Focus:
+		return t.focusPuml(g)
+	}
+	arc, err := g.View()
+	if err != nil {
+		return "", err
+	}
+	switch g.Pers {
+	case analyzer.Landscape:
+		return t.C4ContextPuml(arc)
+	case analyzer.Context:
+		return t.C4ContextPuml(arc, targets...)
+	case analyzer.Container:
+		return t.C4ContainerPuml(arc, targets...)
+	default:
+		return "", errors.New("Not supported perspective")
+	}
+}
+
+func (t *Templates) componentPuml(g *analyzer.Graph) (string, error) {
+	containers, err := g.GetContainers()
+	if err != nil {
+		return "", err
+	}
+	vertices, err := g.GetNeighbors()
+	if err != nil {
+		return "", err
+	}
+	relations, err := g.GetRelations()
+	if err != nil {
+		return "", err
+	}
+	neighbors := make([]C4Neighbor, 0, len(vertices))
+	for path, v := range vertices {
+		neighbors = append(neighbors, toNeighbor(path, v))
+	}
+	return t.C4ComponentPuml(containers, neighbors, relations, g.Arc.Styles)
+}
+
+//focusPuml draw the focused element with its neighbours, with the Component template as neighbours may be of any kind
+func (t *Templates) focusPuml(g *analyzer.Graph) (string, error) {
+	containers, err := g.GetContainers()
+	if err != nil {
+		return "", err
+	}
+	vertices, err := g.GetNeighbors()
+	if err != nil {
+		return "", err
+	}
+	relations, err := g.GetRelations()
+	if err != nil {
+		return "", err
+	}
+	neighbors := make([]C4Neighbor, 0, len(vertices))
+	for path, v := range vertices {
+		neighbors = append(neighbors, toNeighbor(path, v))
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].ID < neighbors[j].ID })
+	style := newC4Style(g.Arc.Styles, componentKinds)
+	data := C4ContainerComponent{
+		Title:      fmt.Sprintf("Focus view for: %s", g.Focus),
+		Containers: containers,
+		Neighbors:  neighbors,
+		Relations:  c4Relations(relations, style),
+		Style:      style,
+	}
+	return t.execute(data, c4ComponentTemplate, "component")
+}
+
+//toNeighbor map an analyzed graph vertice to its generic C4 presentation
+func toNeighbor(path string, v analyzer.Vertice) C4Neighbor {
+	n := C4Neighbor{ID: path, Name: path}
+	switch e := v.Entity.(type) {
+	case model.User:
+		n.Kind, n.Desc, n.Tags = "person", e.Role, e.Tags
+		if e.External {
+			n.Kind = "external_person"
+		}
+	case model.InternalSystem:
+		n.Kind, n.Desc, n.Tags = "system", e.Desc, e.Tags
+	case model.ExternalSystem:
+		n.Kind, n.Desc, n.Tags = "external_system", e.Desc, e.Tags
+	case model.Container:
+		n.Kind, n.Desc, n.Technology, n.Icon, n.Tags = "container", e.Desc, e.Technology, e.Icon, e.Tags
+		n.ContainerKind = e.ResolveKind()
+		if v.Kind == analyzer.VerticeTypeExternalContainer {
+			n.Kind = "external_container"
+		}
+	case model.Component:
+		n.Kind, n.Desc, n.Technology, n.Icon, n.Tags = "component", e.Desc, e.Technology, e.Icon, e.Tags
+	}
+	return n
+}
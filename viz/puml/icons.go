@@ -0,0 +1,109 @@
+package puml
+
+import (
+	_ "embed" //embed the built-in icon registry
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+	"gopkg.in/yaml.v2"
+)
+
+//builtinIconsYaml map common technologies to icons, so elements get one without any configuration
+//
+//go:embed icons.yaml
+var builtinIconsYaml []byte
+
+//builtinIcons is the parsed built-in icon registry
+var builtinIcons = mustParseIcons(builtinIconsYaml)
+
+//validSprite match a PlantUML sprite definition, either in hexadecimal or encoded form
+var validSprite = regexp.MustCompile(`^\[\d+x\d+/\d+z?\]\s*(\{[0-9A-Za-z\s]*\}|[0-9A-Za-z_\-]+)$`)
+
+//validGlyph match the name of an OpenIconic glyph
+var validGlyph = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+//technologySeparators split a technology description into single technologies
+var technologySeparators = regexp.MustCompile(`[\s,;/()|+]+`)
+
+//C4Sprite is a sprite to declare in the diagram
+type C4Sprite struct {
+	ID   string
+	Data string
+}
+
+//c4Icons resolve the icon of elements from the registered icons, later registrations taking precedence
+type c4Icons struct {
+	byName map[string]string
+	byTech map[string]string
+}
+
+func mustParseIcons(data []byte) []model.IconStyle {
+	icons := make([]model.IconStyle, 0)
+	if err := yaml.Unmarshal(data, &icons); err != nil {
+		panic(fmt.Sprintf("invalid built-in icons: %v", err))
+	}
+	return icons
+}
+
+//newC4Icons build the icon registry from the built-in icons and the given ones, and return the sprites to declare
+func newC4Icons(icons []model.IconStyle) (c4Icons, []C4Sprite) {
+	registry := c4Icons{byName: make(map[string]string), byTech: make(map[string]string)}
+	sprites := make([]C4Sprite, 0)
+	for _, icon := range append(append([]model.IconStyle{}, builtinIcons...), icons...) {
+		var glyph string
+		switch {
+		case validSprite.MatchString(strings.TrimSpace(icon.Sprite)):
+			id := "arc_" + cleanID(icon.Name)
+			sprites = append(sprites, C4Sprite{ID: id, Data: strings.TrimSpace(icon.Sprite)})
+			glyph = fmt.Sprintf("<$%s>", id)
+		case validGlyph.MatchString(icon.Openiconic):
+			glyph = fmt.Sprintf("<&%s>", icon.Openiconic)
+		default:
+			continue
+		}
+		if icon.Name != "" {
+			registry.byName[icon.Name] = glyph
+		}
+		for _, tech := range icon.Technologies {
+			registry.byTech[strings.ToLower(tech)] = glyph
+		}
+	}
+	return registry, sprites
+}
+
+//icon return the creole glyph of an element given its technology and explicit icon property.
+//An explicit icon is the name of a registered icon or of an OpenIconic glyph, "none" removes the icon.
+func (r c4Icons) icon(technology, icon string) string {
+	switch {
+	case icon == "none":
+		return ""
+	case icon != "":
+		if glyph, ok := r.byName[icon]; ok {
+			return glyph
+		}
+		if validGlyph.MatchString(icon) {
+			return fmt.Sprintf("<&%s>", icon)
+		}
+		return ""
+	}
+	tech := strings.ToLower(strings.TrimSpace(technology))
+	if glyph, ok := r.byTech[tech]; ok {
+		return glyph
+	}
+	fields := technologySeparators.Split(tech, -1)
+	for _, field := range fields {
+		if glyph, ok := r.byTech[field]; ok {
+			return glyph
+		}
+	}
+	for _, field := range fields {
+		for _, part := range strings.FieldsFunc(field, func(r rune) bool { return r == '-' || r == '.' }) {
+			if glyph, ok := r.byTech[part]; ok {
+				return glyph
+			}
+		}
+	}
+	return ""
+}
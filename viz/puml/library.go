@@ -0,0 +1,72 @@
+package puml
+
+import (
+	"embed"
+	"path"
+	"strings"
+)
+
+//c4Library bundle the C4-PlantUML sources so the generated code does not depend on the renderer filesystem
+//
+//go:embed C4-PlantUML/*.puml
+var c4Library embed.FS
+
+//c4Include return the C4-PlantUML definitions of the given level with all its includes inlined
+func c4Include(level string) (string, error) {
+	var sb strings.Builder
+	if err := inlineC4(&sb, level+".puml", make(map[string]bool)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+//inlineC4 write the content of a library file, replacing every include by the content of the included file
+func inlineC4(sb *strings.Builder, file string, done map[string]bool) error {
+	if done[file] {
+		return nil
+	}
+	done[file] = true
+	src, err := c4Library.ReadFile("C4-PlantUML/" + file)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "!include") {
+			fields := strings.Fields(trimmed)
+			if len(fields) < 2 {
+				continue
+			}
+			if err := inlineC4(sb, path.Base(fields[1]), done); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "'") {
+			continue
+		}
+		sb.WriteString(strings.TrimRight(line, "\r"))
+		sb.WriteString("\n")
+	}
+	return nil
+}
+
+//IncludeLibrary replace the C4-PlantUML definitions inlined in the generated code by an include of the library file
+//of the same level under dir, and return the levels of the library files included
+func IncludeLibrary(src, dir string) (string, []string) {
+	levels := make([]string, 0)
+	for _, level := range []string{"C4_Component", "C4_Container", "C4_Context"} {
+		library, err := c4Include(level)
+		if err != nil || !strings.Contains(src, library) {
+			continue
+		}
+		src = strings.Replace(src, library, "!include "+path.Join(dir, level+".puml")+"\n", 1)
+		levels = append(levels, level)
+	}
+	return src, levels
+}
+
+//Library return the C4-PlantUML definitions of the given level as a single file, to be included by the generated code
+func Library(level string) (string, error) {
+	return c4Include(level)
+}
@@ -1,158 +1,281 @@
 package puml
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/koderizer/arc/model"
+	"github.com/koderizer/arc/viz/analyzer"
 )
 
-//C4Context type hold all data structure to render Context diagrams
+//C4Context type hold all data structure to render Landscape and Context diagrams.
+//It is the data given to the landscape and context templates, fields are only ever added to it.
 type C4Context struct {
-	Title     string
-	Arc       model.ArcType
+	//Title of the diagram
+	Title string
+	//Arc is the analysed view, holding the users, internal and external systems to draw
+	Arc model.ArcType
+	//Relations between the drawn elements
 	Relations []C4Relation
+	//Style of the diagram, to render with {{template "style" .Style}}
+	Style C4Style
+	//Groups of the drawn internal systems, to render with {{template "group" .}}
+	Groups []C4Group
+	//Ungrouped are the drawn internal systems that belong to no group
+	Ungrouped []model.InternalSystem
 }
 
-//C4SystemContainer type hold data structure to render Container diagrams
+//C4Group is a group of internal systems drawn as a boundary, holding its systems and nested groups
+type C4Group struct {
+	ID      string
+	Name    string
+	Type    string
+	Desc    string
+	Systems []model.InternalSystem
+	Groups  []C4Group
+	//Style of the diagram, to style the systems of the group
+	Style C4Style
+}
+
+//C4SystemContainer type hold data structure to render Container diagrams.
+//It is the data given to the container template, fields are only ever added to it.
 type C4SystemContainer struct {
-	Title     string
-	Systems   map[string][]model.Container
-	Users     []model.User
+	//Title of the diagram
+	Title string
+	//Systems map each targeted system name to its containers
+	Systems map[string][]model.Container
+	//Users interacting with the targeted systems
+	Users []model.User
+	//Relations between the drawn elements
 	Relations []C4Relation
+	//Neighbors are the external systems interacting with the targeted systems,
+	//along with their containers the targeted systems interact with
 	Neighbors []model.ExternalSystem
+	//Style of the diagram, to render with {{template "style" .Style}}
+	Style C4Style
+}
+
+//C4ContainerComponent type hold data structure to render Component diagrams.
+//It is the data given to the component template, fields are only ever added to it.
+type C4ContainerComponent struct {
+	//Title of the diagram
+	Title string
+	//Containers map each targeted container path to the container and its components
+	Containers map[string]model.Container
+	//Neighbors are all other elements interacting with the components
+	Neighbors []C4Neighbor
+	//Relations between the drawn elements
+	Relations []C4Relation
+	//Style of the diagram, to render with {{template "style" .Style}}
+	Style C4Style
 }
 
-//C4Neighbor is the generic presentation for any partnering elements
+//C4Neighbor is the generic presentation for any partnering elements.
+//Kind is one of person, external_person, system, external_system, container, external_container or component.
 type C4Neighbor struct {
-	Name string
-	Desc string
+	ID         string
+	Name       string
+	Desc       string
+	Technology string
+	Icon       string
+	Kind       string
+	//ContainerKind is the kind of a container neighbor, see model.Container.ResolveKind
+	ContainerKind string
+	Tags          []string
 }
 
-//C4Relation is the data struct to draw relation in C4
+//C4Relation is the data struct to draw relation in C4, to render with {{template "relation" .}}
 type C4Relation struct {
 	Subject     string
 	Object      string
 	Pointer     string
 	PointerTech string
+	Arrow       string
+}
+
+var funcMap = template.FuncMap{
+	"CleanUp":        cleanUp,
+	"CleanID":        cleanID,
+	"Include":        c4Include,
+	"ContainerMacro": containerMacro,
+	"Join":           strings.Join,
+	"Upper":          strings.ToUpper,
+	"Lower":          strings.ToLower,
+	"Replace":        strings.ReplaceAll,
+	"Default":        defaultText,
+	"Date":           date,
+}
+
+//c4Relations prepare the relations to draw with the arrows of their styled tags
+func c4Relations(relations []model.Relation, style C4Style) []C4Relation {
+	rels := make([]C4Relation, 0)
+	for _, r := range relations {
+		rels = append(rels, C4Relation{
+			Subject:     r.Subject,
+			Object:      r.Object,
+			Pointer:     cleanRelation(r.Pointer),
+			PointerTech: defaultText(parseRelationTech(r.Pointer), r.Technology),
+			Arrow:       style.Arrow(r.Tags),
+		})
+	}
+	return rels
 }
 
 //C4ContextPuml generate puml code for Context diagram using the given ArcType data
 func C4ContextPuml(arcData model.ArcType, targets ...string) (string, error) {
+	return builtins.C4ContextPuml(arcData, targets...)
+}
+
+//C4ContextPuml generate puml code for Context diagram using the given ArcType data, with the Landscape or Context template
+func (t *Templates) C4ContextPuml(arcData model.ArcType, targets ...string) (string, error) {
 	if arcData.App == "" || arcData.Desc == "" {
 		return "", errors.New("Context require Application name and description")
 	}
-	funcMap := template.FuncMap{
-		"CleanUp": cleanUp,
-		"CleanID": cleanID,
-	}
-	contextTemplate, err := template.New("c4ContextTemplate").Funcs(funcMap).Parse(c4ContextTemplate)
-	if err != nil {
-		log.Println("Fail to parse tpl file")
-		return "", err
-	}
 	data, err := c4ContextParse(arcData, targets...)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
-	puml := []byte{}
-	wr := bytes.NewBuffer(puml)
-
-	if err = contextTemplate.ExecuteTemplate(wr, "c4ContextTemplate", data); err != nil {
-		return "", err
+	if len(targets) != 0 && len(targets) != len(arcData.InternalSystems) {
+		return t.execute(data, c4ContextTemplate, "context")
 	}
-
-	return wr.String(), nil
+	return t.execute(data, c4ContextTemplate, "landscape", "context")
 }
 
 //c4ContextParse prepare the data structure to render C4 full Landscape or targeted Context diagram
 func c4ContextParse(arcData model.ArcType, targets ...string) (C4Context, error) {
 	// sys := relMap(arcData, targets...)
-	relations := make([]C4Relation, 0)
-	for _, relation := range arcData.Relations {
-
-		relations = append(relations, C4Relation{
-			Subject:     relation.Subject,
-			Pointer:     cleanRelation(relation.Pointer),
-			PointerTech: parseRelationTech(relation.Pointer),
-			Object:      relation.Object,
-		})
-
-	}
+	style := newC4Style(arcData.Styles, contextKinds)
+	relations := c4Relations(arcData.Relations, style)
 	var title string
 	if len(targets) != 0 && len(targets) != len(arcData.InternalSystems) {
 		title = fmt.Sprintf("System Context view for: %s", strings.Join(targets, ", "))
 	} else {
 		title = fmt.Sprintf("System Landscape view for: %s", arcData.App)
 	}
+	systems := make(map[string]model.InternalSystem, len(arcData.InternalSystems))
+	shown := make(map[string]bool, len(arcData.InternalSystems))
+	for _, iSys := range arcData.InternalSystems {
+		systems[iSys.Name] = iSys
+		shown[iSys.Name] = true
+	}
+	groups := c4Groups(model.PruneGroups(arcData.Groups, shown), systems, style)
+	ungrouped := make([]model.InternalSystem, 0)
+	for _, iSys := range arcData.InternalSystems {
+		if _, ok := systems[iSys.Name]; ok {
+			ungrouped = append(ungrouped, iSys)
+		}
+	}
 	return C4Context{
 		Title:     title,
 		Arc:       arcData,
 		Relations: relations,
+		Style:     style,
+		Groups:    groups,
+		Ungrouped: ungrouped,
 	}, nil
 }
 
+//c4Groups prepare the groups to draw, each system being drawn in the first group listing it.
+//Drawn systems are removed from the remaining ones.
+func c4Groups(groups []model.Group, systems map[string]model.InternalSystem, style C4Style) []C4Group {
+	c4groups := make([]C4Group, 0)
+	for _, g := range groups {
+		group := C4Group{
+			ID:      cleanID(g.Name) + "__group",
+			Name:    g.Name,
+			Type:    defaultText("Group", g.Type),
+			Desc:    g.Desc,
+			Systems: make([]model.InternalSystem, 0),
+			Style:   style,
+		}
+		for _, name := range g.Systems {
+			if iSys, ok := systems[name]; ok {
+				group.Systems = append(group.Systems, iSys)
+				delete(systems, name)
+			}
+		}
+		group.Groups = c4Groups(g.Groups, systems, style)
+		if len(group.Systems) > 0 || len(group.Groups) > 0 {
+			c4groups = append(c4groups, group)
+		}
+	}
+	return c4groups
+}
+
 //C4ContainerPuml generate the C4 plantUml code from ArcType data to draw Container diagram for target Systems
 func C4ContainerPuml(arcData model.ArcType, targets ...string) (string, error) {
+	return builtins.C4ContainerPuml(arcData, targets...)
+}
 
-	funcMap := template.FuncMap{
-		"CleanUp": cleanUp,
-		"CleanID": cleanID,
-	}
-	containerTemplate, err := template.New("c4ContainerTemplate").Funcs(funcMap).Parse(c4ContainerTemplate)
-	if err != nil {
-		log.Println("Fail to parse tpl")
-		return "", err
-	}
+//C4ContainerPuml generate the C4 plantUml code from ArcType data to draw Container diagram for target Systems, with the Container template
+func (t *Templates) C4ContainerPuml(arcData model.ArcType, targets ...string) (string, error) {
 	data, err := c4ContainerParse(arcData)
 	if err != nil {
 		log.Println(err)
 		return "", err
 	}
 	data.Title = fmt.Sprintf("System Container view for: %s", strings.Join(targets, ", "))
-	puml := []byte{}
-	wr := bytes.NewBuffer(puml)
-
-	if err = containerTemplate.ExecuteTemplate(wr, "c4ContainerTemplate", data); err != nil {
-		return "", err
-	}
-
-	return wr.String(), nil
+	return t.execute(data, c4ContainerTemplate, "container")
 }
 
 //c4ContainerParse return the data to render Container diagram for given target system and clip out all others.
 func c4ContainerParse(arcData model.ArcType) (C4SystemContainer, error) {
 
-	rels := make([]C4Relation, 0)
+	style := newC4Style(arcData.Styles, containerKinds)
 	sys := make(map[string][]model.Container, 0)
 	for _, s := range arcData.InternalSystems {
 		sys[s.Name] = s.Containers
 	}
-	for _, r := range arcData.Relations {
-		rels = append(rels, C4Relation{
-			Subject:     r.Subject,
-			Object:      r.Object,
-			Pointer:     cleanRelation(r.Pointer),
-			PointerTech: parseRelationTech(r.Pointer),
-		})
-	}
+	rels := c4Relations(arcData.Relations, style)
 
 	return C4SystemContainer{
 		Systems:   sys,
 		Users:     arcData.Users,
 		Relations: rels,
 		Neighbors: arcData.ExternalSystems,
+		Style:     style,
 	}, nil
 }
 
-/* Map up all primary top path between 2 systems
-by folding all relations into parent targeted systems */
+//C4ComponentPuml generate the C4 plantUml code to draw Component diagram for the target Containers
+func C4ComponentPuml(containers map[string]model.Container, neighbors []C4Neighbor, relations []model.Relation, styles model.Styles) (string, error) {
+	return builtins.C4ComponentPuml(containers, neighbors, relations, styles)
+}
+
+//C4ComponentPuml generate the C4 plantUml code to draw Component diagram for the target Containers, with the Component template
+func (t *Templates) C4ComponentPuml(containers map[string]model.Container, neighbors []C4Neighbor, relations []model.Relation, styles model.Styles) (string, error) {
+	if len(containers) == 0 {
+		return "", errors.New("Component view require at least one container")
+	}
+	targets := make([]string, 0, len(containers))
+	for k := range containers {
+		targets = append(targets, k)
+	}
+	sort.Strings(targets)
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].ID < neighbors[j].ID })
+	style := newC4Style(styles, componentKinds)
+	data := C4ContainerComponent{
+		Title:      fmt.Sprintf("Container Component view for: %s", strings.Join(targets, ", ")),
+		Containers: containers,
+		Neighbors:  neighbors,
+		Relations:  c4Relations(relations, style),
+		Style:      style,
+	}
+	return t.execute(data, c4ComponentTemplate, "component")
+}
+
+/*
+	Map up all primary top path between 2 systems
+
+by folding all relations into parent targeted systems
+*/
 func relMap(arcData model.ArcType, targets ...string) map[string][]string {
 	sys := make(map[string][]string)
 	tmap := make(map[string]int)
@@ -202,12 +325,47 @@ func parseRelationTech(rel string) string {
 //Utilities function for template map
 func cleanRelation(rel string) string {
 	r := regexp.MustCompile(`\((.*?)\)`)
-	return r.ReplaceAllString(rel, "")
+	return strings.TrimSpace(r.ReplaceAllString(rel, ""))
 }
 
+//containerMacro return the C4 macro drawing a container of the given kind
+func containerMacro(kind string) string {
+	switch kind {
+	case model.ContainerDatabase:
+		return "ContainerDb"
+	case model.ContainerQueue:
+		return "ContainerQueue"
+	case model.ContainerFileStore:
+		return "ContainerFiles"
+	case model.ContainerBatch:
+		return "ContainerBatch"
+	default:
+		return "Container"
+	}
+}
+
+//cleanUp escape a text so it is shown as is within a quoted label of a C4 macro.
+//Line breaks are collapsed so the text can not start a new directive line, and every character
+//meaningful to the PlantUML preprocessor, macro arguments or creole markup is replaced by its html entity.
 func cleanUp(s string) string {
-	return strings.ReplaceAll(s, "\n", " ")
+	var sb strings.Builder
+	for _, r := range strings.Join(strings.Fields(s), " ") {
+		switch {
+		case unicode.IsControl(r):
+			continue
+		case strings.ContainsRune(labelSpecials, r):
+			fmt.Fprintf(&sb, "&#%d;", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
 }
+
+//labelSpecials list the characters escaped in labels
+const labelSpecials = "\"(),!<>&\\$%_~#[]{}@"
+
+//cleanID turn an element path into a valid PlantUML alias, "__" being left free to suffix the generated aliases
 func cleanID(s string) string {
-	return strings.ReplaceAll(s, "-", "")
+	return analyzer.EncodeID(s)
 }
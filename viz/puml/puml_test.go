@@ -1,6 +1,8 @@
 package puml
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	model "github.com/koderizer/arc/model"
@@ -46,3 +48,437 @@ System_Ext(testexternsys, "To test external system", "external system test")
 		}
 	}
 }
+
+func TestC4ComponentPuml(t *testing.T) {
+	containers := map[string]model.Container{
+		"sys-a.api": {
+			Name: "api",
+			Components: []model.Component{
+				{Name: "handler", Technology: "golang", Desc: "serve\nrequests"},
+			},
+		},
+	}
+	neighbors := []C4Neighbor{
+		{ID: "user-1", Name: "user-1", Kind: "person"},
+		{ID: "sys-a.db", Name: "sys-a.db", Kind: "container", ContainerKind: model.ContainerDatabase, Technology: "dgraph"},
+		{ID: "ext", Name: "ext", Kind: "external_system"},
+	}
+	relations := []model.Relation{
+		{Subject: "user-1", Pointer: "call (https)", Object: "sys-a.api.handler"},
+		{Subject: "sys-a.api.handler", Pointer: "persist", Object: "sys-a.db"},
+	}
+	actual, err := C4ComponentPuml(containers, neighbors, relations, model.Styles{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		`Container_Boundary(sys_2da_2eapi, "sys-a.api"){`,
+		`Component(sys_2da_2eapi_2ehandler, "handler", "golang", "serve requests", "<&code>\n", component)`,
+		`Person(user_2d1, "user-1", "")`,
+		`ContainerDb(sys_2da_2edb, "sys-a.db", "dgraph", "", "<&hard-drive>\n", container)`,
+		`System_Ext(ext, "ext", "")`,
+		`Rel(user_2d1, sys_2da_2eapi_2ehandler, "call", "https")`,
+		`Rel(sys_2da_2eapi_2ehandler, sys_2da_2edb, "persist")`,
+	} {
+		if !strings.Contains(actual, expect) {
+			t.Errorf("Expect puml to contain %s, actual puml is\n%s", expect, actual)
+		}
+	}
+
+	if _, err := C4ComponentPuml(nil, nil, nil, model.Styles{}); err == nil {
+		t.Error("Expect error without container")
+	}
+}
+
+func TestC4Include(t *testing.T) {
+	var includeTests = []struct {
+		level  string
+		expect []string
+	}{
+		{"C4_Context", []string{"!define LAYOUT_TOP_DOWN", "!define Person(e_alias, e_label)", "!define System_Ext("}},
+		{"C4_Container", []string{"!define LAYOUT_TOP_DOWN", "!define System_Ext(", "!define Container(e_alias, e_label, e_techn)"}},
+		{"C4_Component", []string{"!define LAYOUT_TOP_DOWN", "!define Container_Boundary(", "!define Component(e_alias, e_label, e_techn)"}},
+	}
+	for _, tt := range includeTests {
+		actual, err := c4Include(tt.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(actual, "!include") {
+			t.Errorf("%s still contains include directive", tt.level)
+		}
+		for _, e := range tt.expect {
+			if strings.Count(actual, e) < 1 {
+				t.Errorf("%s expect to define %s", tt.level, e)
+			}
+		}
+		if strings.Count(actual, "!define ELEMENT_FONT_COLOR") != 1 {
+			t.Errorf("%s expect base library inlined exactly once", tt.level)
+		}
+	}
+	if _, err := c4Include("C4_Unknown"); err == nil {
+		t.Error("Expect error on unknown library level")
+	}
+
+	src, err := C4ContainerPuml(model.ArcType{App: "app", Desc: "desc"}, "sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(src, "!include") || !strings.Contains(src, "!define Container(") {
+		t.Errorf("Expect container puml to inline the C4 library, actual is\n%s", src)
+	}
+
+	included, levels := IncludeLibrary(src, "c4")
+	if !reflect.DeepEqual(levels, []string{"C4_Container"}) || !strings.Contains(included, "@startuml\n!include c4/C4_Container.puml\n") ||
+		strings.Contains(included, "!define LAYOUT_TOP_DOWN") || !strings.Contains(included, "title ") {
+		t.Errorf("Expect the library to be included from c4/C4_Container.puml, get %v\n%s", levels, included)
+	}
+	library, err := Library("C4_Container")
+	if err != nil || strings.Replace(included, "!include c4/C4_Container.puml\n", library, 1) != src {
+		t.Errorf("Expect the included library to be the inlined one: %v", err)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	var labelTests = []struct {
+		in  string
+		out string
+	}{
+		{"plain text", "plain text"},
+		{"multi\nline\r\n  text", "multi line text"},
+		{`say "hi"`, "say &#34;hi&#34;"},
+		{"close) , (open", "close&#41; &#44; &#40;open"},
+		{"desc\n!include /etc/passwd", "desc &#33;include /etc/passwd"},
+		{"<img:http://evil>", "&#60;img:http://evil&#62;"},
+		{"LAYOUT_TOP_DOWN $var %x", "LAYOUT&#95;TOP&#95;DOWN &#36;var &#37;x"},
+	}
+	for _, tt := range labelTests {
+		if actual := cleanUp(tt.in); actual != tt.out {
+			t.Errorf("cleanUp(%q) expect %s, actual %s", tt.in, tt.out, actual)
+		}
+	}
+
+	adversarial := model.ArcType{
+		App:   "evil\"app",
+		Desc:  "desc\n@enduml\n!include /etc/passwd",
+		Users: []model.User{{Name: "a-b", Role: "role \"quoted\")"}},
+		InternalSystems: []model.InternalSystem{
+			{Name: "ab", Desc: "sys)\n!define X"},
+		},
+		Relations: []model.Relation{
+			{Subject: "a-b", Pointer: "call \"x\", y (tech\")", Object: "ab"},
+		},
+	}
+	src, err := C4ContextPuml(adversarial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := src[strings.Index(src, "title "):]
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "!") || strings.HasPrefix(line, "@enduml") && line != "@enduml" {
+			t.Errorf("Injected directive line %q", line)
+		}
+		if strings.Count(line, `"`)%2 != 0 {
+			t.Errorf("Unbalanced quotes in line %q", line)
+		}
+	}
+	if strings.Count(body, "@enduml") != 1 {
+		t.Errorf("Expect a single @enduml, actual puml is\n%s", body)
+	}
+	for _, expect := range []string{
+		`Person(a_2db, "a-b", "role &#34;quoted&#34;&#41;")`,
+		`System(ab, "ab", "sys&#41; &#33;define X")`,
+		`Rel(a_2db, ab, "call &#34;x&#34;&#44; y", "tech&#34;")`,
+	} {
+		if !strings.Contains(body, expect) {
+			t.Errorf("Expect puml to contain %s, actual puml is\n%s", expect, body)
+		}
+	}
+}
+
+func TestC4Style(t *testing.T) {
+	arc := model.ArcType{
+		App:  "style-test",
+		Desc: "Styled landscape",
+		Users: []model.User{
+			{Name: "admin", Role: "operate", Tags: []string{"staff"}},
+			{Name: "buyer", Role: "shop"},
+			{Name: "auditor", Role: "audit", External: true, Tags: []string{"deprecated"}},
+		},
+		InternalSystems: []model.InternalSystem{{Name: "shop", Desc: "sell", Tags: []string{"legacy"}}},
+		ExternalSystems: []model.ExternalSystem{{Name: "bank", Desc: "pay"}, {Name: "psp", Desc: "old payments", Tags: []string{"deprecated"}}},
+		Relations: []model.Relation{
+			{Subject: "shop", Pointer: "charge (https)", Object: "bank", Tags: []string{"async"}},
+			{Subject: "buyer", Pointer: "browse", Object: "shop"},
+		},
+		Styles: model.Styles{
+			Layout: "left-right",
+			Legend: true,
+			Sketch: true,
+			Elements: []model.ElementStyle{
+				{Kind: "system", Background: "#2E7D32"},
+				{Tag: "legacy", Background: "#B71C1C", Line: "dashed", Legend: "legacy (to retire)"},
+				{Tag: "unused", Background: "red; evil"},
+				{Tag: "deprecated", Line: "dotted"},
+			},
+			Relations: []model.RelationStyle{{Tag: "async", Color: "FF9800", Line: "dashed", Legend: "asynchronous"}},
+		},
+	}
+	actual, err := C4ContextPuml(arc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"LAYOUT_LEFT_RIGHT",
+		"skinparam handwritten true",
+		"skinparam rectangle<<system>> {\n    BackgroundColor #2E7D32\n}",
+		"skinparam rectangle<<system_legacy>> {\n    StereotypeFontColor ELEMENT_FONT_COLOR\n    FontColor ELEMENT_FONT_COLOR\n" +
+			"    BorderColor SYSTEM_BG_COLOR\n    BackgroundColor #B71C1C\n    BorderStyle dashed\n}",
+		`System_Tag(shop, "shop", "sell", system_legacy)`,
+		//tagged elements keep the look of their kind for the params not set by the tag style
+		"skinparam rectangle<<external_system_deprecated>> {\n    StereotypeFontColor ELEMENT_FONT_COLOR\n    FontColor ELEMENT_FONT_COLOR\n" +
+			"    BackgroundColor EXTERNAL_SYSTEM_BG_COLOR\n    BorderColor EXTERNAL_SYSTEM_BG_COLOR\n    BorderStyle dotted\n}",
+		`System_Tag(psp, "psp", "old payments", external_system_deprecated)`,
+		"skinparam actor<<external_person_deprecated>> {\n    StereotypeFontColor ELEMENT_FONT_COLOR\n    FontColor ELEMENT_FONT_COLOR\n" +
+			"    BackgroundColor EXTERNAL_PERSON_BG_COLOR\n    BorderColor EXTERNAL_PERSON_BG_COLOR\n    BorderStyle dotted\n}",
+		`Person_Tag(auditor, "auditor", "audit", external_person_deprecated)`,
+		`Person(admin, "admin", "operate")`,
+		`Person(buyer, "buyer", "shop")`,
+		`Rel_(shop, bank, "charge", "https", "-[#FF9800,dashed]->")`,
+		`Rel(buyer, shop, "browse")`,
+		"hide stereotype",
+		"|<#2E7D32>      | system |",
+		"|<#B71C1C>      | legacy &#40;to retire&#41; |",
+		"|<#FF9800>      | asynchronous |",
+	} {
+		if !strings.Contains(actual, expect) {
+			t.Errorf("Expect styled puml to contain %q, actual puml is\n%s", expect, actual)
+		}
+	}
+	if strings.Contains(actual, "evil") {
+		t.Errorf("Expect invalid colors to be dropped, actual puml is\n%s", actual)
+	}
+	if library, err := c4Include("C4_Context"); err != nil || strings.Contains(strings.Replace(actual, library, "", 1), "SOLARIZED_COLOR_BLUE") {
+		t.Errorf("Expect tagged elements to only get the params set by their style, actual puml is\n%s", actual)
+	}
+
+	plain, err := C4ContextPuml(model.ArcType{App: "plain", Desc: "Plain landscape", Relations: arc.Relations})
+	if err != nil {
+		t.Fatal(err)
+	}
+	library, err := c4Include("C4_Context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain = strings.Replace(plain, library, "", 1)
+	for _, unexpect := range []string{"LAYOUT_LEFT_RIGHT", "handwritten", "legend right", "Rel_("} {
+		if strings.Contains(plain, unexpect) {
+			t.Errorf("Expect unstyled puml not to contain %q, actual puml is\n%s", unexpect, plain)
+		}
+	}
+}
+
+func TestC4Icons(t *testing.T) {
+	styles := model.Styles{
+		Elements: []model.ElementStyle{{Tag: "core", Background: "#000000"}},
+		Icons: []model.IconStyle{
+			{Name: "acme-bus", Technologies: []string{"acmemq"}, Sprite: "[4x4/16] {\n0FF0\nF00F\nF00F\n0FF0\n}"},
+			{Name: "cache", Technologies: []string{"golang"}, Openiconic: "bolt"},
+			{Name: "evil", Technologies: []string{"evil"}, Sprite: "[4x4/16] {}\n!include http://evil"},
+		},
+	}
+	var iconTests = []struct {
+		technology string
+		icon       string
+		expect     string
+	}{
+		{"dgraph", "", "<&hard-drive>"},
+		{"Docker-Jetty", "", "<&box>"},
+		{"javascript-spa", "", "<&browser>"},
+		{"gRPC service, plantuml", "", "<&link-intact>"},
+		{"AcmeMQ", "", "<$arc_acme_2dbus>"},
+		{"golang", "", "<&bolt>"},
+		{"golang", "none", ""},
+		{"golang", "acme-bus", "<$arc_acme_2dbus>"},
+		{"", "cloud", "<&cloud>"},
+		{"", "<&evil>", ""},
+		{"evil", "", ""},
+		{"cobol", "", ""},
+	}
+	style := newC4Style(styles, containerKinds)
+	for _, tt := range iconTests {
+		if actual := style.Icon(tt.technology, tt.icon); actual != tt.expect {
+			t.Errorf("Icon(%q, %q) expect %q, get %q", tt.technology, tt.icon, tt.expect, actual)
+		}
+	}
+
+	actual, err := C4ContainerPuml(model.ArcType{
+		InternalSystems: []model.InternalSystem{{Name: "s1", Containers: []model.Container{
+			{Name: "bus", Technology: "acmemq", Tags: []string{"core"}},
+			{Name: "legacy", Technology: "cobol"},
+		}}},
+		Styles: styles,
+	}, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"sprite $arc_acme_2dbus [4x4/16] {\n0FF0\nF00F\nF00F\n0FF0\n}",
+		`Container(s1_2ebus, "bus", "acmemq", "", "<$arc_acme_2dbus>\n", container_core)`,
+		`Container(s1_2elegacy, "legacy", "cobol", "")`,
+	} {
+		if !strings.Contains(actual, expect) {
+			t.Errorf("Expect puml to contain %q, actual puml is\n%s", expect, actual)
+		}
+	}
+	if strings.Contains(actual, "evil") {
+		t.Errorf("Expect invalid sprite to be dropped, actual puml is\n%s", actual)
+	}
+}
+
+func TestContainerKinds(t *testing.T) {
+	arc := model.ArcType{
+		InternalSystems: []model.InternalSystem{{Name: "s1", Containers: []model.Container{
+			{Name: "api", Technology: "grpc service", Icon: "none"},
+			{Name: "db", Runtime: "database", Technology: "dgraph", Icon: "none"},
+			{Name: "events", Technology: "kafka", Icon: "none"},
+			{Name: "files", Kind: "file store", Technology: "minio", Icon: "none"},
+			{Name: "nightly", Runtime: "k8s-cronjob", Icon: "none"},
+			{Name: "cache", Kind: "service", Technology: "redis", Icon: "none"},
+		}}},
+	}
+	actual, err := C4ContainerPuml(arc, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		`Container(s1_2eapi, "api", "grpc service", "")`,
+		`ContainerDb(s1_2edb, "db", "dgraph", "")`,
+		`ContainerQueue(s1_2eevents, "events", "kafka", "")`,
+		`ContainerFiles(s1_2efiles, "files", "minio", "")`,
+		`ContainerBatch(s1_2enightly, "nightly", "", "")`,
+		`Container(s1_2ecache, "cache", "redis", "")`,
+		"!define ContainerQueue(e_alias, e_label, e_techn, e_descr) queue",
+	} {
+		if !strings.Contains(actual, expect) {
+			t.Errorf("Expect puml to contain %q, actual puml is\n%s", expect, actual)
+		}
+	}
+}
+
+func TestExternalElements(t *testing.T) {
+	arc := model.ArcType{
+		App:   "ext-test",
+		Desc:  "External elements",
+		Users: []model.User{{Name: "ops", External: true}, {Name: "dev"}},
+		InternalSystems: []model.InternalSystem{
+			{Name: "s1", Containers: []model.Container{{Name: "api", Icon: "none"}}},
+		},
+		ExternalSystems: []model.ExternalSystem{
+			{Name: "partner", Containers: []model.Container{
+				{Name: "gateway", Technology: "nginx", Icon: "none"},
+				{Name: "events", Technology: "kafka", Icon: "none"},
+			}},
+			{Name: "e1"},
+		},
+	}
+	actual, err := C4ContainerPuml(arc, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		`Person_Ext(ops, "ops")`,
+		`Person(dev, "dev")`,
+		`External_Boundary(partner, "partner"){`,
+		`Container(partner_2egateway, "gateway", "nginx", "", "", external_container)`,
+		`ContainerQueue(partner_2eevents, "events", "kafka", "", "", external_container)`,
+		`System_Ext(e1, "e1", "")`,
+		"skinparam queue<<external_container>> {",
+	} {
+		if !strings.Contains(actual, expect) {
+			t.Errorf("Expect puml to contain %q, actual puml is\n%s", expect, actual)
+		}
+	}
+
+	actual, err = C4ContextPuml(arc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(actual, `Person_Ext(ops, "ops", "")`) {
+		t.Errorf("Expect external user in context view, actual puml is\n%s", actual)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	arc := model.ArcType{
+		App:  "group-test",
+		Desc: "Groups",
+		InternalSystems: []model.InternalSystem{
+			{Name: "ledger", Desc: "Ledger"},
+			{Name: "checkout", Desc: "Checkout"},
+			{Name: "search", Desc: "Search"},
+		},
+		Groups: []model.Group{
+			{Name: "commerce", Type: "Business Unit", Systems: []string{"checkout"}, Groups: []model.Group{
+				{Name: "payments", Systems: []string{"ledger"}},
+			}},
+			{Name: "empty", Systems: []string{"unknown"}},
+		},
+	}
+	actual, err := C4ContextPuml(arc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := []string{
+		`Enterprise_Boundary(group_2dtest__boundary, "Groups") {`,
+		`Boundary(commerce__group, "commerce", "Business Unit") {`,
+		`Boundary(payments__group, "payments", "Group") {`,
+		`System(ledger, "ledger", "Ledger")`,
+		`System(checkout, "checkout", "Checkout")`,
+		`System(search, "search", "Search")`,
+	}
+	last := -1
+	for _, expect := range order {
+		i := strings.Index(actual, expect)
+		if i <= last {
+			t.Fatalf("Expect puml to contain %q after %q, actual puml is\n%s", expect, order, actual)
+		}
+		last = i
+	}
+	if strings.Contains(actual, "empty__group") {
+		t.Errorf("Expect groups without drawn systems to be dropped, actual puml is\n%s", actual)
+	}
+	if strings.Count(actual, "\tSystem(") != 3 {
+		t.Errorf("Expect each system to be drawn once, actual puml is\n%s", actual)
+	}
+}
+
+func TestRelationTechnology(t *testing.T) {
+	relations := c4Relations([]model.Relation{
+		{Subject: "a", Object: "b", Pointer: "call", Technology: "gRPC"},
+		{Subject: "a", Object: "c", Pointer: "call (https)"},
+		{Subject: "b", Object: "c", Pointer: "notify"},
+	}, newC4Style(model.Styles{}, contextKinds))
+	expect := []C4Relation{
+		{Subject: "a", Object: "b", Pointer: "call", PointerTech: "gRPC"},
+		{Subject: "a", Object: "c", Pointer: "call", PointerTech: "https"},
+		{Subject: "b", Object: "c", Pointer: "notify"},
+	}
+	for i, r := range relations {
+		if r != expect[i] {
+			t.Errorf("Expect relation %+v, get %+v", expect[i], r)
+		}
+	}
+}
+
+func TestElementKinds(t *testing.T) {
+	if len(c4Kinds) != len(model.ElementKinds) {
+		t.Fatalf("Expect the styled element kinds %v, get %+v", model.ElementKinds, c4Kinds)
+	}
+	for i, kind := range c4Kinds {
+		if kind.name != model.ElementKinds[i] {
+			t.Errorf("Expect element kind %s, get %s", model.ElementKinds[i], kind.name)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package puml
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/koderizer/arc/model"
+)
+
+//C4Style hold the layout, skinparams and legend of a diagram derived from the arc styles
+type C4Style struct {
+	Layout  string
+	Legend  bool
+	Sketch  bool
+	Skins   []C4Skin
+	Legends []C4Legend
+	Sprites []C4Sprite
+	tags    map[string]bool
+	arrows  map[string]string
+	icons   c4Icons
+}
+
+//C4Skin is a skinparam block applied to all elements of a shape and stereotype
+type C4Skin struct {
+	Selector string
+	Params   []string
+}
+
+//C4Legend is a row of the diagram legend
+type C4Legend struct {
+	Color string
+	Text  string
+}
+
+//c4Kind describe the default look of an element kind as defined by the C4-PlantUML library
+type c4Kind struct {
+	name   string
+	shapes []string
+	extra  []string
+	color  string
+	legend string
+}
+
+//c4Kinds list the element kinds in the order of the legend.
+//Shapes are styled by the C4-PlantUML library, extra shapes get a default style like the library ones.
+var c4Kinds = []c4Kind{
+	{"person", []string{"actor"}, nil, "PERSON_BG_COLOR", "person"},
+	{"external_person", []string{"actor"}, nil, "EXTERNAL_PERSON_BG_COLOR", "external person"},
+	{"system", []string{"rectangle", "database"}, nil, "SYSTEM_BG_COLOR", "system"},
+	{"external_system", []string{"rectangle", "database"}, nil, "EXTERNAL_SYSTEM_BG_COLOR", "external system"},
+	{"container", []string{"rectangle", "database"}, []string{"queue", "folder", "collections"}, "CONTAINER_BG_COLOR", "container"},
+	{"external_container", nil, []string{"rectangle", "database", "queue", "folder", "collections"}, "EXTERNAL_SYSTEM_BG_COLOR", "external container"},
+	{"component", []string{"rectangle", "database"}, nil, "COMPONENT_BG_COLOR", "component"},
+}
+
+//Element kinds shown in each level of diagram
+var (
+	contextKinds   = []string{"person", "external_person", "system", "external_system"}
+	containerKinds = append(contextKinds[:len(contextKinds):len(contextKinds)], "container", "external_container")
+	componentKinds = append(containerKinds[:len(containerKinds):len(containerKinds)], "component")
+)
+
+//validColor match a named or hexadecimal PlantUML color
+var validColor = regexp.MustCompile(`^#?[A-Za-z0-9]+$`)
+
+//newC4Style derive the style of a diagram showing the given element kinds
+func newC4Style(styles model.Styles, kinds []string) C4Style {
+	style := C4Style{
+		Layout: "LAYOUT_TOP_DOWN",
+		Legend: styles.Legend,
+		Sketch: styles.Sketch,
+		tags:   make(map[string]bool),
+		arrows: make(map[string]string),
+	}
+	if styles.Layout == "left-right" {
+		style.Layout = "LAYOUT_LEFT_RIGHT"
+	}
+	shown := make(map[string]bool)
+	for _, kind := range kinds {
+		shown[kind] = true
+	}
+	colors := make(map[string]string)
+	for _, kind := range c4Kinds {
+		if !shown[kind.name] {
+			continue
+		}
+		colors[kind.name] = kind.color
+		for _, shape := range kind.extra {
+			style.Skins = append(style.Skins, C4Skin{Selector: fmt.Sprintf("%s<<%s>>", shape, kind.name), Params: kindParams(kind)})
+		}
+	}
+	restyled := make(map[string][]string)
+	for _, es := range styles.Elements {
+		if es.Tag != "" || es.Kind == "" {
+			continue
+		}
+		for _, kind := range c4Kinds {
+			if kind.name != es.Kind {
+				continue
+			}
+			for _, shape := range append(kind.shapes[:len(kind.shapes):len(kind.shapes)], kind.extra...) {
+				style.Skins = append(style.Skins, C4Skin{Selector: fmt.Sprintf("%s<<%s>>", shape, kind.name), Params: elementParams(es)})
+			}
+			restyled[kind.name] = overrideParams(restyled[kind.name], elementParams(es))
+			if validColor.MatchString(es.Background) && shown[kind.name] {
+				colors[kind.name] = es.Background
+			}
+		}
+	}
+	//tagged elements get a stereotype per kind, so that the params not set by the tag style keep the look of the kind,
+	//as the bundled C4-PlantUML has no AddElementTag
+	for _, es := range styles.Elements {
+		if es.Tag == "" {
+			continue
+		}
+		style.tags[es.Tag] = true
+		params := elementParams(es)
+		for _, kind := range c4Kinds {
+			if !shown[kind.name] {
+				continue
+			}
+			defaults := overrideParams(kindParams(kind), restyled[kind.name])
+			for _, shape := range append(kind.shapes[:len(kind.shapes):len(kind.shapes)], kind.extra...) {
+				style.Skins = append(style.Skins, C4Skin{
+					Selector: fmt.Sprintf("%s<<%s>>", shape, tagStereotype(kind.name, es.Tag)),
+					Params:   overrideParams(defaults, params),
+				})
+			}
+		}
+	}
+	for _, rs := range styles.Relations {
+		if arrow := relationArrow(rs); rs.Tag != "" && arrow != "" {
+			style.arrows[rs.Tag] = arrow
+		}
+	}
+	style.icons, style.Sprites = newC4Icons(styles.Icons)
+	if !style.Legend {
+		return style
+	}
+	for _, kind := range c4Kinds {
+		if !shown[kind.name] {
+			continue
+		}
+		style.Legends = append(style.Legends, C4Legend{Color: colors[kind.name], Text: kind.legend})
+	}
+	for _, es := range styles.Elements {
+		if es.Tag != "" && es.Legend != "" {
+			color := "transparent"
+			if validColor.MatchString(es.Background) {
+				color = es.Background
+			} else if validColor.MatchString(es.Border) {
+				color = es.Border
+			}
+			style.Legends = append(style.Legends, C4Legend{Color: color, Text: cleanUp(es.Legend)})
+		}
+	}
+	for _, rs := range styles.Relations {
+		if rs.Tag != "" && rs.Legend != "" {
+			color := "SOLARIZED_COLOR_BASE1"
+			if validColor.MatchString(rs.Color) {
+				color = hexColor(rs.Color)
+			}
+			style.Legends = append(style.Legends, C4Legend{Color: color, Text: cleanUp(rs.Legend)})
+		}
+	}
+	return style
+}
+
+//kindParams return the skinparams giving an element the default look of its kind, like the C4-PlantUML library
+func kindParams(kind c4Kind) []string {
+	return []string{
+		"StereotypeFontColor ELEMENT_FONT_COLOR",
+		"FontColor ELEMENT_FONT_COLOR",
+		"BackgroundColor " + kind.color,
+		"BorderColor " + kind.color,
+	}
+}
+
+//overrideParams return the default skinparams not set by the given ones, followed by the given ones
+func overrideParams(defaults, params []string) []string {
+	set := make(map[string]bool)
+	for _, p := range params {
+		set[strings.Fields(p)[0]] = true
+	}
+	merged := make([]string, 0, len(defaults)+len(params))
+	for _, p := range defaults {
+		if !set[strings.Fields(p)[0]] {
+			merged = append(merged, p)
+		}
+	}
+	return append(merged, params...)
+}
+
+//tagStereotype return the stereotype of the elements of a kind carrying a styled tag
+func tagStereotype(kind, tag string) string {
+	return kind + "_" + cleanID(tag)
+}
+
+//elementParams turn an element style into skinparams
+func elementParams(es model.ElementStyle) []string {
+	params := make([]string, 0)
+	if validColor.MatchString(es.Background) {
+		params = append(params, "BackgroundColor "+es.Background)
+	}
+	if validColor.MatchString(es.Font) {
+		params = append(params, "FontColor "+es.Font, "StereotypeFontColor "+es.Font, "ActorFontColor "+es.Font)
+	}
+	if validColor.MatchString(es.Border) {
+		params = append(params, "BorderColor "+es.Border)
+	}
+	switch es.Line {
+	case "dashed", "dotted":
+		params = append(params, "BorderStyle "+es.Line)
+	case "bold":
+		params = append(params, "BorderThickness 3")
+	}
+	switch es.Shape {
+	case "rounded":
+		params = append(params, "RoundCorner 25")
+	case "rectangle":
+		params = append(params, "RoundCorner 0")
+	}
+	return params
+}
+
+//relationArrow turn a relation style into a PlantUML arrow
+func relationArrow(rs model.RelationStyle) string {
+	options := make([]string, 0)
+	if validColor.MatchString(rs.Color) {
+		options = append(options, hexColor(rs.Color))
+	}
+	switch rs.Line {
+	case "dashed", "dotted", "bold":
+		options = append(options, rs.Line)
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("-[%s]->", strings.Join(options, ","))
+}
+
+//hexColor prefix a color with the # expected by arrows and legends
+func hexColor(color string) string {
+	if strings.HasPrefix(color, "#") {
+		return color
+	}
+	return "#" + color
+}
+
+//Stereotype return the stereotype of an element of the given kind for the first styled tag in the list, if any
+func (s C4Style) Stereotype(tags []string, kind string) string {
+	for _, tag := range tags {
+		if s.tags[tag] {
+			return tagStereotype(kind, tag)
+		}
+	}
+	return ""
+}
+
+//Arrow return the arrow of the first styled tag in the list, if any
+func (s C4Style) Arrow(tags []string) string {
+	for _, tag := range tags {
+		if arrow, ok := s.arrows[tag]; ok {
+			return arrow
+		}
+	}
+	return ""
+}
+
+//Icon return the glyph to show on an element given its technology and explicit icon property, if any
+func (s C4Style) Icon(technology, icon string) string {
+	return s.icons.icon(technology, icon)
+}
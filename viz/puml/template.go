@@ -2,61 +2,183 @@ package puml
 
 const c4ContextTemplate = `
 @startuml
-!include /C4-PlantUML/C4_Context.puml
-
-title {{.Title}} 
+{{Include "C4_Context"}}
+{{template "style" .Style}}
+title {{.Title | CleanUp}}
+{{template "header" .}}
 {{range .Arc.Users}}
-Person({{.Name | CleanID}}, "{{.Name}}", "{{.Role | CleanUp}}")
+{{$tag := $.Style.Stereotype .Tags (or (and .External "external_person") "person")}}
+{{if $tag}}Person_Tag({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Role | CleanUp}}", {{$tag}}){{else if .External}}Person_Ext({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Role | CleanUp}}"){{else}}Person({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Role | CleanUp}}"){{end}}
 {{end}}
 
-Enterprise_Boundary({{.Arc.App}}, "{{.Arc.Desc}}") {
-{{range .Arc.InternalSystems}}
-	System({{.Name | CleanID}}, "{{.Name}}","{{.Desc | CleanUp}}")
+Enterprise_Boundary({{.Arc.App | CleanID}}__boundary, "{{.Arc.Desc | CleanUp}}") {
+{{range .Groups}}
+{{template "group" .}}
+{{end}}
+{{range .Ungrouped}}
+{{$tag := $.Style.Stereotype .Tags "system"}}
+	{{if $tag}}System_Tag({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}System({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
 {{end}}
 }
 {{range .Arc.ExternalSystems}}
-System_Ext({{.Name | CleanID}}, "{{.Name}}", "{{.Desc | CleanUp}}")
+{{$tag := $.Style.Stereotype .Tags "external_system"}}
+{{if $tag}}System_Tag({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}System_Ext({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
 {{end}}
 {{range .Relations}}
-{{if (ne .PointerTech "")}}
-Rel({{.Subject | CleanID}},{{.Object | CleanID}},"{{.Pointer}}","{{.PointerTech}}")
-{{else}}
-Rel({{.Subject | CleanID}},{{.Object | CleanID}},"{{.Pointer}}")
-{{end}}
+{{template "relation" .}}
 {{end}}
+{{template "footer" .}}
 @enduml`
 
 const c4ContainerTemplate = `
 @startuml
-!include /C4-PlantUML/C4_Container.puml
-
-title {{.Title}}
-
-LAYOUT_TOP_DOWN
+{{Include "C4_Container"}}
+{{template "style" .Style}}
+title {{.Title | CleanUp}}
+{{template "header" .}}
 {{range .Users}}
-Person({{.Name | CleanID}}, "{{.Name}}")
+{{$tag := $.Style.Stereotype .Tags (or (and .External "external_person") "person")}}
+{{if $tag}}Person_Tag({{.Name | CleanID}}, "{{.Name | CleanUp}}", "", {{$tag}}){{else if .External}}Person_Ext({{.Name | CleanID}}, "{{.Name | CleanUp}}"){{else}}Person({{.Name | CleanID}}, "{{.Name | CleanUp}}"){{end}}
 {{end}}
 
 {{range $k, $v := $.Systems}}
-{{$sys := $k | CleanID}}
-System_Boundary({{$sys}}, "{{$sys}}"){
+System_Boundary({{$k | CleanID}}, "{{$k | CleanUp}}"){
 {{range $v}}
-	Container({{$sys}}.{{.Name | CleanID}}, "{{.Name}}", "{{.Technology}}", "{{.Desc | CleanUp}}")
+{{$tag := $.Style.Stereotype .Tags "container"}}{{$icon := $.Style.Icon .Technology .Icon}}
+	{{ContainerMacro .ResolveKind}}({{(printf "%s.%s" $k .Name) | CleanID}}, "{{.Name | CleanUp}}", "{{.Technology | CleanUp}}", "{{.Desc | CleanUp}}"{{if (or $tag $icon)}}, "{{with $icon}}{{.}}\n{{end}}", {{$tag | Default "container"}}{{end}})
 {{end}}
 }
 {{end}}
 
 {{range .Neighbors}}
-System_Ext({{.Name | CleanID}}, "{{.Name}}", "{{.Desc | CleanUp}}")
+{{$tag := $.Style.Stereotype .Tags "external_system"}}
+{{if .Containers}}
+{{$sys := .Name}}
+External_Boundary({{$sys | CleanID}}, "{{$sys | CleanUp}}"){
+{{range .Containers}}
+{{$tag := $.Style.Stereotype .Tags "external_container"}}{{$icon := $.Style.Icon .Technology .Icon}}
+	{{ContainerMacro .ResolveKind}}({{(printf "%s.%s" $sys .Name) | CleanID}}, "{{.Name | CleanUp}}", "{{.Technology | CleanUp}}", "{{.Desc | CleanUp}}", "{{with $icon}}{{.}}\n{{end}}", {{$tag | Default "external_container"}})
+{{end}}
+}
+{{else if $tag}}System_Tag({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}System_Ext({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
 {{end}}
 
 {{range .Relations}}
-{{if (ne .PointerTech "")}}
-Rel({{.Subject | CleanID}},{{.Object | CleanID}},"{{.Pointer}}","{{.PointerTech}}")
+{{template "relation" .}}
+{{end}}
+
+{{template "footer" .}}
+@enduml
+`
+
+const c4ComponentTemplate = `
+@startuml
+{{Include "C4_Component"}}
+{{template "style" .Style}}
+title {{.Title | CleanUp}}
+{{template "header" .}}
+{{range $k, $v := .Containers}}
+Container_Boundary({{$k | CleanID}}, "{{$k | CleanUp}}"){
+{{range $v.Components}}
+{{$tag := $.Style.Stereotype .Tags "component"}}{{$icon := $.Style.Icon .Technology .Icon}}
+	Component({{(printf "%s.%s" $k .Name) | CleanID}}, "{{.Name | CleanUp}}", "{{.Technology | CleanUp}}", "{{.Desc | CleanUp}}"{{if (or $tag $icon)}}, "{{with $icon}}{{.}}\n{{end}}", {{$tag | Default "component"}}{{end}})
+{{end}}
+}
+{{end}}
+
+{{range .Neighbors}}
+{{$tag := $.Style.Stereotype .Tags .Kind}}{{$icon := $.Style.Icon .Technology .Icon}}
+{{if (eq .Kind "person")}}
+{{if $tag}}Person_Tag({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}Person({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
+{{else if (eq .Kind "external_person")}}
+{{if $tag}}Person_Tag({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}Person_Ext({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
+{{else if (eq .Kind "system")}}
+{{if $tag}}System_Tag({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}System({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
+{{else if (eq .Kind "container")}}
+{{ContainerMacro .ContainerKind}}({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Technology | CleanUp}}", "{{.Desc | CleanUp}}"{{if (or $tag $icon)}}, "{{with $icon}}{{.}}\n{{end}}", {{$tag | Default "container"}}{{end}})
+{{else if (eq .Kind "external_container")}}
+{{ContainerMacro .ContainerKind}}({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Technology | CleanUp}}", "{{.Desc | CleanUp}}", "{{with $icon}}{{.}}\n{{end}}", {{$tag | Default "external_container"}})
+{{else if (eq .Kind "component")}}
+Component({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Technology | CleanUp}}", "{{.Desc | CleanUp}}"{{if (or $tag $icon)}}, "{{with $icon}}{{.}}\n{{end}}", {{$tag | Default "component"}}{{end}})
 {{else}}
-Rel({{.Subject | CleanID}},{{.Object | CleanID}},"{{.Pointer}}")
+{{if $tag}}System_Tag({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}System_Ext({{.ID | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
 {{end}}
 {{end}}
 
+{{range .Relations}}
+{{template "relation" .}}
+{{end}}
+
+{{template "footer" .}}
 @enduml
 `
+
+//c4CommonTemplate define the parts shared by all C4 diagrams: styling, tagged elements, container shapes, relations,
+//and the header and footer left empty for overrides to fill.
+//Containers and components take 2 more arguments when styled: the icon line and the stereotype.
+const c4CommonTemplate = `
+{{define "header"}}{{end}}
+{{define "footer"}}{{end}}
+{{define "style"}}
+{{.Layout}}
+{{if .Sketch}}skinparam handwritten true{{end}}
+!define Person_Tag(e_alias, e_label, e_descr, e_tag) actor "==e_label\n\n e_descr" <<e_tag>> as e_alias
+!define System_Tag(e_alias, e_label, e_descr, e_tag) rectangle "==e_label\n\n e_descr" <<e_tag>> as e_alias
+!define External_Boundary(e_alias, e_label) rectangle "==e_label\n<size:TECHN_FONT_SIZE>[External System]</size>" <<external_boundary>> as e_alias
+skinparam rectangle<<external_boundary>> {
+    Shadowing false
+    StereotypeFontSize 0
+    FontColor EXTERNAL_SYSTEM_BG_COLOR
+    BorderColor EXTERNAL_SYSTEM_BG_COLOR
+    BorderStyle dashed
+}
+!define ContainerQueue(e_alias, e_label, e_techn, e_descr) queue "==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<container>> as e_alias
+!define ContainerFiles(e_alias, e_label, e_techn, e_descr) folder "==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<container>> as e_alias
+!define ContainerBatch(e_alias, e_label, e_techn, e_descr) collections "==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<container>> as e_alias
+!define Container(e_alias, e_label, e_techn, e_descr, e_icon, e_tag) rectangle "e_icon==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<e_tag>> as e_alias
+!define ContainerDb(e_alias, e_label, e_techn, e_descr, e_icon, e_tag) database "e_icon==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<e_tag>> as e_alias
+!define ContainerQueue(e_alias, e_label, e_techn, e_descr, e_icon, e_tag) queue "e_icon==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<e_tag>> as e_alias
+!define ContainerFiles(e_alias, e_label, e_techn, e_descr, e_icon, e_tag) folder "e_icon==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<e_tag>> as e_alias
+!define ContainerBatch(e_alias, e_label, e_techn, e_descr, e_icon, e_tag) collections "e_icon==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<e_tag>> as e_alias
+!define Component(e_alias, e_label, e_techn, e_descr, e_icon, e_tag) rectangle "e_icon==e_label\n//<size:TECHN_FONT_SIZE>[e_techn]</size>//\n\n e_descr" <<e_tag>> as e_alias
+{{range .Sprites}}
+sprite ${{.ID}} {{.Data}}
+{{end}}{{range .Skins}}
+skinparam {{.Selector}} {
+{{range .Params}}    {{.}}
+{{end}}}
+{{end}}
+{{if .Legend}}
+hide stereotype
+legend right
+|=              |= Type |
+{{range .Legends}}|<{{.Color}}>      | {{.Text}} |
+{{end}}endlegend
+{{end}}
+{{end}}
+
+{{define "group"}}
+{{$style := .Style}}
+Boundary({{.ID}}, "{{.Name | CleanUp}}", "{{.Type | CleanUp}}") {
+{{range .Groups}}
+{{template "group" .}}
+{{end}}
+{{range .Systems}}
+{{$tag := $style.Stereotype .Tags "system"}}
+	{{if $tag}}System_Tag({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}", {{$tag}}){{else}}System({{.Name | CleanID}}, "{{.Name | CleanUp}}", "{{.Desc | CleanUp}}"){{end}}
+{{end}}
+}
+{{end}}
+
+{{define "relation"}}
+{{if (and .Arrow (ne .PointerTech ""))}}
+Rel_({{.Subject | CleanID}}, {{.Object | CleanID}}, "{{.Pointer | CleanUp}}", "{{.PointerTech | CleanUp}}", "{{.Arrow}}")
+{{else if .Arrow}}
+Rel_({{.Subject | CleanID}}, {{.Object | CleanID}}, "{{.Pointer | CleanUp}}", "{{.Arrow}}")
+{{else if (ne .PointerTech "")}}
+Rel({{.Subject | CleanID}}, {{.Object | CleanID}}, "{{.Pointer | CleanUp}}", "{{.PointerTech | CleanUp}}")
+{{else}}
+Rel({{.Subject | CleanID}}, {{.Object | CleanID}}, "{{.Pointer | CleanUp}}")
+{{end}}
+{{end}}
+`
@@ -0,0 +1,130 @@
+package puml
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+//Templates resolve the template used to generate each perspective, preferring the overrides found in Dir.
+//
+//An override directory may hold any of the following files, written as Go text/template:
+//   - landscape.puml: the Landscape view, falling back to context.puml, executed with a C4Context
+//   - context.puml: the Context view, executed with a C4Context
+//   - container.puml: the Container view, executed with a C4SystemContainer
+//   - component.puml: the Component view, executed with a C4ContainerComponent
+//   - common.puml: definitions shared by all views, eg to redefine the "header" or "footer" blocks
+//     that the built-in templates render right after the title and before the end of the diagram
+//
+//Missing files fall back to the built-in templates. All templates can use the "style" and "relation" blocks
+//and the helper functions: CleanUp, CleanID, Include, Join, Upper, Lower, Replace, Default and Date.
+type Templates struct {
+	Dir string
+}
+
+//builtins only use the templates compiled in
+var builtins = &Templates{}
+
+//commonOverride is the file name of the definitions shared by all overridden views
+const commonOverride = "common"
+
+//NewTemplates return the templates overridden by the files of the given directory, and check that they parse.
+//An empty directory keep the built-in templates.
+func NewTemplates(dir string) (*Templates, error) {
+	t := &Templates{Dir: dir}
+	if dir == "" {
+		return t, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open template directory: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("template directory %s is not a directory", dir)
+	}
+	for _, name := range []string{"landscape", "context", "container", "component"} {
+		if _, err := t.lookup("", name); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+//execute render the data with the first overridden template among the given names, or the builtin one
+func (t *Templates) execute(data interface{}, builtin string, names ...string) (string, error) {
+	tpl, err := t.lookup(builtin, names...)
+	if err != nil {
+		return "", err
+	}
+	wr := &bytes.Buffer{}
+	if err := tpl.Execute(wr, data); err != nil {
+		return "", err
+	}
+	return wr.String(), nil
+}
+
+//lookup parse the template of a view along with the common definitions, overrides being read on each call
+func (t *Templates) lookup(builtin string, names ...string) (*template.Template, error) {
+	tpl, err := template.New(names[0]).Funcs(funcMap).Parse(c4CommonTemplate)
+	if err != nil {
+		return nil, err
+	}
+	common, ok, err := t.override(commonOverride)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if tpl, err = tpl.Parse(common); err != nil {
+			return nil, fmt.Errorf("fail to parse template %s: %v", t.path(commonOverride), err)
+		}
+	}
+	for _, name := range names {
+		body, ok, err := t.override(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if tpl, err = tpl.Parse(body); err != nil {
+				return nil, fmt.Errorf("fail to parse template %s: %v", t.path(name), err)
+			}
+			return tpl, nil
+		}
+	}
+	return tpl.Parse(builtin)
+}
+
+//override read the overriding template of the given name, if any
+func (t *Templates) override(name string) (string, bool, error) {
+	if t == nil || t.Dir == "" {
+		return "", false, nil
+	}
+	content, err := ioutil.ReadFile(t.path(name))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("fail to read template: %v", err)
+	}
+	return string(content), true, nil
+}
+
+func (t *Templates) path(name string) string {
+	return filepath.Join(t.Dir, name+".puml")
+}
+
+//defaultText return the text, or the given default if it is empty
+func defaultText(def, text string) string {
+	if text == "" {
+		return def
+	}
+	return text
+}
+
+//date format the current time with the given Go layout
+func date(layout string) string {
+	return time.Now().Format(layout)
+}
@@ -0,0 +1,89 @@
+package puml
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	model "github.com/koderizer/arc/model"
+)
+
+func TestTemplates(t *testing.T) {
+	arc := model.ArcType{
+		App:             "tpl-test",
+		Desc:            "Template test",
+		Users:           []model.User{{Name: "dev", Role: "develop"}},
+		InternalSystems: []model.InternalSystem{{Name: "s1", Desc: "one", Containers: []model.Container{{Name: "api"}}}, {Name: "s2", Desc: "two"}},
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"common.puml":  "{{define \"header\"}}header ACME {{Date \"2006\"}}{{end}}\n{{define \"footer\"}}note as N\n{{.Title | Upper}} {{\"\" | Default \"none\"}}\nend note{{end}}",
+		"context.puml": "@startuml\n{{template \"header\" .}}\n{{range .Arc.InternalSystems}}{{.Name | CleanID}} {{end}}\n@enduml",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := NewTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var templateTests = []struct {
+		name     string
+		generate func() (string, error)
+		expect   []string
+		unexpect []string
+	}{
+		{
+			name:     "context override",
+			generate: func() (string, error) { return templates.C4ContextPuml(arc, "s1") },
+			expect:   []string{"@startuml\nheader ACME ", "s1 s2 \n@enduml"},
+			unexpect: []string{"Enterprise_Boundary", "note as N"},
+		},
+		{
+			name:     "landscape fall back to context override",
+			generate: func() (string, error) { return templates.C4ContextPuml(arc) },
+			expect:   []string{"s1 s2 \n@enduml"},
+		},
+		{
+			name:     "container built-in with common override",
+			generate: func() (string, error) { return templates.C4ContainerPuml(arc, "s1") },
+			expect:   []string{"header ACME ", "System_Boundary(s1", "note as N\nSYSTEM CONTAINER VIEW FOR: S1 none\nend note\n@enduml"},
+		},
+		{
+			name:     "built-in templates",
+			generate: func() (string, error) { return C4ContainerPuml(arc, "s1") },
+			expect:   []string{"System_Boundary(s1"},
+			unexpect: []string{"header ACME", "note as N"},
+		},
+	}
+	for _, tt := range templateTests {
+		actual, err := tt.generate()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, e := range tt.expect {
+			if !strings.Contains(actual, e) {
+				t.Errorf("%s: expect puml to contain %q, actual puml is\n%s", tt.name, e, actual)
+			}
+		}
+		for _, u := range tt.unexpect {
+			if strings.Contains(actual, u) {
+				t.Errorf("%s: expect puml not to contain %q, actual puml is\n%s", tt.name, u, actual)
+			}
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "component.puml"), []byte("{{.Broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTemplates(dir); err == nil || !strings.Contains(err.Error(), "component.puml") {
+		t.Errorf("Expect parse error of component.puml, get %v", err)
+	}
+	if _, err := NewTemplates(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expect error on missing template directory")
+	}
+}
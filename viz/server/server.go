@@ -22,11 +22,12 @@ const defaultPlantUmlID = "SyfFKj2rKt3CoKnELR1Io4ZDoSa70000"
 //ArcViz type is the core config of ArcViz server
 type ArcViz struct {
 	PumlRenderURI string
+	Templates     *puml.Templates
 }
 
-//NewArcViz initialized the Plant-UML rendering viz
-func NewArcViz(plantUmlAddress string) *ArcViz {
-	return &ArcViz{plantUmlAddress}
+//NewArcViz initialized the Plant-UML rendering viz, generating code with the given templates
+func NewArcViz(plantUmlAddress string, templates *puml.Templates) *ArcViz {
+	return &ArcViz{plantUmlAddress, templates}
 }
 
 func (s *ArcViz) doPumlRender(ctx context.Context, pumlSrc string, format model.ArcVisualFormat) ([]byte, error) {
@@ -96,49 +97,10 @@ func (s *ArcViz) Render(ctx context.Context, in *model.RenderRequest) (*model.Ar
 	if err != nil {
 		return nil, err
 	}
-	arc := model.ArcType{
-		App:  g.Arc.App,
-		Desc: g.Arc.Desc,
-	}
-	arc.InternalSystems, err = g.GetInternalSystems()
-	if err != nil {
-		return nil, err
-	}
-	arc.ExternalSystems, err = g.GetExternalSystems()
-	if err != nil {
-		return nil, err
-	}
-	arc.Relations, err = g.GetRelations()
+	pumlSrc, err := s.Templates.Generate(g, in.GetTarget()...)
 	if err != nil {
 		return nil, err
 	}
-	arc.Users, err = g.GetUsers()
-	if err != nil {
-		return nil, err
-	}
-	var pumlSrc string
-	switch g.Pers {
-	case analyzer.Landscape:
-		pumlSrc, err = puml.C4ContextPuml(arc)
-		if err != nil {
-			return nil, err
-		}
-	case analyzer.Context:
-		pumlSrc, err = puml.C4ContextPuml(arc, in.GetTarget()...)
-		if err != nil {
-			return nil, err
-		}
-	case analyzer.Container:
-		pumlSrc, err = puml.C4ContainerPuml(arc, in.GetTarget()...)
-		if err != nil {
-			return nil, err
-		}
-	case analyzer.Component:
-	case analyzer.Code:
-	default:
-		return nil, errors.New("Not supported perspective")
-
-	}
 	//Send to puml rederer
 	output, err := s.doPumlRender(ctx, pumlSrc, in.VisualFormat)
 	if err != nil {